@@ -1,22 +1,183 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
-	"log"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net"
 	"net/http"
+	"os/signal"
 	"prompthor/config"
+	"sync"
+	"syscall"
+	"time"
 
 	"prompthor/internal/domain"
+	"prompthor/internal/infrastructure/certificate"
 	httphandler "prompthor/internal/interfaces/http"
 )
 
-func Run(config config.Config, usecase domain.ChatUseCase) {
+// Run serves the API until SIGINT or SIGTERM, then drains the in-flight requests before returning
+func Run(config config.Config, usecase domain.ChatUseCase, options ...httphandler.Option) error {
 	// Configure router
-	router := httphandler.SetupRouter(usecase)
+	router := httphandler.SetupRouter(usecase, options...)
+	server := newServer(config, router)
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return err
+	}
+	server.TLSConfig = tlsConfig
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", ":"+config.Port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", config.Port, err)
+	}
+	switch {
+	case tlsConfig == nil:
+		log.Info().Msgf("🌐 Listening on %s", listener.Addr())
+	case tlsConfig.ClientAuth != tls.NoClientCert:
+		log.Info().Msgf("🔐 Listening on %s with mutual TLS", listener.Addr())
+	default:
+		log.Info().Msgf("🔒 Listening on %s with TLS", listener.Addr())
+	}
+	return serve(ctx, server, listener, config.ShutdownTimeout)
+}
+
+// newServer creates the HTTP server of the handler with the configured timeouts
+func newServer(config config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: config.HTTPReadTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
+	}
+}
+
+// newTLSConfig returns the TLS configuration of the server, reloading the rotated certificates, and nil without
+// certificate. With a client CA bundle the clients are authenticated with their certificates.
+func newTLSConfig(config config.Config) (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		if config.TLSClientCAFile != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return nil, nil
+	}
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	var clientAuth tls.ClientAuthType
+	switch config.TLSClientAuth {
+	case "require":
+		clientAuth = tls.RequireAndVerifyClientCert
+	case "optional":
+		clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown TLS client auth %q, use require or optional", config.TLSClientAuth)
+	}
+	reloader, err := certificate.NewReloader(config.TLSCertFile, config.TLSKeyFile, config.TLSClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return reloader.Config(clientAuth), nil
+}
+
+// cancelTimeout is how long the cancelled requests have to return, so the dependencies they use aren't closed under them
+const cancelTimeout = 5 * time.Second
+
+// serve serves the requests of the listener until ctx is done. Then it stops accepting requests and waits up to
+// drainTimeout for the in-flight ones, the chats and their gateway sends, before cancelling their contexts and waiting
+// up to cancelTimeout for their handlers to return.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, drainTimeout time.Duration) error {
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server.BaseContext = func(net.Listener) context.Context {
+		return requestsCtx
+	}
+	var handlers inFlight
+	handler := server.Handler
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.start()
+		defer handlers.done()
+		handler.ServeHTTP(w, r)
+	})
+
+	served := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			served <- server.ServeTLS(listener, "", "")
+			return
+		}
+		served <- server.Serve(listener)
+	}()
 
-	// Start server
-	serverAddr := ":" + config.Port
-	if err := router.Run(serverAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("Failed to start server: %v", err)
+	select {
+	case err := <-served:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	log.Info().Msgf("🛑 Shutting down, draining the in-flight requests for up to %s", drainTimeout)
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+	if err := server.Shutdown(drainCtx); err != nil {
+		log.Warn().Err(err).Msg("in-flight requests not drained in time, cancelling them")
+		cancelRequests()
+		server.Close()
+		handlers.wait(cancelTimeout)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	log.Info().Msg("server stopped")
+	return nil
+}
+
+// inFlight counts the handlers running. Unlike a WaitGroup, a handler can start while another goroutine waits.
+type inFlight struct {
+	mu    sync.Mutex
+	count int
+	// idle is closed when the last running handler returns
+	idle chan struct{}
+}
+
+// start counts a handler starting
+func (f *inFlight) start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.count++
+}
+
+// done counts a handler returning
+func (f *inFlight) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+}
+
+// wait waits up to timeout for the running handlers to return
+func (f *inFlight) wait(timeout time.Duration) {
+	f.mu.Lock()
+	if f.count == 0 {
+		f.mu.Unlock()
+		return
+	}
+	idle := f.idle
+	f.mu.Unlock()
+	select {
+	case <-idle:
+	case <-time.After(timeout):
+		log.Warn().Msgf("cancelled requests still running after %s, stopping anyway", timeout)
 	}
 }
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"prompthor/config"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves handler on a random port until the returned cancel is called
+func startServer(t *testing.T, handler http.Handler, drainTimeout time.Duration) (string, context.CancelFunc, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, &http.Server{Handler: handler}, listener, drainTimeout)
+	}()
+	return "http://" + listener.Addr().String(), cancel, served
+}
+
+func TestServe(t *testing.T) {
+	t.Run("drains the in-flight requests", func(t *testing.T) {
+		started := make(chan struct{})
+		url, shutdown, served := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte("answer"))
+		}), time.Second)
+
+		responses := make(chan *http.Response, 1)
+		go func() {
+			resp, err := http.Get(url)
+			assert.NoError(t, err)
+			responses <- resp
+		}()
+		<-started
+		shutdown()
+
+		resp := <-responses
+		require.NotNil(t, resp)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "answer", string(body))
+		assert.NoError(t, <-served)
+
+		_, err := http.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("cancels the requests not drained in time", func(t *testing.T) {
+		started := make(chan struct{})
+		cancelled := make(chan error, 1)
+		url, shutdown, served := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+			cancelled <- r.Context().Err()
+		}), 50*time.Millisecond)
+
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+		shutdown()
+
+		assert.NoError(t, <-served)
+		assert.ErrorIs(t, <-cancelled, context.Canceled)
+	})
+
+	t.Run("waits for the cancelled requests to return", func(t *testing.T) {
+		started := make(chan struct{})
+		var returned atomic.Bool
+		url, shutdown, served := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+			time.Sleep(100 * time.Millisecond)
+			returned.Store(true)
+		}), 50*time.Millisecond)
+
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+		shutdown()
+
+		assert.NoError(t, <-served)
+		assert.True(t, returned.Load())
+	})
+
+	t.Run("fails when the listener fails", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listener.Close()
+
+		err = serve(context.Background(), &http.Server{}, listener, time.Second)
+
+		assert.ErrorContains(t, err, "failed to serve")
+	})
+}
+
+func TestInFlight(t *testing.T) {
+	t.Run("returns at once without handlers", func(t *testing.T) {
+		var handlers inFlight
+		start := time.Now()
+
+		handlers.wait(time.Second)
+
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("counts the handlers starting while waiting", func(t *testing.T) {
+		var handlers inFlight
+		handlers.start()
+		waited := make(chan struct{})
+		go func() {
+			handlers.wait(time.Second)
+			close(waited)
+		}()
+
+		handlers.start()
+		handlers.done()
+		select {
+		case <-waited:
+			t.Fatal("returned while a handler is running")
+		case <-time.After(50 * time.Millisecond):
+		}
+		handlers.done()
+
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			t.Fatal("still waiting after the handlers returned")
+		}
+	})
+}
+
+func TestNewServer(t *testing.T) {
+	server := newServer(config.Config{
+		HTTPReadTimeout:  time.Minute,
+		HTTPWriteTimeout: 5 * time.Minute,
+		HTTPIdleTimeout:  2 * time.Minute,
+	}, http.NotFoundHandler())
+
+	assert.Equal(t, time.Minute, server.ReadHeaderTimeout)
+	assert.Equal(t, time.Minute, server.ReadTimeout)
+	assert.Equal(t, 5*time.Minute, server.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, server.IdleTimeout)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("plain HTTP without certificate", func(t *testing.T) {
+		tlsConfig, err := newTLSConfig(config.Config{TLSClientAuth: "require"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	tests := []struct {
+		name     string
+		config   config.Config
+		expected string
+	}{
+		{name: "certificate without key", config: config.Config{TLSCertFile: "server.crt", TLSClientAuth: "require"},
+			expected: "TLS_CERT_FILE and TLS_KEY_FILE must be set together"},
+		{name: "client CA without certificate", config: config.Config{TLSClientCAFile: "ca.crt", TLSClientAuth: "require"},
+			expected: "TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE"},
+		{name: "unknown client auth", config: config.Config{TLSCertFile: "server.crt", TLSKeyFile: "server.key", TLSClientAuth: "always"},
+			expected: `unknown TLS client auth "always", use require or optional`},
+		{name: "missing certificate", config: config.Config{TLSCertFile: "missing.crt", TLSKeyFile: "missing.key", TLSClientAuth: "require"},
+			expected: "failed to read missing.crt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTLSConfig(tt.config)
+
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
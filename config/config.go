@@ -1,43 +1,348 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
 	anysherlog "github.com/narumayase/anysher/log"
 	"github.com/rs/zerolog/log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config contains the application configuration
 type Config struct {
-	Port       string
-	OpenAIKey  string
-	GroqAPIKey string
-	GroqUrl    string
-	ChatModel  string
+	ConfigFile                string
+	Port                      string
+	HTTPReadTimeout           time.Duration
+	HTTPWriteTimeout          time.Duration
+	HTTPIdleTimeout           time.Duration
+	ShutdownTimeout           time.Duration
+	TLSCertFile               string
+	TLSKeyFile                string
+	TLSClientCAFile           string
+	TLSClientAuth             string
+	OpenAIKey                 Secret
+	OpenAIModel               string
+	OpenAIOrgID               string
+	OpenAIProjectID           string
+	OpenAIBaseUrl             string
+	OpenAITimeout             time.Duration
+	GroqAPIKey                Secret
+	GroqUrl                   string
+	ChatProvider              string
+	ChatModel                 string
+	GroqChatCompletionsUrl    string
+	GroqChatCompletionsModels []string
+	MistralAPIKey             Secret
+	MistralUrl                string
+	MistralModel              string
+	MistralSafePrompt         bool
+	CohereAPIKey              Secret
+	CohereUrl                 string
+	CohereModel               string
+	CohereSafetyMode          string
+	StripReasoning            bool
+	AgentTools                []string
+	AgentMaxIterations        int
+	AgentTimeout              time.Duration
+	HTTPFetchAllowedHosts     []string
+	MCPServers                []string
+	StructuredOutputRepairs   int
+	VisionModels              []string
+	VisionMaxImageBytes       int
+	DocumentMaxUploadBytes    int
+	DocumentChunkTokens       int
+	ContextWindowTokens       int
+	ModelContextWindows       map[string]int
+	EmbeddingsProvider        string
+	EmbeddingsModel           string
+	EmbeddingsUrl             string
+	EmbeddingsAPIKey          Secret
+	EmbeddingsBatchSize       int
+	OllamaUrl                 string
+	RAGEmbedder               string
+	RAGStorePath              string
+	RAGTopK                   int
+	TemplatesDir              string
+	ExperimentsFile           string
+	MetricsEnabled            bool
+	TracingExporter           string
+	AuditFile                 string
+	AuditMaxBytes             int
+	AuditRetention            time.Duration
+	AuditRedact               []string
+	AuditRedactPatterns       []string
+	ReadinessCacheTTL         time.Duration
+	ReadinessTimeout          time.Duration
+	GatewayEnabled            bool
+	GatewayAPIUrl             string
+	AdminToken                Secret
+	ConfigWatchInterval       time.Duration
+}
+
+// defaultVisionModels are the models known to accept images
+var defaultVisionModels = []string{
+	"gpt-4o",
+	"gpt-4o-mini",
+	"gpt-4.1",
+	"gpt-4.1-mini",
+	"meta-llama/llama-4-scout-17b-16e-instruct",
+	"meta-llama/llama-4-maverick-17b-128e-instruct",
+	"pixtral-12b-2409",
+	"pixtral-large-latest",
+	"mistral-small-latest",
+	"mistral-medium-latest",
+	"command-a-vision-07-2025",
 }
 
 // Load loads configuration from environment variables or an .env file
 func Load() Config {
+	loadDotEnv()
+	return (&source{}).load()
+}
+
+// LoadFile loads the configuration like Load, with the values of the YAML configuration file at path, or at
+// CONFIG_FILE when path is empty, under the environment variables. It returns the invalid values and the validation
+// errors of the configuration together.
+func LoadFile(path string) (Config, error) {
+	loadDotEnv()
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	source := &source{}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		source.file = values
+	}
+	config := source.load()
+	config.ConfigFile = path
+	return config, errors.Join(append(source.errors, config.Validate())...)
+}
+
+// loadDotEnv loads the .env file into the environment
+func loadDotEnv() {
 	// Load .env file if it exists (ignore error if file doesn't exist)
 	if err := godotenv.Load(); err != nil {
 		log.Printf("No .env file found or error loading .env file: %v", err)
 	}
-	config := Config{
-		Port:       getEnv("PORT", "8080"),
-		OpenAIKey:  getEnv("OPENAI_API_KEY", ""),
-		GroqAPIKey: getEnv("GROQ_API_KEY", ""),
-		GroqUrl:    getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses"),
-		ChatModel:  getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
-	}
 	anysherlog.SetLogLevel()
+}
+
+// source looks the configuration values up in the environment variables, then in the configuration file, and
+// collects the invalid ones
+type source struct {
+	file   map[string]string
+	errors []error
+}
 
+// load reads the configuration from the source
+func (s *source) load() Config {
+	config := Config{
+		Port:                      s.getEnv("PORT", "8080"),
+		HTTPReadTimeout:           s.getEnvAsDuration("HTTP_READ_TIMEOUT", 60*time.Second),
+		HTTPWriteTimeout:          s.getEnvAsDuration("HTTP_WRITE_TIMEOUT", 5*time.Minute),
+		HTTPIdleTimeout:           s.getEnvAsDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:           s.getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		TLSCertFile:               s.getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:                s.getEnv("TLS_KEY_FILE", ""),
+		TLSClientCAFile:           s.getEnv("TLS_CLIENT_CA_FILE", ""),
+		TLSClientAuth:             s.getEnv("TLS_CLIENT_AUTH", "require"),
+		OpenAIKey:                 s.getSecret("OPENAI_API_KEY"),
+		OpenAIModel:               s.getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		OpenAIOrgID:               s.getEnv("OPENAI_ORG_ID", ""),
+		OpenAIProjectID:           s.getEnv("OPENAI_PROJECT_ID", ""),
+		OpenAIBaseUrl:             s.getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+		OpenAITimeout:             s.getEnvAsDuration("OPENAI_TIMEOUT", 60*time.Second),
+		GroqAPIKey:                s.getSecret("GROQ_API_KEY"),
+		GroqUrl:                   s.getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses"),
+		ChatProvider:              s.getEnv("CHAT_PROVIDER", ""),
+		ChatModel:                 s.getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
+		GroqChatCompletionsUrl:    s.getEnv("GROQ_CHAT_COMPLETIONS_URL", "https://api.groq.com/openai/v1/chat/completions"),
+		GroqChatCompletionsModels: s.getEnvAsSlice("GROQ_CHAT_COMPLETIONS_MODELS", nil),
+		MistralAPIKey:             s.getSecret("MISTRAL_API_KEY"),
+		MistralUrl:                s.getEnv("MISTRAL_URL", "https://api.mistral.ai/v1/chat/completions"),
+		MistralModel:              s.getEnv("MISTRAL_MODEL", "mistral-small-latest"),
+		MistralSafePrompt:         s.getEnvAsBool("MISTRAL_SAFE_PROMPT", false),
+		CohereAPIKey:              s.getSecret("COHERE_API_KEY"),
+		CohereUrl:                 s.getEnv("COHERE_URL", "https://api.cohere.com/v2/chat"),
+		CohereModel:               s.getEnv("COHERE_MODEL", "command-r-plus-08-2024"),
+		CohereSafetyMode:          s.getEnv("COHERE_SAFETY_MODE", ""),
+		StripReasoning:            s.getEnvAsBool("STRIP_REASONING", true),
+		AgentTools:                s.getEnvAsSlice("AGENT_TOOLS", nil),
+		AgentMaxIterations:        s.getEnvAsInt("AGENT_MAX_ITERATIONS", 5),
+		AgentTimeout:              s.getEnvAsDuration("AGENT_TIMEOUT", 60*time.Second),
+		HTTPFetchAllowedHosts:     s.getEnvAsSlice("HTTP_FETCH_ALLOWED_HOSTS", nil),
+		MCPServers:                s.getEnvAsSlice("MCP_SERVERS", nil),
+		StructuredOutputRepairs:   s.getEnvAsInt("STRUCTURED_OUTPUT_REPAIRS", 2),
+		VisionModels:              s.getEnvAsSlice("VISION_MODELS", defaultVisionModels),
+		VisionMaxImageBytes:       s.getEnvAsInt("VISION_MAX_IMAGE_BYTES", 4*1024*1024),
+		DocumentMaxUploadBytes:    s.getEnvAsInt("DOCUMENT_MAX_UPLOAD_BYTES", 20*1024*1024),
+		DocumentChunkTokens:       s.getEnvAsInt("DOCUMENT_CHUNK_TOKENS", 500),
+		ContextWindowTokens:       s.getEnvAsInt("CONTEXT_WINDOW_TOKENS", 8192),
+		ModelContextWindows:       s.getEnvAsIntMap("MODEL_CONTEXT_WINDOWS"),
+		EmbeddingsProvider:        s.getEnv("EMBEDDINGS_PROVIDER", "openai"),
+		EmbeddingsModel:           s.getEnv("EMBEDDINGS_MODEL", "text-embedding-3-small"),
+		EmbeddingsUrl:             s.getEnv("EMBEDDINGS_URL", ""),
+		EmbeddingsAPIKey:          s.getSecret("EMBEDDINGS_API_KEY"),
+		EmbeddingsBatchSize:       s.getEnvAsInt("EMBEDDINGS_BATCH_SIZE", 256),
+		OllamaUrl:                 s.getEnv("OLLAMA_URL", "http://localhost:11434"),
+		RAGEmbedder:               s.getEnv("RAG_EMBEDDER", "local"),
+		RAGStorePath:              s.getEnv("RAG_STORE_PATH", ""),
+		RAGTopK:                   s.getEnvAsInt("RAG_TOP_K", 4),
+		TemplatesDir:              s.getEnv("TEMPLATES_DIR", ""),
+		ExperimentsFile:           s.getEnv("EXPERIMENTS_FILE", ""),
+		MetricsEnabled:            s.getEnvAsBool("METRICS_ENABLED", true),
+		TracingExporter:           s.getEnv("TRACING_EXPORTER", "none"),
+		AuditFile:                 s.getEnv("AUDIT_FILE", ""),
+		AuditMaxBytes:             s.getEnvAsInt("AUDIT_MAX_BYTES", 100<<20),
+		AuditRetention:            s.getEnvAsDuration("AUDIT_RETENTION", 30*24*time.Hour),
+		AuditRedact:               s.getEnvAsSlice("AUDIT_REDACT", []string{"email", "phone", "card"}),
+		AuditRedactPatterns:       s.getEnvAsSeparatedSlice("AUDIT_REDACT_PATTERNS", ";", nil),
+		ReadinessCacheTTL:         s.getEnvAsDuration("READINESS_CACHE_TTL", 30*time.Second),
+		ReadinessTimeout:          s.getEnvAsDuration("READINESS_TIMEOUT", 2*time.Second),
+		GatewayEnabled:            s.getEnvAsBool("GATEWAY_ENABLED", false),
+		GatewayAPIUrl:             s.getEnv("GATEWAY_API_URL", "http://anyway:9889"),
+		AdminToken:                s.getSecret("ADMIN_TOKEN"),
+		ConfigWatchInterval:       s.getEnvAsDuration("CONFIG_WATCH_INTERVAL", 10*time.Second),
+	}
+	// Read the secrets now, so their values are masked in the logs from the start
+	secrets := config.Secrets()
+	for _, key := range sortedKeys(secrets) {
+		if _, err := secrets[key].Value(); err != nil {
+			log.Error().Err(err).Msgf("invalid secret %s", key)
+			s.errors = append(s.errors, fmt.Errorf("%s: %w", key, err))
+		}
+	}
 	return config
 }
 
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
+// Secrets returns the secret settings by environment variable
+func (c Config) Secrets() map[string]Secret {
+	return map[string]Secret{
+		"OPENAI_API_KEY":     c.OpenAIKey,
+		"GROQ_API_KEY":       c.GroqAPIKey,
+		"MISTRAL_API_KEY":    c.MistralAPIKey,
+		"COHERE_API_KEY":     c.CohereAPIKey,
+		"EMBEDDINGS_API_KEY": c.EmbeddingsAPIKey,
+		"ADMIN_TOKEN":        c.AdminToken,
+	}
+}
+
+// lookup returns the value of the environment variable, or its value in the configuration file
+func (s *source) lookup(key string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	return s.file[key]
+}
+
+// getEnv gets an environment variable or returns a default value
+func (s *source) getEnv(key, defaultValue string) string {
+	if value := s.lookup(key); value != "" {
+		return value
+	}
 	return defaultValue
 }
+
+// getSecret returns the secret of the environment variable, or the reference to the file named by its _FILE variant,
+// eg: OPENAI_API_KEY_FILE=/run/secrets/openai_api_key. Without both, it returns the value of the configuration file.
+func (s *source) getSecret(key string) Secret {
+	value, file := os.Getenv(key), os.Getenv(key+"_FILE")
+	switch {
+	case value != "" && file != "":
+		log.Error().Msgf("%s and %s_FILE are both set", key, key)
+		s.errors = append(s.errors, fmt.Errorf("%s: set either %s or %s_FILE", key, key, key))
+		return Secret(value)
+	case value != "":
+		return Secret(value)
+	case file != "":
+		return FileSecret(file)
+	default:
+		return Secret(s.file[key])
+	}
+}
+
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func (s *source) getEnvAsBool(key string, defaultValue bool) bool {
+	value := strings.ToLower(s.lookup(key))
+	if value == "" {
+		return defaultValue
+	}
+	if value != "true" && value != "false" {
+		s.errors = append(s.errors, fmt.Errorf("%s: invalid boolean %q, use true or false", key, value))
+	}
+	return value == "true"
+}
+
+// getEnvAsInt gets an environment variable as an integer or returns a default value
+func (s *source) getEnvAsInt(key string, defaultValue int) int {
+	value := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s: %v, using default %d", key, err, defaultValue)
+		s.errors = append(s.errors, fmt.Errorf("%s: invalid integer %q", key, value))
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvAsSlice gets a comma separated environment variable as a slice or returns a default value
+func (s *source) getEnvAsSlice(key string, defaultValue []string) []string {
+	return s.getEnvAsSeparatedSlice(key, ",", defaultValue)
+}
+
+// getEnvAsSeparatedSlice gets an environment variable separated by separator as a slice or returns a default value,
+// for the values that can contain commas
+func (s *source) getEnvAsSeparatedSlice(key, separator string, defaultValue []string) []string {
+	value := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvAsIntMap gets a comma separated list of key=integer pairs (eg: gpt-4o=128000) as a map, invalid pairs are skipped
+func (s *source) getEnvAsIntMap(key string) map[string]int {
+	values := make(map[string]int)
+	for _, item := range s.getEnvAsSlice(key, nil) {
+		name, value, found := strings.Cut(item, "=")
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || strings.TrimSpace(name) == "" || err != nil {
+			log.Printf("Invalid entry %q for %s, expected key=integer", item, key)
+			s.errors = append(s.errors, fmt.Errorf("%s: invalid entry %q, expected key=integer", key, item))
+			continue
+		}
+		values[strings.TrimSpace(name)] = number
+	}
+	return values
+}
+
+// getEnvAsDuration gets an environment variable as a duration (eg: 30s, 2m) or returns a default value
+func (s *source) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	value := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %v, using default %s", key, err, defaultValue)
+		s.errors = append(s.errors, fmt.Errorf("%s: invalid duration %q", key, value))
+		return defaultValue
+	}
+	return duration
+}
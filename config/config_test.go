@@ -1,10 +1,14 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetEnv(t *testing.T) {
@@ -49,7 +53,7 @@ func TestGetEnv(t *testing.T) {
 				defer os.Unsetenv(tt.key)
 			}
 
-			result := getEnv(tt.key, tt.defaultValue)
+			result := (&source{}).getEnv(tt.key, tt.defaultValue)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -67,10 +71,10 @@ func TestConfig_DefaultValues(t *testing.T) {
 
 	t.Run("config structure", func(t *testing.T) {
 		config := Config{
-			Port:       getEnv("PORT", "8080"),
-			OpenAIKey:  getEnv("OPENAI_API_KEY", ""),
-			GroqAPIKey: getEnv("GROQ_API_KEY", ""),
-			ChatModel:  getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
+			Port:       (&source{}).getEnv("PORT", "8080"),
+			OpenAIKey:  (&source{}).getSecret("OPENAI_API_KEY"),
+			GroqAPIKey: (&source{}).getSecret("GROQ_API_KEY"),
+			ChatModel:  (&source{}).getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
 		}
 
 		assert.Equal(t, "8080", config.Port)
@@ -97,15 +101,15 @@ func TestConfig_WithEnvironmentVariables(t *testing.T) {
 
 	t.Run("config with environment variables", func(t *testing.T) {
 		config := Config{
-			Port:       getEnv("PORT", "8080"),
-			OpenAIKey:  getEnv("OPENAI_API_KEY", ""),
-			GroqAPIKey: getEnv("GROQ_API_KEY", ""),
-			ChatModel:  getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
+			Port:       (&source{}).getEnv("PORT", "8080"),
+			OpenAIKey:  (&source{}).getSecret("OPENAI_API_KEY"),
+			GroqAPIKey: (&source{}).getSecret("GROQ_API_KEY"),
+			ChatModel:  (&source{}).getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
 		}
 
 		assert.Equal(t, "3000", config.Port)
-		assert.Equal(t, "test-openai-key", config.OpenAIKey)
-		assert.Equal(t, "test-groq-key", config.GroqAPIKey)
+		assert.Equal(t, Secret("test-openai-key"), config.OpenAIKey)
+		assert.Equal(t, Secret("test-groq-key"), config.GroqAPIKey)
 		assert.Equal(t, "test-model", config.ChatModel)
 	})
 }
@@ -125,14 +129,14 @@ func TestConfig_PartialEnvironmentVariables(t *testing.T) {
 
 	t.Run("config with partial environment variables", func(t *testing.T) {
 		config := Config{
-			Port:       getEnv("PORT", "8080"),
-			OpenAIKey:  getEnv("OPENAI_API_KEY", ""),
-			GroqAPIKey: getEnv("GROQ_API_KEY", ""),
-			ChatModel:  getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
+			Port:       (&source{}).getEnv("PORT", "8080"),
+			OpenAIKey:  (&source{}).getSecret("OPENAI_API_KEY"),
+			GroqAPIKey: (&source{}).getSecret("GROQ_API_KEY"),
+			ChatModel:  (&source{}).getEnv("CHAT_MODEL", "openai/gpt-oss-20b"),
 		}
 
 		assert.Equal(t, "9000", config.Port)
-		assert.Equal(t, "partial-test-key", config.OpenAIKey)
+		assert.Equal(t, Secret("partial-test-key"), config.OpenAIKey)
 		assert.Empty(t, config.GroqAPIKey)
 		assert.Equal(t, "openai/gpt-oss-20b", config.ChatModel)
 	})
@@ -142,7 +146,7 @@ func TestConfig_GroqUrl_Default(t *testing.T) {
 	// Test that GroqUrl has the correct default value
 	os.Unsetenv("GROQ_URL")
 
-	groqUrl := getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses")
+	groqUrl := (&source{}).getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses")
 	assert.Equal(t, "https://api.groq.com/openai/v1/responses", groqUrl)
 }
 
@@ -152,7 +156,7 @@ func TestConfig_GroqUrl_Custom(t *testing.T) {
 	os.Setenv("GROQ_URL", customUrl)
 	defer os.Unsetenv("GROQ_URL")
 
-	groqUrl := getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses")
+	groqUrl := (&source{}).getEnv("GROQ_URL", "https://api.groq.com/openai/v1/responses")
 	assert.Equal(t, customUrl, groqUrl)
 }
 
@@ -174,8 +178,8 @@ LOG_LEVEL=debug`
 	config := Load()
 
 	assert.Equal(t, "3000", config.Port)
-	assert.Equal(t, "test-openai-key", config.OpenAIKey)
-	assert.Equal(t, "test-groq-key", config.GroqAPIKey)
+	assert.Equal(t, Secret("test-openai-key"), config.OpenAIKey)
+	assert.Equal(t, Secret("test-groq-key"), config.GroqAPIKey)
 	assert.Equal(t, "https://test.groq.com/api", config.GroqUrl)
 	assert.Equal(t, "test-model", config.ChatModel)
 }
@@ -195,7 +199,18 @@ func TestLoad_WithoutEnvFile(t *testing.T) {
 	defer os.Chdir(originalWd)
 
 	// Clean environment variables
-	envVars := []string{"PORT", "OPENAI_API_KEY", "GROQ_API_KEY", "GROQ_URL", "CHAT_MODEL", "LOG_LEVEL"}
+	envVars := []string{"PORT", "OPENAI_API_KEY", "GROQ_API_KEY", "GROQ_URL", "CHAT_MODEL", "LOG_LEVEL",
+		"OPENAI_MODEL", "OPENAI_ORG_ID", "OPENAI_PROJECT_ID", "OPENAI_BASE_URL", "OPENAI_TIMEOUT", "GROQ_CHAT_COMPLETIONS_URL", "GROQ_CHAT_COMPLETIONS_MODELS", "MISTRAL_URL", "MISTRAL_MODEL", "MISTRAL_SAFE_PROMPT", "COHERE_URL", "COHERE_MODEL", "COHERE_SAFETY_MODE", "STRIP_REASONING",
+		"AGENT_TOOLS", "AGENT_MAX_ITERATIONS", "AGENT_TIMEOUT", "HTTP_FETCH_ALLOWED_HOSTS", "MCP_SERVERS", "STRUCTURED_OUTPUT_REPAIRS", "VISION_MODELS", "VISION_MAX_IMAGE_BYTES",
+		"DOCUMENT_MAX_UPLOAD_BYTES", "DOCUMENT_CHUNK_TOKENS", "CONTEXT_WINDOW_TOKENS", "MODEL_CONTEXT_WINDOWS",
+		"EMBEDDINGS_PROVIDER", "EMBEDDINGS_MODEL", "EMBEDDINGS_URL", "EMBEDDINGS_API_KEY", "EMBEDDINGS_BATCH_SIZE", "OLLAMA_URL",
+		"RAG_EMBEDDER", "RAG_STORE_PATH", "RAG_TOP_K", "TEMPLATES_DIR", "EXPERIMENTS_FILE", "METRICS_ENABLED",
+		"TRACING_EXPORTER", "AUDIT_FILE", "AUDIT_MAX_BYTES", "AUDIT_RETENTION", "AUDIT_REDACT", "AUDIT_REDACT_PATTERNS",
+		"READINESS_CACHE_TTL", "READINESS_TIMEOUT", "GATEWAY_ENABLED", "GATEWAY_API_URL",
+		"HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT",
+		"TLS_CERT_FILE", "TLS_KEY_FILE", "TLS_CLIENT_CA_FILE", "TLS_CLIENT_AUTH", "CHAT_PROVIDER",
+		"ADMIN_TOKEN", "CONFIG_WATCH_INTERVAL", "OPENAI_API_KEY_FILE", "GROQ_API_KEY_FILE", "MISTRAL_API_KEY_FILE",
+		"COHERE_API_KEY_FILE", "EMBEDDINGS_API_KEY_FILE", "ADMIN_TOKEN_FILE"}
 	for _, env := range envVars {
 		os.Unsetenv(env)
 	}
@@ -208,4 +223,215 @@ func TestLoad_WithoutEnvFile(t *testing.T) {
 	assert.Empty(t, config.GroqAPIKey)
 	assert.Equal(t, "https://api.groq.com/openai/v1/responses", config.GroqUrl)
 	assert.Equal(t, "openai/gpt-oss-20b", config.ChatModel)
+	assert.Equal(t, "gpt-4o-mini", config.OpenAIModel)
+	assert.Empty(t, config.OpenAIOrgID)
+	assert.Empty(t, config.OpenAIProjectID)
+	assert.Equal(t, "https://api.openai.com/v1", config.OpenAIBaseUrl)
+	assert.Equal(t, 60*time.Second, config.OpenAITimeout)
+	assert.Equal(t, "https://api.groq.com/openai/v1/chat/completions", config.GroqChatCompletionsUrl)
+	assert.Empty(t, config.GroqChatCompletionsModels)
+	assert.Equal(t, "https://api.mistral.ai/v1/chat/completions", config.MistralUrl)
+	assert.Equal(t, "mistral-small-latest", config.MistralModel)
+	assert.False(t, config.MistralSafePrompt)
+	assert.Equal(t, "https://api.cohere.com/v2/chat", config.CohereUrl)
+	assert.Equal(t, "command-r-plus-08-2024", config.CohereModel)
+	assert.Empty(t, config.CohereSafetyMode)
+	assert.True(t, config.StripReasoning)
+	assert.Empty(t, config.AgentTools)
+	assert.Equal(t, 5, config.AgentMaxIterations)
+	assert.Equal(t, 60*time.Second, config.AgentTimeout)
+	assert.Empty(t, config.HTTPFetchAllowedHosts)
+	assert.Empty(t, config.MCPServers)
+	assert.Equal(t, 2, config.StructuredOutputRepairs)
+	assert.Contains(t, config.VisionModels, "gpt-4o-mini")
+	assert.Equal(t, 4*1024*1024, config.VisionMaxImageBytes)
+	assert.Equal(t, 20*1024*1024, config.DocumentMaxUploadBytes)
+	assert.Equal(t, 500, config.DocumentChunkTokens)
+	assert.Equal(t, 8192, config.ContextWindowTokens)
+	assert.Empty(t, config.ModelContextWindows)
+	assert.Equal(t, "openai", config.EmbeddingsProvider)
+	assert.Equal(t, "text-embedding-3-small", config.EmbeddingsModel)
+	assert.Empty(t, config.EmbeddingsUrl)
+	assert.Empty(t, config.EmbeddingsAPIKey)
+	assert.Equal(t, 256, config.EmbeddingsBatchSize)
+	assert.Equal(t, "http://localhost:11434", config.OllamaUrl)
+	assert.Equal(t, "local", config.RAGEmbedder)
+	assert.Empty(t, config.RAGStorePath)
+	assert.Equal(t, 4, config.RAGTopK)
+	assert.Empty(t, config.TemplatesDir)
+	assert.Empty(t, config.ExperimentsFile)
+	assert.True(t, config.MetricsEnabled)
+	assert.Equal(t, "none", config.TracingExporter)
+	assert.Empty(t, config.AuditFile)
+	assert.Equal(t, 104857600, config.AuditMaxBytes)
+	assert.Equal(t, 720*time.Hour, config.AuditRetention)
+	assert.Equal(t, []string{"email", "phone", "card"}, config.AuditRedact)
+	assert.Empty(t, config.AuditRedactPatterns)
+	assert.Equal(t, 30*time.Second, config.ReadinessCacheTTL)
+	assert.Equal(t, 2*time.Second, config.ReadinessTimeout)
+	assert.False(t, config.GatewayEnabled)
+	assert.Equal(t, "http://anyway:9889", config.GatewayAPIUrl)
+	assert.Equal(t, 60*time.Second, config.HTTPReadTimeout)
+	assert.Equal(t, 5*time.Minute, config.HTTPWriteTimeout)
+	assert.Equal(t, 2*time.Minute, config.HTTPIdleTimeout)
+	assert.Equal(t, 30*time.Second, config.ShutdownTimeout)
+	assert.Empty(t, config.TLSCertFile)
+	assert.Empty(t, config.TLSKeyFile)
+	assert.Empty(t, config.TLSClientCAFile)
+	assert.Equal(t, "require", config.TLSClientAuth)
+	assert.Empty(t, config.ChatProvider)
+	assert.Empty(t, config.ConfigFile)
+	assert.Empty(t, config.AdminToken)
+	assert.Equal(t, 10*time.Second, config.ConfigWatchInterval)
+}
+
+func TestGetSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openai_api_key")
+	require.NoError(t, os.WriteFile(path, []byte("sk-from-file\n"), 0600))
+
+	t.Run("reads the environment variable", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "sk-from-env")
+		t.Setenv("OPENAI_API_KEY_FILE", "")
+
+		assert.Equal(t, Secret("sk-from-env"), (&source{}).getSecret("OPENAI_API_KEY"))
+	})
+
+	t.Run("references the file of the _FILE variant", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "")
+		t.Setenv("OPENAI_API_KEY_FILE", path)
+
+		secret := (&source{}).getSecret("OPENAI_API_KEY")
+
+		assert.Equal(t, FileSecret(path), secret)
+		value, err := secret.Value()
+		require.NoError(t, err)
+		assert.Equal(t, "sk-from-file", value)
+	})
+
+	t.Run("falls back to the configuration file", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "")
+		t.Setenv("OPENAI_API_KEY_FILE", "")
+
+		secret := (&source{file: map[string]string{"OPENAI_API_KEY": "sk-from-config"}}).getSecret("OPENAI_API_KEY")
+
+		assert.Equal(t, Secret("sk-from-config"), secret)
+	})
+
+	t.Run("reports both variants set", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "sk-from-env")
+		t.Setenv("OPENAI_API_KEY_FILE", path)
+		s := &source{}
+
+		assert.Equal(t, Secret("sk-from-env"), s.getSecret("OPENAI_API_KEY"))
+		assert.Equal(t, []error{errors.New("OPENAI_API_KEY: set either OPENAI_API_KEY or OPENAI_API_KEY_FILE")}, s.errors)
+	})
+}
+
+func TestGetEnvAsBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		envValue     string
+		defaultValue bool
+		expected     bool
+	}{
+		{name: "true value", envValue: "true", defaultValue: false, expected: true},
+		{name: "uppercase true value", envValue: "TRUE", defaultValue: false, expected: true},
+		{name: "false value", envValue: "false", defaultValue: true, expected: false},
+		{name: "unset value", envValue: "", defaultValue: true, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("TEST_BOOL_KEY")
+			if tt.envValue != "" {
+				os.Setenv("TEST_BOOL_KEY", tt.envValue)
+				defer os.Unsetenv("TEST_BOOL_KEY")
+			}
+			assert.Equal(t, tt.expected, (&source{}).getEnvAsBool("TEST_BOOL_KEY", tt.defaultValue))
+		})
+	}
+}
+
+func TestGetEnvAsSlice(t *testing.T) {
+	t.Run("comma separated values", func(t *testing.T) {
+		os.Setenv("TEST_SLICE_KEY", "llama-3.3-70b-versatile, qwen/qwen3-32b,,")
+		defer os.Unsetenv("TEST_SLICE_KEY")
+
+		assert.Equal(t, []string{"llama-3.3-70b-versatile", "qwen/qwen3-32b"}, (&source{}).getEnvAsSlice("TEST_SLICE_KEY", nil))
+	})
+
+	t.Run("unset value", func(t *testing.T) {
+		os.Unsetenv("TEST_SLICE_KEY")
+
+		assert.Equal(t, []string{"default"}, (&source{}).getEnvAsSlice("TEST_SLICE_KEY", []string{"default"}))
+	})
+}
+
+func TestGetEnvAsSeparatedSlice(t *testing.T) {
+	os.Setenv("TEST_SLICE_KEY", `employee=EMP-\d{6}; order=ORD-\d{4,8};`)
+	defer os.Unsetenv("TEST_SLICE_KEY")
+
+	assert.Equal(t, []string{`employee=EMP-\d{6}`, `order=ORD-\d{4,8}`}, (&source{}).getEnvAsSeparatedSlice("TEST_SLICE_KEY", ";", nil))
+}
+
+func TestGetEnvAsDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected time.Duration
+	}{
+		{name: "valid duration", envValue: "90s", expected: 90 * time.Second},
+		{name: "invalid duration", envValue: "ninety", expected: time.Minute},
+		{name: "unset value", envValue: "", expected: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("TEST_DURATION_KEY")
+			if tt.envValue != "" {
+				os.Setenv("TEST_DURATION_KEY", tt.envValue)
+				defer os.Unsetenv("TEST_DURATION_KEY")
+			}
+			assert.Equal(t, tt.expected, (&source{}).getEnvAsDuration("TEST_DURATION_KEY", time.Minute))
+		})
+	}
+}
+
+func TestGetEnvAsInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected int
+	}{
+		{name: "valid integer", envValue: "10", expected: 10},
+		{name: "invalid integer", envValue: "ten", expected: 5},
+		{name: "unset value", envValue: "", expected: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("TEST_INT_KEY")
+			if tt.envValue != "" {
+				os.Setenv("TEST_INT_KEY", tt.envValue)
+				defer os.Unsetenv("TEST_INT_KEY")
+			}
+			assert.Equal(t, tt.expected, (&source{}).getEnvAsInt("TEST_INT_KEY", 5))
+		})
+	}
+}
+
+func TestGetEnvAsIntMap(t *testing.T) {
+	t.Run("valid pairs", func(t *testing.T) {
+		os.Setenv("TEST_INT_MAP_KEY", "gpt-4o=128000, mistral-small-latest = 32000")
+		defer os.Unsetenv("TEST_INT_MAP_KEY")
+		assert.Equal(t, map[string]int{"gpt-4o": 128000, "mistral-small-latest": 32000}, (&source{}).getEnvAsIntMap("TEST_INT_MAP_KEY"))
+	})
+
+	t.Run("invalid pairs are skipped", func(t *testing.T) {
+		os.Setenv("TEST_INT_MAP_KEY", "gpt-4o,=10,gpt-4.1=many,gpt-4o-mini=128000")
+		defer os.Unsetenv("TEST_INT_MAP_KEY")
+		assert.Equal(t, map[string]int{"gpt-4o-mini": 128000}, (&source{}).getEnvAsIntMap("TEST_INT_MAP_KEY"))
+	})
+
+	t.Run("unset value", func(t *testing.T) {
+		os.Unsetenv("TEST_INT_MAP_KEY")
+		assert.Empty(t, (&source{}).getEnvAsIntMap("TEST_INT_MAP_KEY"))
+	})
 }
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReference matches the ${NAME} references to environment variables in the values of the configuration file
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// environmentOnly are the sections only read from the environment, by the libraries using them, with their variables
+var environmentOnly = map[string]string{
+	"gateway": "GATEWAY_ENABLED, GATEWAY_API_URL and GATEWAY_TOKEN",
+}
+
+// File is the schema of the configuration file. Every value is tagged with the environment variable overriding it,
+// the values left out keep their defaults.
+type File struct {
+	Server struct {
+		Port            *int           `yaml:"port" env:"PORT"`
+		ReadTimeout     *time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+		WriteTimeout    *time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+		IdleTimeout     *time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+		ShutdownTimeout *time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+		TLS             struct {
+			CertFile     *string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+			KeyFile      *string `yaml:"key_file" env:"TLS_KEY_FILE"`
+			ClientCAFile *string `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+			ClientAuth   *string `yaml:"client_auth" env:"TLS_CLIENT_AUTH"`
+		} `yaml:"tls"`
+	} `yaml:"server"`
+	Chat struct {
+		Provider                *string `yaml:"provider" env:"CHAT_PROVIDER"`
+		Model                   *string `yaml:"model" env:"CHAT_MODEL"`
+		StripReasoning          *bool   `yaml:"strip_reasoning" env:"STRIP_REASONING"`
+		StructuredOutputRepairs *int    `yaml:"structured_output_repairs" env:"STRUCTURED_OUTPUT_REPAIRS"`
+	} `yaml:"chat"`
+	OpenAI struct {
+		APIKey    *string        `yaml:"api_key" env:"OPENAI_API_KEY"`
+		Model     *string        `yaml:"model" env:"OPENAI_MODEL"`
+		OrgID     *string        `yaml:"org_id" env:"OPENAI_ORG_ID"`
+		ProjectID *string        `yaml:"project_id" env:"OPENAI_PROJECT_ID"`
+		BaseUrl   *string        `yaml:"base_url" env:"OPENAI_BASE_URL"`
+		Timeout   *time.Duration `yaml:"timeout" env:"OPENAI_TIMEOUT"`
+	} `yaml:"openai"`
+	Groq struct {
+		APIKey                *string  `yaml:"api_key" env:"GROQ_API_KEY"`
+		Url                   *string  `yaml:"url" env:"GROQ_URL"`
+		ChatCompletionsUrl    *string  `yaml:"chat_completions_url" env:"GROQ_CHAT_COMPLETIONS_URL"`
+		ChatCompletionsModels []string `yaml:"chat_completions_models" env:"GROQ_CHAT_COMPLETIONS_MODELS"`
+	} `yaml:"groq"`
+	Mistral struct {
+		APIKey     *string `yaml:"api_key" env:"MISTRAL_API_KEY"`
+		Url        *string `yaml:"url" env:"MISTRAL_URL"`
+		Model      *string `yaml:"model" env:"MISTRAL_MODEL"`
+		SafePrompt *bool   `yaml:"safe_prompt" env:"MISTRAL_SAFE_PROMPT"`
+	} `yaml:"mistral"`
+	Cohere struct {
+		APIKey     *string `yaml:"api_key" env:"COHERE_API_KEY"`
+		Url        *string `yaml:"url" env:"COHERE_URL"`
+		Model      *string `yaml:"model" env:"COHERE_MODEL"`
+		SafetyMode *string `yaml:"safety_mode" env:"COHERE_SAFETY_MODE"`
+	} `yaml:"cohere"`
+	Agent struct {
+		Tools                 []string       `yaml:"tools" env:"AGENT_TOOLS"`
+		MaxIterations         *int           `yaml:"max_iterations" env:"AGENT_MAX_ITERATIONS"`
+		Timeout               *time.Duration `yaml:"timeout" env:"AGENT_TIMEOUT"`
+		HTTPFetchAllowedHosts []string       `yaml:"http_fetch_allowed_hosts" env:"HTTP_FETCH_ALLOWED_HOSTS"`
+		MCPServers            []string       `yaml:"mcp_servers" env:"MCP_SERVERS"`
+	} `yaml:"agent"`
+	Vision struct {
+		Models        []string `yaml:"models" env:"VISION_MODELS"`
+		MaxImageBytes *int     `yaml:"max_image_bytes" env:"VISION_MAX_IMAGE_BYTES"`
+	} `yaml:"vision"`
+	Documents struct {
+		MaxUploadBytes      *int           `yaml:"max_upload_bytes" env:"DOCUMENT_MAX_UPLOAD_BYTES"`
+		ChunkTokens         *int           `yaml:"chunk_tokens" env:"DOCUMENT_CHUNK_TOKENS"`
+		ContextWindowTokens *int           `yaml:"context_window_tokens" env:"CONTEXT_WINDOW_TOKENS"`
+		ModelContextWindows map[string]int `yaml:"model_context_windows" env:"MODEL_CONTEXT_WINDOWS"`
+	} `yaml:"documents"`
+	Embeddings struct {
+		Provider  *string `yaml:"provider" env:"EMBEDDINGS_PROVIDER"`
+		Model     *string `yaml:"model" env:"EMBEDDINGS_MODEL"`
+		Url       *string `yaml:"url" env:"EMBEDDINGS_URL"`
+		APIKey    *string `yaml:"api_key" env:"EMBEDDINGS_API_KEY"`
+		BatchSize *int    `yaml:"batch_size" env:"EMBEDDINGS_BATCH_SIZE"`
+		OllamaUrl *string `yaml:"ollama_url" env:"OLLAMA_URL"`
+	} `yaml:"embeddings"`
+	RAG struct {
+		Embedder  *string `yaml:"embedder" env:"RAG_EMBEDDER"`
+		StorePath *string `yaml:"store_path" env:"RAG_STORE_PATH"`
+		TopK      *int    `yaml:"top_k" env:"RAG_TOP_K"`
+	} `yaml:"rag"`
+	Templates struct {
+		Dir *string `yaml:"dir" env:"TEMPLATES_DIR"`
+	} `yaml:"templates"`
+	Experiments struct {
+		File *string `yaml:"file" env:"EXPERIMENTS_FILE"`
+	} `yaml:"experiments"`
+	Metrics struct {
+		Enabled *bool `yaml:"enabled" env:"METRICS_ENABLED"`
+	} `yaml:"metrics"`
+	Tracing struct {
+		Exporter *string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	} `yaml:"tracing"`
+	Audit struct {
+		File           *string        `yaml:"file" env:"AUDIT_FILE"`
+		MaxBytes       *int           `yaml:"max_bytes" env:"AUDIT_MAX_BYTES"`
+		Retention      *time.Duration `yaml:"retention" env:"AUDIT_RETENTION"`
+		Redact         []string       `yaml:"redact" env:"AUDIT_REDACT"`
+		RedactPatterns []string       `yaml:"redact_patterns" env:"AUDIT_REDACT_PATTERNS" separator:";"`
+	} `yaml:"audit"`
+	Readiness struct {
+		CacheTTL *time.Duration `yaml:"cache_ttl" env:"READINESS_CACHE_TTL"`
+		Timeout  *time.Duration `yaml:"timeout" env:"READINESS_TIMEOUT"`
+	} `yaml:"readiness"`
+	Admin struct {
+		Token         *string        `yaml:"token" env:"ADMIN_TOKEN"`
+		WatchInterval *time.Duration `yaml:"watch_interval" env:"CONFIG_WATCH_INTERVAL"`
+	} `yaml:"admin"`
+}
+
+// readFile reads the YAML configuration file and returns its values by environment variable.
+// The ${NAME} references in the values are replaced with the environment variables, eg: api_key: ${OPENAI_API_KEY}.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	values := make(map[string]string)
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if document.Kind == 0 {
+		return values, nil
+	}
+	if err := checkEnvironmentOnly(&document); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := interpolate(&document); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	// The values are decoded after the interpolation, so the referenced variables are type checked too
+	interpolated, err := yaml.Marshal(&document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(interpolated))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	flatten(reflect.ValueOf(file), values)
+	return values, nil
+}
+
+// checkEnvironmentOnly rejects the sections only read from the environment, they would be silently ignored
+func checkEnvironmentOnly(document *yaml.Node) error {
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := document.Content[0]
+	for i := 0; i < len(root.Content); i += 2 {
+		if variables, ok := environmentOnly[root.Content[i].Value]; ok {
+			return fmt.Errorf("line %d: %s is only read from the environment, set %s instead",
+				root.Content[i].Line, root.Content[i].Value, variables)
+		}
+	}
+	return nil
+}
+
+// interpolate replaces the ${NAME} references in the scalar values of the node with the environment variables
+func interpolate(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && envReference.MatchString(node.Value) {
+		var missing []string
+		node.Value = envReference.ReplaceAllStringFunc(node.Value, func(reference string) string {
+			name := envReference.FindStringSubmatch(reference)[1]
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("line %d: environment variable %s is not set", node.Line, strings.Join(missing, ", "))
+		}
+		// Resolve the type of the value again, eg: port: ${PORT}
+		node.Tag = ""
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := interpolate(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatten adds the values set in the section to values, formatted as their environment variables
+func flatten(section reflect.Value, values map[string]string) {
+	for i := 0; i < section.NumField(); i++ {
+		field, value := section.Type().Field(i), section.Field(i)
+		env := field.Tag.Get("env")
+		if env == "" {
+			flatten(value, values)
+			continue
+		}
+		if value.IsNil() {
+			continue
+		}
+		switch value := value.Interface().(type) {
+		case *string:
+			values[env] = *value
+		case *int:
+			values[env] = strconv.Itoa(*value)
+		case *bool:
+			values[env] = strconv.FormatBool(*value)
+		case *time.Duration:
+			values[env] = value.String()
+		case []string:
+			separator := field.Tag.Get("separator")
+			if separator == "" {
+				separator = ","
+			}
+			values[env] = strings.Join(value, separator)
+		case map[string]int:
+			pairs := make([]string, 0, len(value))
+			for name, number := range value {
+				pairs = append(pairs, name+"="+strconv.Itoa(number))
+			}
+			sort.Strings(pairs)
+			values[env] = strings.Join(pairs, ",")
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes the YAML content to a configuration file and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "prompthor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	t.Run("flattens the values by environment variable", func(t *testing.T) {
+		path := writeConfigFile(t, `
+server:
+  port: 9090
+  write_timeout: 10m
+  tls:
+    client_auth: optional
+chat:
+  provider: mistral
+  strip_reasoning: false
+mistral:
+  api_key: test-key
+vision:
+  models: [gpt-4o, pixtral-12b-2409]
+documents:
+  model_context_windows:
+    gpt-4o: 128000
+    llama3: 8192
+audit:
+  redact_patterns:
+    - employee=EMP-\d{6}
+    - ticket=T-\d{1,3},\d{3}
+`)
+
+		values, err := readFile(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"PORT":                  "9090",
+			"HTTP_WRITE_TIMEOUT":    "10m0s",
+			"TLS_CLIENT_AUTH":       "optional",
+			"CHAT_PROVIDER":         "mistral",
+			"STRIP_REASONING":       "false",
+			"MISTRAL_API_KEY":       "test-key",
+			"VISION_MODELS":         "gpt-4o,pixtral-12b-2409",
+			"MODEL_CONTEXT_WINDOWS": "gpt-4o=128000,llama3=8192",
+			"AUDIT_REDACT_PATTERNS": `employee=EMP-\d{6};ticket=T-\d{1,3},\d{3}`,
+		}, values)
+	})
+
+	t.Run("interpolates the environment variables", func(t *testing.T) {
+		t.Setenv("TEST_GROQ_KEY", "gsk-secret")
+		t.Setenv("TEST_PORT", "9091")
+		path := writeConfigFile(t, `
+server:
+  port: ${TEST_PORT}
+groq:
+  api_key: ${TEST_GROQ_KEY}
+  url: https://${TEST_PORT}.example.com/openai/v1/responses
+`)
+
+		values, err := readFile(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "9091", values["PORT"])
+		assert.Equal(t, "gsk-secret", values["GROQ_API_KEY"])
+		assert.Equal(t, "https://9091.example.com/openai/v1/responses", values["GROQ_URL"])
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		values, err := readFile(writeConfigFile(t, ""))
+
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "unknown key", content: "chat:\n  modle: llama3\n", expected: "field modle not found"},
+		{name: "unknown section", content: "providers:\n  openai: {}\n", expected: "field providers not found"},
+		{name: "wrong type", content: "server:\n  port: eighty\n", expected: "cannot unmarshal !!str `eighty` into int"},
+		{name: "invalid duration", content: "agent:\n  timeout: soon\n", expected: "invalid config file"},
+		{name: "missing environment variable", content: "openai:\n  api_key: ${TEST_MISSING_KEY}\n",
+			expected: "line 2: environment variable TEST_MISSING_KEY is not set"},
+		{name: "invalid YAML", content: "chat: [\n", expected: "invalid config file"},
+		{name: "environment only section", content: "rag:\n  top_k: 4\ngateway:\n  enabled: true\n",
+			expected: "line 3: gateway is only read from the environment, set GATEWAY_ENABLED, GATEWAY_API_URL and GATEWAY_TOKEN instead"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readFile(writeConfigFile(t, tt.content))
+
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readFile(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		assert.ErrorContains(t, err, "failed to read config file")
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("environment variables override the file", func(t *testing.T) {
+		t.Setenv("GROQ_API_KEY", "")
+		t.Setenv("RAG_TOP_K", "8")
+		path := writeConfigFile(t, `
+groq:
+  api_key: file-key
+rag:
+  top_k: 2
+  embedder: local
+agent:
+  timeout: 90s
+`)
+
+		config, err := LoadFile(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, path, config.ConfigFile)
+		assert.Equal(t, Secret("file-key"), config.GroqAPIKey)
+		assert.Equal(t, 8, config.RAGTopK)
+		assert.Equal(t, 90*time.Second, config.AgentTimeout)
+		assert.Equal(t, "8080", config.Port)
+	})
+
+	t.Run("reads the file of CONFIG_FILE", func(t *testing.T) {
+		t.Setenv("GROQ_API_KEY", "")
+		path := writeConfigFile(t, "groq:\n  api_key: file-key\n")
+		t.Setenv("CONFIG_FILE", path)
+
+		config, err := LoadFile("")
+
+		require.NoError(t, err)
+		assert.Equal(t, Secret("file-key"), config.GroqAPIKey)
+	})
+
+	t.Run("reports the invalid values with the validation errors", func(t *testing.T) {
+		t.Setenv("GROQ_API_KEY", "")
+		t.Setenv("AGENT_TIMEOUT", "soon")
+		t.Setenv("METRICS_ENABLED", "yes")
+
+		_, err := LoadFile(writeConfigFile(t, "chat:\n  model: mistral\n"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `AGENT_TIMEOUT: invalid duration "soon"`)
+		assert.Contains(t, err.Error(), `METRICS_ENABLED: invalid boolean "yes", use true or false`)
+		assert.Contains(t, err.Error(), `CHAT_MODEL: "mistral" is not a provider, did you mean Mistral?`)
+	})
+
+	t.Run("reads the secret references", func(t *testing.T) {
+		t.Setenv("GROQ_API_KEY", "")
+		secretPath := filepath.Join(t.TempDir(), "groq_api_key")
+		require.NoError(t, os.WriteFile(secretPath, []byte("gsk-from-file\n"), 0600))
+		path := writeConfigFile(t, "groq:\n  api_key: secret://file"+filepath.ToSlash(secretPath)+"\n")
+
+		config, err := LoadFile(path)
+
+		require.NoError(t, err)
+		apiKey, err := config.GroqAPIKey.Value()
+		require.NoError(t, err)
+		assert.Equal(t, "gsk-from-file", apiKey)
+	})
+
+	t.Run("reports the unreadable secrets", func(t *testing.T) {
+		t.Setenv("GROQ_API_KEY", "")
+		path := writeConfigFile(t, "groq:\n  api_key: secret://file/run/secrets/missing_groq_api_key\n")
+
+		_, err := LoadFile(path)
+
+		assert.ErrorContains(t, err, "GROQ_API_KEY: failed to read secret file")
+	})
+
+	t.Run("fails with an invalid file", func(t *testing.T) {
+		_, err := LoadFile(writeConfigFile(t, "chat:\n  modle: llama3\n"))
+
+		assert.ErrorContains(t, err, "field modle not found")
+	})
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretFilePrefix prefixes the references to the files holding a secret, eg: secret://file/run/secrets/openai_api_key
+// reads the secret in /run/secrets/openai_api_key
+const secretFilePrefix = "secret://file/"
+
+// secretMask replaces the secrets when they are printed
+const secretMask = "********"
+
+// minMaskedLength is the length of the shortest secret value masked in the logs, so short values don't mask common words
+const minMaskedLength = 6
+
+// Secret is a secret setting: its value, or the reference to the file holding it, read again when the file is rotated.
+// It is masked when printed, so it doesn't leak in the logs nor in the configuration dumps.
+type Secret string
+
+// FileSecret returns the reference to the secret in the file at path
+func FileSecret(path string) Secret {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	return Secret(secretFilePrefix + strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
+
+// Value returns the value of the secret, read from its file for a reference
+func (s Secret) Value() (string, error) {
+	path, ok := s.file()
+	if !ok {
+		maskSecret(string(s))
+		return string(s), nil
+	}
+	return readSecretFile(path)
+}
+
+// String masks the secret, it implements fmt.Stringer
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return secretMask
+}
+
+// GoString masks the secret in the %#v dumps, it implements fmt.GoStringer
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalText masks the secret in the JSON and YAML dumps, it implements encoding.TextMarshaler
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// file returns the path of the file of a reference
+func (s Secret) file() (string, bool) {
+	path, ok := strings.CutPrefix(string(s), secretFilePrefix)
+	if !ok {
+		return "", false
+	}
+	return filepath.FromSlash("/" + path), true
+}
+
+// secretFile is the value read from a secret file with the state of the file it was read at
+type secretFile struct {
+	state fileState
+	value string
+}
+
+// secretFiles caches the secret files by path, so they are only read again when they change
+var secretFiles = struct {
+	sync.Mutex
+	files map[string]secretFile
+}{files: make(map[string]secretFile)}
+
+// readSecretFile returns the secret in the file at path without its surrounding spaces, reading the file again when
+// its modification time or its size changed
+func readSecretFile(path string) (string, error) {
+	secretFiles.Lock()
+	defer secretFiles.Unlock()
+
+	state := stat(path)
+	if cached, ok := secretFiles.files[path]; ok && cached.state.equal(state) {
+		return cached.value, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	maskSecret(value)
+	secretFiles.files[path] = secretFile{state: state, value: value}
+	return value, nil
+}
+
+// maskedSecrets are the values of the secrets read, the rotated ones included, with the replacer masking them
+var maskedSecrets = struct {
+	sync.RWMutex
+	values   map[string]bool
+	replacer *strings.Replacer
+}{values: make(map[string]bool)}
+
+// maskSecret adds the value to the secrets masked in the logs
+func maskSecret(value string) {
+	if len(value) < minMaskedLength {
+		return
+	}
+	maskedSecrets.RLock()
+	known := maskedSecrets.values[value]
+	maskedSecrets.RUnlock()
+	if known {
+		return
+	}
+
+	maskedSecrets.Lock()
+	defer maskedSecrets.Unlock()
+	maskedSecrets.values[value] = true
+	values := make([]string, 0, len(maskedSecrets.values))
+	for value := range maskedSecrets.values {
+		values = append(values, value)
+	}
+	// The longest values first, so a secret containing another one is masked whole
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, secretMask)
+	}
+	maskedSecrets.replacer = strings.NewReplacer(pairs...)
+}
+
+// maskingWriter masks the values of the secrets read in the output written to its writer
+type maskingWriter struct {
+	w io.Writer
+}
+
+// MaskSecrets returns a writer masking the values of the secrets read by the configuration before writing to w,
+// eg: the output of the logs
+func MaskSecrets(w io.Writer) io.Writer {
+	return &maskingWriter{w: w}
+}
+
+// Write implements io.Writer
+func (w *maskingWriter) Write(p []byte) (int, error) {
+	maskedSecrets.RLock()
+	replacer := maskedSecrets.replacer
+	maskedSecrets.RUnlock()
+	if replacer == nil {
+		return w.w.Write(p)
+	}
+	if _, err := io.WriteString(w.w, replacer.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSecretFile writes the secret to a file and returns its path
+func writeSecretFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "api_key")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSecret_Value(t *testing.T) {
+	t.Run("returns the value", func(t *testing.T) {
+		value, err := Secret("sk-literal-key").Value()
+
+		require.NoError(t, err)
+		assert.Equal(t, "sk-literal-key", value)
+	})
+
+	t.Run("reads the file of a reference without its trailing newline", func(t *testing.T) {
+		path := writeSecretFile(t, "sk-file-key\n")
+
+		value, err := Secret("secret://file" + filepath.ToSlash(path)).Value()
+
+		require.NoError(t, err)
+		assert.Equal(t, "sk-file-key", value)
+	})
+
+	t.Run("reads the file again once rotated", func(t *testing.T) {
+		path := writeSecretFile(t, "sk-first-key")
+		secret := FileSecret(path)
+		value, err := secret.Value()
+		require.NoError(t, err)
+		assert.Equal(t, "sk-first-key", value)
+
+		require.NoError(t, os.WriteFile(path, []byte("sk-rotated-key"), 0600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+		value, err = secret.Value()
+
+		require.NoError(t, err)
+		assert.Equal(t, "sk-rotated-key", value)
+	})
+
+	t.Run("fails with a missing file", func(t *testing.T) {
+		_, err := FileSecret(filepath.Join(t.TempDir(), "missing")).Value()
+
+		assert.ErrorContains(t, err, "failed to read secret file")
+	})
+
+	t.Run("fails with an empty file", func(t *testing.T) {
+		path := writeSecretFile(t, "\n")
+
+		_, err := FileSecret(path).Value()
+
+		assert.EqualError(t, err, fmt.Sprintf("secret file %s is empty", path))
+	})
+}
+
+func TestFileSecret(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	path, ok := FileSecret("secrets/api_key").file()
+
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(wd, "secrets", "api_key"), path)
+}
+
+func TestSecret_Masking(t *testing.T) {
+	config := Config{OpenAIKey: "sk-openai-key", GroqAPIKey: FileSecret("/run/secrets/groq_api_key")}
+
+	assert.Equal(t, "********", config.OpenAIKey.String())
+	assert.Empty(t, Secret("").String())
+	for _, dump := range []string{fmt.Sprintf("%v", config), fmt.Sprintf("%+v", config), fmt.Sprintf("%#v", config)} {
+		assert.NotContains(t, dump, "sk-openai-key")
+		assert.NotContains(t, dump, "groq_api_key")
+		assert.Contains(t, dump, "********")
+	}
+	content, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"OpenAIKey":"********"`)
+	assert.Contains(t, string(content), `"MistralAPIKey":""`)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	path := writeSecretFile(t, "gsk-rotated-before")
+	_, err := FileSecret(path).Value()
+	require.NoError(t, err)
+	_, err = Secret("sk-masked-in-logs").Value()
+	require.NoError(t, err)
+	_, err = Secret("short").Value()
+	require.NoError(t, err)
+	var out bytes.Buffer
+
+	n, err := MaskSecrets(&out).Write([]byte(`{"message":"Bearer sk-masked-in-logs rejected, gsk-rotated-before too, short"}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, 78, n)
+	assert.Equal(t, `{"message":"Bearer ******** rejected, ******** too, short"}`, out.String())
+}
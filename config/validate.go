@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// chatProviderNames are the values of CHAT_MODEL selecting a provider other than Groq, by provider
+var chatProviderNames = map[string]string{
+	"openai":  "OpenAI",
+	"mistral": "Mistral",
+	"cohere":  "Cohere",
+}
+
+// Validate checks the configuration and returns every error found, nil when it is valid
+func (c Config) Validate() error {
+	var errs []error
+	errs = append(errs, c.validateChatProvider()...)
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT: invalid port %q", c.Port))
+	}
+	urls := map[string]string{
+		"OPENAI_BASE_URL":           c.OpenAIBaseUrl,
+		"GROQ_URL":                  c.GroqUrl,
+		"GROQ_CHAT_COMPLETIONS_URL": c.GroqChatCompletionsUrl,
+		"MISTRAL_URL":               c.MistralUrl,
+		"COHERE_URL":                c.CohereUrl,
+		"OLLAMA_URL":                c.OllamaUrl,
+		"EMBEDDINGS_URL":            c.EmbeddingsUrl,
+	}
+	if c.GatewayEnabled {
+		urls["GATEWAY_API_URL"] = c.GatewayAPIUrl
+	}
+	for _, key := range sortedKeys(urls) {
+		if err := validateURL(urls[key]); urls[key] != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	errs = append(errs,
+		oneOf("EMBEDDINGS_PROVIDER", c.EmbeddingsProvider, "openai", "ollama", "openai_compatible"),
+		oneOf("RAG_EMBEDDER", c.RAGEmbedder, "local", "provider"),
+		oneOf("TRACING_EXPORTER", c.TracingExporter, "none", "otlp", "stdout"),
+		oneOf("TLS_CLIENT_AUTH", c.TLSClientAuth, "require", "optional"),
+	)
+	if c.EmbeddingsProvider == "openai_compatible" && c.EmbeddingsUrl == "" {
+		errs = append(errs, errors.New("EMBEDDINGS_URL: required by the openai_compatible embeddings provider"))
+	}
+	if c.RAGEmbedder == "provider" && c.EmbeddingsProvider == "openai" && c.OpenAIKey == "" {
+		errs = append(errs, errors.New("OPENAI_API_KEY: required by the openai embeddings of the collections"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		errs = append(errs, errors.New("TLS_CLIENT_CA_FILE: needs TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+
+	positives := map[string]int{
+		"AGENT_MAX_ITERATIONS":  c.AgentMaxIterations,
+		"DOCUMENT_CHUNK_TOKENS": c.DocumentChunkTokens,
+		"CONTEXT_WINDOW_TOKENS": c.ContextWindowTokens,
+		"EMBEDDINGS_BATCH_SIZE": c.EmbeddingsBatchSize,
+		"RAG_TOP_K":             c.RAGTopK,
+	}
+	for _, key := range sortedKeys(positives) {
+		if positives[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s: must be positive, got %d", key, positives[key]))
+		}
+	}
+	if c.StructuredOutputRepairs < 0 {
+		errs = append(errs, fmt.Errorf("STRUCTURED_OUTPUT_REPAIRS: must not be negative, got %d", c.StructuredOutputRepairs))
+	}
+	if c.ConfigWatchInterval < 0 {
+		errs = append(errs, fmt.Errorf("CONFIG_WATCH_INTERVAL: must not be negative, got %s", c.ConfigWatchInterval))
+	}
+	return errors.Join(errs...)
+}
+
+// providerLike matches the values of CHAT_MODEL made of letters only, a provider name rather than a model id,
+// eg: Mistarl, which has no version, size nor organization like openai/gpt-oss-20b or gemma2-9b-it
+var providerLike = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// validateChatProvider checks that the chat provider is known and has its API key. Without CHAT_PROVIDER, the values
+// of CHAT_MODEL naming a provider with another case or misspelled are reported, they would silently select Groq.
+func (c Config) validateChatProvider() []error {
+	apiKeys := map[string]string{
+		"openai":  "OPENAI_API_KEY",
+		"groq":    "GROQ_API_KEY",
+		"mistral": "MISTRAL_API_KEY",
+		"cohere":  "COHERE_API_KEY",
+	}
+	provider := c.ChatProvider
+	if provider != "" {
+		if _, ok := apiKeys[provider]; !ok {
+			return []error{fmt.Errorf("CHAT_PROVIDER: unknown provider %q, use openai, groq, mistral or cohere", provider)}
+		}
+	} else {
+		provider = "groq"
+		for name, model := range chatProviderNames {
+			if c.ChatModel == model {
+				provider = name
+			} else if strings.EqualFold(c.ChatModel, model) {
+				return []error{fmt.Errorf("CHAT_MODEL: %q is not a provider, did you mean %s?", c.ChatModel, model)}
+			}
+		}
+		if strings.EqualFold(c.ChatModel, "groq") {
+			return []error{fmt.Errorf("CHAT_MODEL: %q is not a Groq model, set the provider with CHAT_PROVIDER", c.ChatModel)}
+		}
+		if provider == "groq" && providerLike.MatchString(c.ChatModel) {
+			return []error{fmt.Errorf("CHAT_MODEL: %q is neither a provider nor a model id, use OpenAI, Mistral, Cohere "+
+				"or set the provider with CHAT_PROVIDER", c.ChatModel)}
+		}
+	}
+	if apiKey := apiKeys[provider]; c.Secrets()[apiKey] == "" {
+		return []error{fmt.Errorf("%s: required by the %s chat provider", apiKey, provider)}
+	}
+	return nil
+}
+
+// validateURL checks that the value is an absolute http or https url
+func validateURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid url %q", value)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid url %q, expected http(s)://host", value)
+	}
+	return nil
+}
+
+// oneOf checks that the value is one of the allowed ones
+func oneOf(key, value string, allowed ...string) error {
+	if slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("%s: unknown value %q, use %s", key, value, strings.Join(allowed, ", "))
+}
+
+// sortedKeys returns the keys of the map in order, so the errors are always reported in the same order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
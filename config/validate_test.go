@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validConfig returns the default configuration of the environment with a Groq API key
+func validConfig() Config {
+	config := (&source{}).load()
+	config.GroqAPIKey = "gsk-test"
+	return config
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("valid defaults", func(t *testing.T) {
+		assert.NoError(t, validConfig().Validate())
+	})
+
+	tests := []struct {
+		name     string
+		change   func(*Config)
+		expected string
+	}{
+		{name: "unknown chat provider", change: func(c *Config) { c.ChatProvider = "anthropic" },
+			expected: `CHAT_PROVIDER: unknown provider "anthropic", use openai, groq, mistral or cohere`},
+		{name: "chat provider without key", change: func(c *Config) { c.ChatProvider = "openai" },
+			expected: "OPENAI_API_KEY: required by the openai chat provider"},
+		{name: "chat model selecting a provider without key", change: func(c *Config) { c.ChatModel = "Cohere" },
+			expected: "COHERE_API_KEY: required by the cohere chat provider"},
+		{name: "chat model with a typo", change: func(c *Config) { c.ChatModel = "OPENAI" },
+			expected: `CHAT_MODEL: "OPENAI" is not a provider, did you mean OpenAI?`},
+		{name: "chat model with a misspelled provider", change: func(c *Config) { c.ChatModel = "Mistarl" },
+			expected: `CHAT_MODEL: "Mistarl" is neither a provider nor a model id, use OpenAI, Mistral, Cohere or set the provider with CHAT_PROVIDER`},
+		{name: "chat model naming groq", change: func(c *Config) { c.ChatModel = "groq" },
+			expected: `CHAT_MODEL: "groq" is not a Groq model, set the provider with CHAT_PROVIDER`},
+		{name: "no chat provider", change: func(c *Config) { c.GroqAPIKey = "" },
+			expected: "GROQ_API_KEY: required by the groq chat provider"},
+		{name: "invalid port", change: func(c *Config) { c.Port = "80a" }, expected: `PORT: invalid port "80a"`},
+		{name: "url without scheme", change: func(c *Config) { c.MistralUrl = "api.mistral.ai/v1/chat/completions" },
+			expected: `MISTRAL_URL: invalid url "api.mistral.ai/v1/chat/completions", expected http(s)://host`},
+		{name: "gateway url when enabled", change: func(c *Config) { c.GatewayEnabled, c.GatewayAPIUrl = true, "anyway:9889" },
+			expected: "GATEWAY_API_URL: invalid url"},
+		{name: "unknown embeddings provider", change: func(c *Config) { c.EmbeddingsProvider = "voyage" },
+			expected: `EMBEDDINGS_PROVIDER: unknown value "voyage", use openai, ollama, openai_compatible`},
+		{name: "compatible embeddings without url", change: func(c *Config) { c.EmbeddingsProvider = "openai_compatible" },
+			expected: "EMBEDDINGS_URL: required by the openai_compatible embeddings provider"},
+		{name: "provider embeddings without key", change: func(c *Config) { c.RAGEmbedder = "provider" },
+			expected: "OPENAI_API_KEY: required by the openai embeddings of the collections"},
+		{name: "unknown tracing exporter", change: func(c *Config) { c.TracingExporter = "jaeger" },
+			expected: `TRACING_EXPORTER: unknown value "jaeger", use none, otlp, stdout`},
+		{name: "certificate without key", change: func(c *Config) { c.TLSCertFile = "server.crt" },
+			expected: "TLS_CERT_FILE and TLS_KEY_FILE must be set together"},
+		{name: "client CA without certificate", change: func(c *Config) { c.TLSClientCAFile = "ca.crt" },
+			expected: "TLS_CLIENT_CA_FILE: needs TLS_CERT_FILE and TLS_KEY_FILE"},
+		{name: "zero top k", change: func(c *Config) { c.RAGTopK = 0 }, expected: "RAG_TOP_K: must be positive, got 0"},
+		{name: "negative repairs", change: func(c *Config) { c.StructuredOutputRepairs = -1 },
+			expected: "STRUCTURED_OUTPUT_REPAIRS: must not be negative, got -1"},
+		{name: "negative watch interval", change: func(c *Config) { c.ConfigWatchInterval = -time.Second },
+			expected: "CONFIG_WATCH_INTERVAL: must not be negative, got -1s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.change(&config)
+
+			assert.ErrorContains(t, config.Validate(), tt.expected)
+		})
+	}
+
+	t.Run("reports every error", func(t *testing.T) {
+		config := validConfig()
+		config.Port = "0"
+		config.RAGTopK = -1
+
+		assert.EqualError(t, config.Validate(), "PORT: invalid port \"0\"\nRAG_TOP_K: must be positive, got -1")
+	})
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch calls onChange when the modification time or the size of the file at path changes, checking it every interval
+// until ctx is done. Editors replacing the file instead of writing it are detected too, as the new file has another
+// modification time.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := stat(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := stat(path)
+			if current.equal(last) {
+				continue
+			}
+			last = current
+			onChange()
+		}
+	}
+}
+
+// fileState is the state of a watched file, the zero value when it doesn't exist
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// equal reports whether both states are the same
+func (s fileState) equal(other fileState) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// stat returns the state of the file at path
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompthor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("chat:\n  model: OpenAI\n"), 0600))
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, path, 10*time.Millisecond, func() { changes <- struct{}{} })
+		close(done)
+	}()
+
+	t.Run("ignores an untouched file", func(t *testing.T) {
+		select {
+		case <-changes:
+			t.Fatal("change reported without change")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("reports a rewritten file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("chat:\n  model: Mistral\n"), 0600))
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("change not reported")
+		}
+	})
+
+	t.Run("reports a replaced file", func(t *testing.T) {
+		replacement := path + ".tmp"
+		require.NoError(t, os.WriteFile(replacement, []byte("chat:\n  model: Cohere\n"), 0600))
+		require.NoError(t, os.Chtimes(replacement, time.Now(), time.Now().Add(time.Hour)))
+		require.NoError(t, os.Rename(replacement, path))
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("change not reported")
+		}
+	})
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch not stopped")
+	}
+	assert.Empty(t, changes)
+}
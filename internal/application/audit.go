@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"time"
+)
+
+// AuditedChatUseCase records every chat request with its answer in the audit sink, redacting the personal data
+type AuditedChatUseCase struct {
+	chatUseCase domain.ChatUseCase
+	sink        domain.AuditSink
+	redactor    *Redactor
+	provider    string
+}
+
+// NewAuditedChatUseCase creates a chat use case auditing the requests processed by chatUseCase with the provider
+func NewAuditedChatUseCase(chatUseCase domain.ChatUseCase, sink domain.AuditSink, redactor *Redactor, provider string) *AuditedChatUseCase {
+	return &AuditedChatUseCase{
+		chatUseCase: chatUseCase,
+		sink:        sink,
+		redactor:    redactor,
+		provider:    provider,
+	}
+}
+
+// ProcessChat processes the request and writes its audit record. Failing to write the record doesn't fail the request.
+func (uc *AuditedChatUseCase) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
+	start := time.Now()
+	response, err := uc.chatUseCase.ProcessChat(ctx, prompt)
+
+	ids := domain.RequestIDsFrom(ctx)
+	record := domain.AuditRecord{
+		Time:            start.UTC(),
+		RequestID:       ids.RequestID,
+		CorrelationID:   ids.CorrelationID,
+		RoutingKey:      ids.RoutingKey,
+		ClientID:        prompt.ClientID,
+		Caller:          ids.Caller,
+		Provider:        uc.provider,
+		Model:           prompt.Model,
+		Template:        prompt.Template,
+		TemplateVersion: prompt.TemplateVersion,
+		Variables:       uc.variables(prompt.Variables),
+		Prompt:          uc.redactor.Redact(prompt.Prompt),
+		LatencyMs:       time.Since(start).Milliseconds(),
+	}
+	for _, message := range prompt.Messages {
+		record.Messages = append(record.Messages, domain.AuditMessage{
+			Role:    message.Role,
+			Content: uc.redactor.Redact(message.Content),
+		})
+	}
+	if err != nil {
+		record.Error = uc.redactor.Redact(err.Error())
+	} else {
+		record.Response = uc.redactor.Redact(response.Response)
+		if response.Model != "" {
+			record.Model = response.Model
+		}
+	}
+	if writeErr := uc.sink.Write(ctx, record); writeErr != nil {
+		log.Ctx(ctx).Error().Err(writeErr).Msg("failed to write audit record")
+	}
+	return response, err
+}
+
+// variables returns the redacted values of the template variables, the ones that are not text as JSON
+func (uc *AuditedChatUseCase) variables(variables map[string]any) map[string]string {
+	if len(variables) == 0 {
+		return nil
+	}
+	redacted := make(map[string]string, len(variables))
+	for name, value := range variables {
+		text, ok := value.(string)
+		if !ok {
+			content, err := json.Marshal(value)
+			if err != nil {
+				content = []byte(fmt.Sprint(value))
+			}
+			text = string(content)
+		}
+		redacted[name] = uc.redactor.Redact(text)
+	}
+	return redacted
+}
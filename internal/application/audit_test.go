@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditSink is a mock implementation of AuditSink
+type MockAuditSink struct {
+	mock.Mock
+}
+
+func (m *MockAuditSink) Write(ctx context.Context, record domain.AuditRecord) error {
+	args := m.Called(record)
+	return args.Error(0)
+}
+
+func (m *MockAuditSink) Close() error {
+	return m.Called().Error(0)
+}
+
+func TestAuditedChatUseCase_ProcessChat(t *testing.T) {
+	redactor, err := NewRedactor([]string{"email", "phone", "card"}, nil)
+	require.NoError(t, err)
+	ctx := domain.WithRequestIDs(context.Background(), domain.RequestIDs{
+		RequestID:     "request-1",
+		CorrelationID: "correlation-1",
+		RoutingKey:    "telegram:12345",
+		Caller:        "CN=billing,O=Acme",
+	})
+
+	t.Run("records the redacted request and answer", func(t *testing.T) {
+		prompt := domain.PromptRequest{
+			Messages: []domain.Message{
+				{Role: domain.RoleSystem, Content: "You are a support bot."},
+				{Role: domain.RoleUser, Content: "My email is ada@example.com"},
+			},
+			ClientID: "client-1",
+			Model:    "llama-3.3-70b",
+		}
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", prompt).Return(&domain.ChatResponse{
+			Response: "We will write to ada@example.com",
+			Model:    "llama-3.3-70b-versatile",
+		}, nil)
+		sink := &MockAuditSink{}
+		sink.On("Write", mock.Anything).Return(nil)
+
+		response, err := NewAuditedChatUseCase(chatUseCase, sink, redactor, "groq").ProcessChat(ctx, prompt)
+
+		require.NoError(t, err)
+		assert.Equal(t, "We will write to ada@example.com", response.Response)
+		record := sink.Calls[0].Arguments.Get(0).(domain.AuditRecord)
+		assert.Equal(t, "request-1", record.RequestID)
+		assert.Equal(t, "correlation-1", record.CorrelationID)
+		assert.Equal(t, "CN=billing,O=Acme", record.Caller)
+		assert.Equal(t, "telegram:12345", record.RoutingKey)
+		assert.Equal(t, "client-1", record.ClientID)
+		assert.Equal(t, "groq", record.Provider)
+		assert.Equal(t, "llama-3.3-70b-versatile", record.Model)
+		assert.Equal(t, []domain.AuditMessage{
+			{Role: domain.RoleSystem, Content: "You are a support bot."},
+			{Role: domain.RoleUser, Content: "My email is [REDACTED:email]"},
+		}, record.Messages)
+		assert.Equal(t, "We will write to [REDACTED:email]", record.Response)
+		assert.False(t, record.Time.IsZero())
+	})
+
+	t.Run("records the error", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Call +1 555 123 4567", Model: "gpt-4o"}
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", prompt).Return(nil, errors.New("provider unavailable"))
+		sink := &MockAuditSink{}
+		sink.On("Write", mock.Anything).Return(nil)
+
+		_, err := NewAuditedChatUseCase(chatUseCase, sink, redactor, "openai").ProcessChat(context.Background(), prompt)
+
+		assert.EqualError(t, err, "provider unavailable")
+		record := sink.Calls[0].Arguments.Get(0).(domain.AuditRecord)
+		assert.Equal(t, "Call [REDACTED:phone]", record.Prompt)
+		assert.Equal(t, "gpt-4o", record.Model)
+		assert.Equal(t, "provider unavailable", record.Error)
+		assert.Empty(t, record.Response)
+	})
+
+	t.Run("records the redacted variables of the template", func(t *testing.T) {
+		prompt := domain.PromptRequest{
+			Template:        "support",
+			TemplateVersion: 2,
+			Variables:       map[string]any{"customer": "ada@example.com", "order": map[string]any{"id": 42}},
+		}
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", prompt).Return(&domain.ChatResponse{Response: "Hi"}, nil)
+		sink := &MockAuditSink{}
+		sink.On("Write", mock.Anything).Return(nil)
+
+		_, err := NewAuditedChatUseCase(chatUseCase, sink, redactor, "groq").ProcessChat(context.Background(), prompt)
+
+		require.NoError(t, err)
+		record := sink.Calls[0].Arguments.Get(0).(domain.AuditRecord)
+		assert.Equal(t, "support", record.Template)
+		assert.Equal(t, 2, record.TemplateVersion)
+		assert.Equal(t, map[string]string{"customer": "[REDACTED:email]", "order": `{"id":42}`}, record.Variables)
+	})
+
+	t.Run("a failing sink doesn't fail the request", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Hello"}
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", prompt).Return(&domain.ChatResponse{Response: "Hi"}, nil)
+		sink := &MockAuditSink{}
+		sink.On("Write", mock.Anything).Return(errors.New("disk full"))
+
+		response, err := NewAuditedChatUseCase(chatUseCase, sink, redactor, "cohere").ProcessChat(context.Background(), prompt)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Hi", response.Response)
+	})
+}
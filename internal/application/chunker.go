@@ -0,0 +1,133 @@
+package application
+
+import (
+	"prompthor/internal/domain"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// charsPerToken approximates the characters of a token, used to budget the context window without a tokenizer
+const charsPerToken = 4
+
+// chunk is a part of a document page small enough to be sent to the model, score is its similarity to the prompt
+type chunk struct {
+	index  int
+	file   string
+	page   int
+	text   string
+	tokens int
+	score  float64
+}
+
+// estimateTokens approximates the tokens of a text
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// chunkDocuments splits the pages of the documents in chunks of at most maxTokens,
+// keeping paragraphs together and splitting the longer ones between words
+func chunkDocuments(documents []domain.Document, maxTokens int) []chunk {
+	var chunks []chunk
+	for _, document := range documents {
+		for i, page := range document.Pages {
+			number := 0
+			if document.Paged {
+				number = i + 1
+			}
+			for _, text := range splitText(page, maxTokens) {
+				chunks = append(chunks, chunk{
+					index:  len(chunks),
+					file:   document.Name,
+					page:   number,
+					text:   text,
+					tokens: estimateTokens(text),
+				})
+			}
+		}
+	}
+	return chunks
+}
+
+// splitText splits a text in parts of at most maxTokens
+func splitText(text string, maxTokens int) []string {
+	var (
+		parts   []string
+		current strings.Builder
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+	add := func(piece, separator string) {
+		if current.Len() > 0 && estimateTokens(current.String()+separator+piece) > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(piece)
+	}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if estimateTokens(paragraph) <= maxTokens {
+			add(paragraph, "\n\n")
+			continue
+		}
+		flush()
+		for _, word := range strings.Fields(paragraph) {
+			add(word, " ")
+		}
+		flush()
+	}
+	flush()
+	return parts
+}
+
+// selectChunks picks the chunks most relevant to the query that fit in the token budget, in document order
+func selectChunks(chunks []chunk, query string, budget int) []chunk {
+	terms := queryTerms(query)
+	scores := make([]int, len(chunks))
+	for i, c := range chunks {
+		text := strings.ToLower(c.text)
+		for term := range terms {
+			scores[i] += strings.Count(text, term)
+		}
+	}
+	ranked := append([]chunk{}, chunks...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].index] > scores[ranked[j].index]
+	})
+
+	var selected []chunk
+	for _, c := range ranked {
+		if c.tokens > budget {
+			continue
+		}
+		selected = append(selected, c)
+		budget -= c.tokens
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].index < selected[j].index
+	})
+	return selected
+}
+
+// queryTerms returns the lower case words of the query, ignoring the shortest ones
+func queryTerms(query string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if utf8.RuneCountInString(word) > 2 {
+			terms[word] = true
+		}
+	}
+	return terms
+}
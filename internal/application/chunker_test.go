@@ -0,0 +1,79 @@
+package application
+
+import (
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, estimateTokens(""))
+	assert.Equal(t, 1, estimateTokens("abc"))
+	assert.Equal(t, 2, estimateTokens("abcde"))
+	assert.Equal(t, 1, estimateTokens("ñandú"[:4]))
+}
+
+func TestChunkDocuments(t *testing.T) {
+	t.Run("pages are numbered only for paged documents", func(t *testing.T) {
+		chunks := chunkDocuments([]domain.Document{
+			{Name: "report.pdf", Pages: []string{"first page", "", "third page"}, Paged: true},
+			{Name: "notes.md", Pages: []string{"some notes"}},
+		}, 100)
+
+		assert.Equal(t, []chunk{
+			{index: 0, file: "report.pdf", page: 1, text: "first page", tokens: 3},
+			{index: 1, file: "report.pdf", page: 3, text: "third page", tokens: 3},
+			{index: 2, file: "notes.md", page: 0, text: "some notes", tokens: 3},
+		}, chunks)
+	})
+
+	t.Run("paragraphs are kept together until the chunk is full", func(t *testing.T) {
+		chunks := chunkDocuments([]domain.Document{
+			{Name: "notes.md", Pages: []string{"aaaa aaaa\n\nbbbb bbbb\n\ncccc cccc"}},
+		}, 5)
+
+		assert.Len(t, chunks, 2)
+		assert.Equal(t, "aaaa aaaa\n\nbbbb bbbb", chunks[0].text)
+		assert.Equal(t, "cccc cccc", chunks[1].text)
+	})
+
+	t.Run("long paragraphs are split between words", func(t *testing.T) {
+		chunks := chunkDocuments([]domain.Document{
+			{Name: "notes.md", Pages: []string{strings.Repeat("word ", 20)}},
+		}, 5)
+
+		assert.Len(t, chunks, 5)
+		for _, c := range chunks {
+			assert.LessOrEqual(t, c.tokens, 5)
+			assert.Equal(t, "word word word word", c.text)
+		}
+	})
+}
+
+func TestSelectChunks(t *testing.T) {
+	chunks := []chunk{
+		{index: 0, file: "a.md", text: "the weather is nice", tokens: 5},
+		{index: 1, file: "a.md", text: "revenue grew this quarter", tokens: 7},
+		{index: 2, file: "a.md", text: "quarterly revenue details", tokens: 7},
+	}
+
+	t.Run("everything fits", func(t *testing.T) {
+		assert.Equal(t, chunks, selectChunks(chunks, "How did revenue grow?", 100))
+	})
+
+	t.Run("most relevant chunks first, returned in document order", func(t *testing.T) {
+		selected := selectChunks(chunks, "How did revenue grow this quarter?", 14)
+
+		assert.Equal(t, []chunk{chunks[1], chunks[2]}, selected)
+	})
+
+	t.Run("chunks larger than the budget are skipped", func(t *testing.T) {
+		assert.Equal(t, []chunk{chunks[0]}, selectChunks(chunks, "revenue", 6))
+	})
+
+	t.Run("nothing fits", func(t *testing.T) {
+		assert.Empty(t, selectChunks(chunks, "revenue", 2))
+	})
+}
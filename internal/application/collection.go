@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"regexp"
+)
+
+// embedBatchSize is the number of chunks embedded per embedder call
+const embedBatchSize = 64
+
+// collectionName are the valid collection names
+var collectionName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CollectionUseCaseImpl implements CollectionUseCase
+type CollectionUseCaseImpl struct {
+	extractor   domain.DocumentExtractor
+	embedder    domain.Embedder
+	store       domain.VectorStore
+	chunkTokens int
+}
+
+// NewCollectionUseCase creates a new instance of the collection use case, chunkTokens is the size of the stored chunks
+func NewCollectionUseCase(extractor domain.DocumentExtractor, embedder domain.Embedder, store domain.VectorStore, chunkTokens int) domain.CollectionUseCase {
+	if chunkTokens <= 0 {
+		chunkTokens = defaultChunkTokens
+	}
+	return &CollectionUseCaseImpl{
+		extractor:   extractor,
+		embedder:    embedder,
+		store:       store,
+		chunkTokens: chunkTokens,
+	}
+}
+
+// Ingest extracts the text of the files, splits it in chunks and stores them with their embeddings in the collection.
+// Files already in the collection are replaced.
+func (uc *CollectionUseCaseImpl) Ingest(ctx context.Context, collection string, files []domain.File) (*domain.Collection, error) {
+	if err := validateCollection(collection); err != nil {
+		return nil, err
+	}
+	documents := make([]domain.Document, 0, len(files))
+	for _, file := range files {
+		document, err := uc.extractor.Extract(file)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *document)
+	}
+	chunks := chunkDocuments(documents, uc.chunkTokens)
+	if len(chunks) == 0 {
+		return nil, &domain.InputError{Message: "the files have no text"}
+	}
+
+	stored := make([]domain.Chunk, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		batch := chunks[start:min(start+embedBatchSize, len(chunks))]
+		texts := make([]string, 0, len(batch))
+		for _, c := range batch {
+			texts = append(texts, c.text)
+		}
+		vectors, err := uc.embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return nil, fmt.Errorf("failed to embed chunks: got %d embeddings for %d chunks", len(vectors), len(batch))
+		}
+		for i, c := range batch {
+			stored = append(stored, domain.Chunk{File: c.file, Page: c.page, Text: c.text, Vector: vectors[i]})
+		}
+	}
+	if err := uc.store.Add(ctx, collection, stored); err != nil {
+		return nil, fmt.Errorf("failed to store chunks: %w", err)
+	}
+	log.Ctx(ctx).Info().Msgf("ingested %d documents, %d chunks, into collection %s", len(documents), len(stored), collection)
+	return &domain.Collection{Name: collection, Documents: len(documents), Chunks: len(stored)}, nil
+}
+
+// Retrieve returns the k chunks of the collection closest to the query
+func (uc *CollectionUseCaseImpl) Retrieve(ctx context.Context, collection, query string, k int) ([]domain.ScoredChunk, error) {
+	if err := validateCollection(collection); err != nil {
+		return nil, err
+	}
+	vectors, err := uc.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("failed to embed query: got %d embeddings", len(vectors))
+	}
+	chunks, err := uc.store.Search(ctx, collection, vectors[0], k)
+	if errors.Is(err, domain.ErrCollectionNotFound) {
+		return nil, &domain.InputError{Message: fmt.Sprintf("collection %s not found", collection)}
+	}
+	return chunks, err
+}
+
+// Collections returns the stored collections
+func (uc *CollectionUseCaseImpl) Collections(ctx context.Context) ([]domain.Collection, error) {
+	return uc.store.Collections(ctx)
+}
+
+// Delete removes a collection and its documents
+func (uc *CollectionUseCaseImpl) Delete(ctx context.Context, collection string) error {
+	return uc.store.Delete(ctx, collection)
+}
+
+// validateCollection checks the collection name
+func validateCollection(collection string) error {
+	if !collectionName.MatchString(collection) {
+		return &domain.InputError{Message: fmt.Sprintf("invalid collection name %q, use up to 64 letters, digits, - or _", collection)}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmbedder is a mock implementation of Embedder
+type MockEmbedder struct {
+	mock.Mock
+}
+
+func (m *MockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	args := m.Called(texts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]float32), args.Error(1)
+}
+
+// MockVectorStore is a mock implementation of VectorStore
+type MockVectorStore struct {
+	mock.Mock
+}
+
+func (m *MockVectorStore) Add(ctx context.Context, collection string, chunks []domain.Chunk) error {
+	return m.Called(collection, chunks).Error(0)
+}
+
+func (m *MockVectorStore) Search(ctx context.Context, collection string, vector []float32, k int) ([]domain.ScoredChunk, error) {
+	args := m.Called(collection, vector, k)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ScoredChunk), args.Error(1)
+}
+
+func (m *MockVectorStore) Collections(ctx context.Context) ([]domain.Collection, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Collection), args.Error(1)
+}
+
+func (m *MockVectorStore) Delete(ctx context.Context, collection string) error {
+	return m.Called(collection).Error(0)
+}
+
+func TestCollectionUseCaseImpl_Ingest(t *testing.T) {
+	policies := domain.File{Name: "policies.pdf", Data: []byte("%PDF")}
+	extracted := &domain.Document{Name: "policies.pdf", Pages: []string{"Vacation is 20 days.", "Remote work on fridays."}, Paged: true}
+
+	t.Run("chunks are embedded and stored", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", policies).Return(extracted, nil)
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", []string{"Vacation is 20 days.", "Remote work on fridays."}).
+			Return([][]float32{{1, 0}, {0, 1}}, nil)
+		store := &MockVectorStore{}
+		store.On("Add", "handbook", []domain.Chunk{
+			{File: "policies.pdf", Page: 1, Text: "Vacation is 20 days.", Vector: []float32{1, 0}},
+			{File: "policies.pdf", Page: 2, Text: "Remote work on fridays.", Vector: []float32{0, 1}},
+		}).Return(nil)
+		useCase := NewCollectionUseCase(extractor, embedder, store, 0)
+
+		collection, err := useCase.Ingest(context.Background(), "handbook", []domain.File{policies})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Collection{Name: "handbook", Documents: 1, Chunks: 2}, collection)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("chunks are embedded in batches", func(t *testing.T) {
+		pages := make([]string, embedBatchSize+1)
+		for i := range pages {
+			pages[i] = "page"
+		}
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", policies).Return(&domain.Document{Name: "policies.pdf", Pages: pages, Paged: true}, nil)
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", mock.MatchedBy(func(texts []string) bool { return len(texts) == embedBatchSize })).
+			Return(make([][]float32, embedBatchSize), nil).Once()
+		embedder.On("Embed", []string{"page"}).Return([][]float32{{1}}, nil).Once()
+		store := &MockVectorStore{}
+		store.On("Add", "handbook", mock.Anything).Return(nil)
+		useCase := NewCollectionUseCase(extractor, embedder, store, 0)
+
+		collection, err := useCase.Ingest(context.Background(), "handbook", []domain.File{policies})
+
+		assert.NoError(t, err)
+		assert.Equal(t, embedBatchSize+1, collection.Chunks)
+		embedder.AssertExpectations(t)
+	})
+
+	t.Run("invalid collection name", func(t *testing.T) {
+		useCase := NewCollectionUseCase(&MockDocumentExtractor{}, &MockEmbedder{}, &MockVectorStore{}, 0)
+
+		_, err := useCase.Ingest(context.Background(), "../etc", []domain.File{policies})
+
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+	})
+
+	t.Run("embedder error", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", policies).Return(extracted, nil)
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", mock.Anything).Return(nil, errors.New("rate limited"))
+		store := &MockVectorStore{}
+		useCase := NewCollectionUseCase(extractor, embedder, store, 0)
+
+		_, err := useCase.Ingest(context.Background(), "handbook", []domain.File{policies})
+
+		assert.EqualError(t, err, "failed to embed chunks: rate limited")
+		store.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	})
+
+	t.Run("embedder returns fewer vectors", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", policies).Return(extracted, nil)
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", mock.Anything).Return([][]float32{{1}}, nil)
+		useCase := NewCollectionUseCase(extractor, embedder, &MockVectorStore{}, 0)
+
+		_, err := useCase.Ingest(context.Background(), "handbook", []domain.File{policies})
+
+		assert.EqualError(t, err, "failed to embed chunks: got 1 embeddings for 2 chunks")
+	})
+}
+
+func TestCollectionUseCaseImpl_Retrieve(t *testing.T) {
+	t.Run("closest chunks", func(t *testing.T) {
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", []string{"How many vacation days?"}).Return([][]float32{{1, 0}}, nil)
+		found := []domain.ScoredChunk{{Chunk: domain.Chunk{File: "policies.pdf", Page: 1, Text: "Vacation is 20 days."}, Score: 0.9}}
+		store := &MockVectorStore{}
+		store.On("Search", "handbook", []float32{1, 0}, 3).Return(found, nil)
+		useCase := NewCollectionUseCase(&MockDocumentExtractor{}, embedder, store, 0)
+
+		chunks, err := useCase.Retrieve(context.Background(), "handbook", "How many vacation days?", 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, found, chunks)
+	})
+
+	t.Run("unknown collection", func(t *testing.T) {
+		embedder := &MockEmbedder{}
+		embedder.On("Embed", mock.Anything).Return([][]float32{{1, 0}}, nil)
+		store := &MockVectorStore{}
+		store.On("Search", "missing", mock.Anything, 3).Return(nil, domain.ErrCollectionNotFound)
+		useCase := NewCollectionUseCase(&MockDocumentExtractor{}, embedder, store, 0)
+
+		_, err := useCase.Retrieve(context.Background(), "missing", "query", 3)
+
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+		assert.Equal(t, "collection missing not found", err.Error())
+	})
+}
+
+func TestChatUseCaseImpl_ProcessChat_Collection(t *testing.T) {
+	found := []domain.ScoredChunk{
+		{Chunk: domain.Chunk{File: "policies.pdf", Page: 3, Text: "Vacation is 20 days."}, Score: 0.91},
+		{Chunk: domain.Chunk{File: "faq.md", Text: "Ask HR for more days."}, Score: 0.42},
+	}
+
+	t.Run("retrieved chunks are sent and cited", func(t *testing.T) {
+		retriever := &MockRetriever{}
+		retriever.On("Retrieve", "handbook", "How many vacation days?", 2).Return(found, nil)
+		mockRepo := &MockLLMRepository{}
+		mockRepo.On("Send", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			system := prompt.Messages[0]
+			return system.Role == domain.RoleSystem &&
+				strings.Contains(system.Content, "[1] policies.pdf, page 3\nVacation is 20 days.") &&
+				strings.HasSuffix(system.Content, "[2] faq.md\nAsk HR for more days.")
+		})).Return(&domain.LLMResponse{Content: "20 days [1]."}, nil)
+		useCase := NewChatUseCase(mockRepo, WithRetriever(retriever, 2))
+
+		response, err := useCase.ProcessChat(context.Background(),
+			domain.PromptRequest{Prompt: "How many vacation days?", Collection: "handbook"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "20 days [1].", response.Response)
+		assert.Equal(t, []domain.Source{
+			{ID: 1, File: "policies.pdf", Page: 3, Score: 0.91},
+			{ID: 2, File: "faq.md", Score: 0.42},
+		}, response.Sources)
+	})
+
+	t.Run("last user message is the query without prompt", func(t *testing.T) {
+		retriever := &MockRetriever{}
+		retriever.On("Retrieve", "handbook", "And sick days?", defaultRetrieveTopK).Return([]domain.ScoredChunk{}, nil)
+		mockRepo := &MockLLMRepository{}
+		mockRepo.On("Send", mock.Anything).Return(&domain.LLMResponse{Content: "I don't know."}, nil)
+		useCase := NewChatUseCase(mockRepo, WithRetriever(retriever, 0))
+
+		response, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{
+			Collection: "handbook",
+			Messages: []domain.Message{
+				{Role: domain.RoleUser, Content: "And sick days?"},
+				{Role: domain.RoleAssistant, Content: "Let me check."},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, response.Sources)
+		retriever.AssertExpectations(t)
+	})
+
+	t.Run("collections not enabled", func(t *testing.T) {
+		mockRepo := &MockLLMRepository{}
+		useCase := NewChatUseCase(mockRepo)
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Collection: "handbook"})
+
+		assert.EqualError(t, err, "collections are not enabled")
+		mockRepo.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("retrieval error", func(t *testing.T) {
+		retriever := &MockRetriever{}
+		retriever.On("Retrieve", "missing", "Hi", defaultRetrieveTopK).
+			Return([]domain.ScoredChunk(nil), &domain.InputError{Message: "collection missing not found"})
+		useCase := NewChatUseCase(&MockLLMRepository{}, WithRetriever(retriever, 0))
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Collection: "missing"})
+
+		assert.EqualError(t, err, "collection missing not found")
+	})
+}
+
+// MockRetriever is a mock implementation of Retriever
+type MockRetriever struct {
+	mock.Mock
+}
+
+func (m *MockRetriever) Retrieve(ctx context.Context, collection, query string, k int) ([]domain.ScoredChunk, error) {
+	args := m.Called(collection, query, k)
+	return args.Get(0).([]domain.ScoredChunk), args.Error(1)
+}
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"strings"
+)
+
+// defaultChunkTokens is the size of the parts the documents are split in
+const defaultChunkTokens = 500
+
+// defaultContextWindow is the context window assumed for the models without a configured one
+const defaultContextWindow = 8192
+
+// documentsInstruction tells the model how to use and cite the documents
+const documentsInstruction = "Answer using the documents below. Cite the parts you use with their number in brackets, " +
+	"eg: [1]. If the documents don't contain the answer, say so.\n\n"
+
+// DocumentUseCaseImpl implements DocumentUseCase
+type DocumentUseCaseImpl struct {
+	chatUseCase    domain.ChatUseCase
+	extractor      domain.DocumentExtractor
+	chunkTokens    int
+	contextWindow  int
+	contextWindows map[string]int
+}
+
+// DocumentOption configures optional behavior of the document use case
+type DocumentOption func(*DocumentUseCaseImpl)
+
+// WithChunkTokens sets the size of the parts the documents are split in
+func WithChunkTokens(tokens int) DocumentOption {
+	return func(uc *DocumentUseCaseImpl) {
+		if tokens > 0 {
+			uc.chunkTokens = tokens
+		}
+	}
+}
+
+// WithContextWindows sets the context window of the default model and of the models that differ from it
+func WithContextWindows(defaultTokens int, models map[string]int) DocumentOption {
+	return func(uc *DocumentUseCaseImpl) {
+		if defaultTokens > 0 {
+			uc.contextWindow = defaultTokens
+		}
+		uc.contextWindows = models
+	}
+}
+
+// NewDocumentUseCase creates a new instance of the document use case
+func NewDocumentUseCase(chatUseCase domain.ChatUseCase, extractor domain.DocumentExtractor, options ...DocumentOption) domain.DocumentUseCase {
+	uc := &DocumentUseCaseImpl{
+		chatUseCase:   chatUseCase,
+		extractor:     extractor,
+		chunkTokens:   defaultChunkTokens,
+		contextWindow: defaultContextWindow,
+	}
+	for _, option := range options {
+		option(uc)
+	}
+	return uc
+}
+
+// AskWithFiles answers the prompt with the text of the files.
+// The documents are split in chunks and the ones most relevant to the prompt are sent, as many as fit in
+// three quarters of the model context window, the rest is left for the answer.
+func (uc *DocumentUseCaseImpl) AskWithFiles(ctx context.Context, prompt domain.PromptRequest, files []domain.File) (*domain.ChatResponse, error) {
+	documents := make([]domain.Document, 0, len(files))
+	for _, file := range files {
+		document, err := uc.extractor.Extract(file)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *document)
+	}
+	chunks := chunkDocuments(documents, uc.chunkTokens)
+	if len(chunks) == 0 {
+		return nil, &domain.InputError{Message: "the files have no text"}
+	}
+
+	budget := uc.window(prompt.Model)*3/4 - estimateTokens(documentsInstruction)
+	for _, message := range prompt.Conversation() {
+		budget -= estimateTokens(message.Content)
+	}
+	selected := selectChunks(chunks, prompt.Prompt, budget)
+	if len(selected) == 0 {
+		return nil, &domain.InputError{Message: "the prompt leaves no room for the files in the model context window"}
+	}
+	if len(selected) < len(chunks) {
+		log.Ctx(ctx).Info().Msgf("sending %d of %d document chunks to fit the context window", len(selected), len(chunks))
+	}
+
+	instructions, sources := documentsContext(selected)
+	prompt.Messages = append([]domain.Message{{Role: domain.RoleSystem, Content: instructions}}, prompt.Messages...)
+	response, err := uc.chatUseCase.ProcessChat(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	response.Sources = sources
+	return response, nil
+}
+
+// window returns the context window of the model
+func (uc *DocumentUseCaseImpl) window(model string) int {
+	if tokens, ok := uc.contextWindows[model]; ok && model != "" {
+		return tokens
+	}
+	return uc.contextWindow
+}
+
+// documentsContext builds the system message with the numbered chunks and the sources they cite
+func documentsContext(chunks []chunk) (string, []domain.Source) {
+	var text strings.Builder
+	text.WriteString(documentsInstruction)
+	sources := make([]domain.Source, 0, len(chunks))
+	for i, c := range chunks {
+		source := domain.Source{ID: i + 1, File: c.file, Page: c.page, Score: c.score}
+		sources = append(sources, source)
+		if c.page > 0 {
+			fmt.Fprintf(&text, "[%d] %s, page %d\n%s\n\n", source.ID, c.file, c.page, c.text)
+		} else {
+			fmt.Fprintf(&text, "[%d] %s\n%s\n\n", source.ID, c.file, c.text)
+		}
+	}
+	return strings.TrimSpace(text.String()), sources
+}
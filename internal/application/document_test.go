@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockChatUseCase is a mock implementation of ChatUseCase
+type MockChatUseCase struct {
+	mock.Mock
+}
+
+func (m *MockChatUseCase) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
+	args := m.Called(prompt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChatResponse), args.Error(1)
+}
+
+// MockDocumentExtractor is a mock implementation of DocumentExtractor
+type MockDocumentExtractor struct {
+	mock.Mock
+}
+
+func (m *MockDocumentExtractor) Extract(file domain.File) (*domain.Document, error) {
+	args := m.Called(file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Document), args.Error(1)
+}
+
+func TestNewDocumentUseCase(t *testing.T) {
+	useCase := NewDocumentUseCase(&MockChatUseCase{}, &MockDocumentExtractor{},
+		WithChunkTokens(200), WithContextWindows(32000, map[string]int{"gpt-4o": 128000}))
+
+	impl := useCase.(*DocumentUseCaseImpl)
+	assert.Equal(t, 200, impl.chunkTokens)
+	assert.Equal(t, 32000, impl.window(""))
+	assert.Equal(t, 32000, impl.window("gpt-4o-mini"))
+	assert.Equal(t, 128000, impl.window("gpt-4o"))
+}
+
+func TestDocumentUseCaseImpl_AskWithFiles(t *testing.T) {
+	report := domain.File{Name: "report.pdf", Data: []byte("%PDF")}
+	notes := domain.File{Name: "notes.md", Data: []byte("notes")}
+
+	t.Run("documents are sent with their sources", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", report).Return(&domain.Document{
+			Name: "report.pdf", Pages: []string{"Prompthor report", "Revenue grew 12 percent"}, Paged: true,
+		}, nil)
+		extractor.On("Extract", notes).Return(&domain.Document{Name: "notes.md", Pages: []string{"Ship on friday."}}, nil)
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			system := prompt.Messages[0]
+			return len(prompt.Messages) == 1 && system.Role == domain.RoleSystem &&
+				strings.HasPrefix(system.Content, documentsInstruction) &&
+				strings.Contains(system.Content, "[1] report.pdf, page 1\nPrompthor report") &&
+				strings.Contains(system.Content, "[2] report.pdf, page 2\nRevenue grew 12 percent") &&
+				strings.HasSuffix(system.Content, "[3] notes.md\nShip on friday.") &&
+				prompt.Prompt == "How much did revenue grow?"
+		})).Return(&domain.ChatResponse{Response: "It grew 12 percent [2]."}, nil)
+		useCase := NewDocumentUseCase(chatUseCase, extractor)
+
+		response, err := useCase.AskWithFiles(context.Background(),
+			domain.PromptRequest{Prompt: "How much did revenue grow?"}, []domain.File{report, notes})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "It grew 12 percent [2].", response.Response)
+		assert.Equal(t, []domain.Source{
+			{ID: 1, File: "report.pdf", Page: 1},
+			{ID: 2, File: "report.pdf", Page: 2},
+			{ID: 3, File: "notes.md"},
+		}, response.Sources)
+		chatUseCase.AssertExpectations(t)
+	})
+
+	t.Run("only the relevant chunks fit the context window", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", notes).Return(&domain.Document{Name: "notes.md", Pages: []string{
+			strings.Repeat("filler ", 40) + "\n\nThe launch is on friday.\n\n" + strings.Repeat("padding ", 40),
+		}}, nil)
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			return strings.HasSuffix(prompt.Messages[0].Content, "[1] notes.md\nThe launch is on friday.")
+		})).Return(&domain.ChatResponse{Response: "Friday [1]."}, nil)
+		useCase := NewDocumentUseCase(chatUseCase, extractor, WithChunkTokens(20), WithContextWindows(70, nil))
+
+		response, err := useCase.AskWithFiles(context.Background(),
+			domain.PromptRequest{Prompt: "When is the launch?"}, []domain.File{notes})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Source{{ID: 1, File: "notes.md"}}, response.Sources)
+	})
+
+	t.Run("prompt too large for the context window", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", notes).Return(&domain.Document{Name: "notes.md", Pages: []string{"Ship on friday."}}, nil)
+		chatUseCase := &MockChatUseCase{}
+		useCase := NewDocumentUseCase(chatUseCase, extractor, WithContextWindows(100, nil))
+
+		response, err := useCase.AskWithFiles(context.Background(),
+			domain.PromptRequest{Prompt: strings.Repeat("why ", 100)}, []domain.File{notes})
+
+		assert.Nil(t, response)
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+		assert.Equal(t, "the prompt leaves no room for the files in the model context window", err.Error())
+		chatUseCase.AssertNotCalled(t, "ProcessChat", mock.Anything)
+	})
+
+	t.Run("files without text", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", report).Return(&domain.Document{Name: "report.pdf", Pages: []string{""}, Paged: true}, nil)
+		useCase := NewDocumentUseCase(&MockChatUseCase{}, extractor)
+
+		_, err := useCase.AskWithFiles(context.Background(), domain.PromptRequest{Prompt: "Summarize"}, []domain.File{report})
+
+		assert.EqualError(t, err, "the files have no text")
+	})
+
+	t.Run("extraction error", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", report).Return(nil, &domain.InputError{Message: "failed to read file report.pdf"})
+		useCase := NewDocumentUseCase(&MockChatUseCase{}, extractor)
+
+		_, err := useCase.AskWithFiles(context.Background(), domain.PromptRequest{Prompt: "Summarize"}, []domain.File{report})
+
+		assert.EqualError(t, err, "failed to read file report.pdf")
+	})
+
+	t.Run("chat error", func(t *testing.T) {
+		extractor := &MockDocumentExtractor{}
+		extractor.On("Extract", notes).Return(&domain.Document{Name: "notes.md", Pages: []string{"Ship on friday."}}, nil)
+		chatUseCase := &MockChatUseCase{}
+		chatUseCase.On("ProcessChat", mock.Anything).Return(nil, errors.New("API error"))
+		useCase := NewDocumentUseCase(chatUseCase, extractor)
+
+		response, err := useCase.AskWithFiles(context.Background(), domain.PromptRequest{Prompt: "When?"}, []domain.File{notes})
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "API error")
+	})
+}
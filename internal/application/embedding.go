@@ -0,0 +1,81 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"sort"
+)
+
+// defaultEmbeddingBatchSize is the number of inputs sent per embeddings repository call
+const defaultEmbeddingBatchSize = 256
+
+// EmbeddingUseCaseImpl implements EmbeddingUseCase, and Embedder with the configured model
+type EmbeddingUseCaseImpl struct {
+	embeddingRepository domain.EmbeddingRepository
+	batchSize           int
+}
+
+// NewEmbeddingUseCase creates a new instance of the embeddings use case, batchSize limits the inputs per provider call.
+// It is also the embedder of the collections when they use the embeddings provider.
+func NewEmbeddingUseCase(embeddingRepository domain.EmbeddingRepository, batchSize int) *EmbeddingUseCaseImpl {
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatchSize
+	}
+	return &EmbeddingUseCaseImpl{
+		embeddingRepository: embeddingRepository,
+		batchSize:           batchSize,
+	}
+}
+
+// CreateEmbeddings returns the embeddings of the inputs, sending them to the provider in batches.
+// The embeddings are indexed by their position in the request and the usage of every batch is added up.
+func (uc *EmbeddingUseCaseImpl) CreateEmbeddings(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	response := &domain.EmbeddingResponse{
+		Object: "list",
+		Data:   make([]domain.Embedding, 0, len(request.Input)),
+		Model:  request.Model,
+	}
+	for start := 0; start < len(request.Input); start += uc.batchSize {
+		batch := request
+		batch.Input = request.Input[start:min(start+uc.batchSize, len(request.Input))]
+		result, err := uc.embeddingRepository.Embed(ctx, batch)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to create embeddings")
+			return nil, err
+		}
+		if len(result.Data) != len(batch.Input) {
+			return nil, fmt.Errorf("embeddings provider returned %d embeddings for %d inputs", len(result.Data), len(batch.Input))
+		}
+		for _, embedding := range result.Data {
+			if embedding.Index < 0 || embedding.Index >= len(batch.Input) {
+				return nil, fmt.Errorf("embeddings provider returned an unknown index %d", embedding.Index)
+			}
+			embedding.Object = "embedding"
+			embedding.Index += start
+			response.Data = append(response.Data, embedding)
+		}
+		response.Model = result.Model
+		response.Usage.PromptTokens += result.Usage.PromptTokens
+		response.Usage.TotalTokens += result.Usage.TotalTokens
+	}
+	// providers may return the embeddings of a batch in any order
+	sort.Slice(response.Data, func(i, j int) bool {
+		return response.Data[i].Index < response.Data[j].Index
+	})
+	return response, nil
+}
+
+// Embed returns a vector per text, using the configured embedding model
+func (uc *EmbeddingUseCaseImpl) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	response, err := uc.CreateEmbeddings(ctx, domain.EmbeddingRequest{Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, 0, len(response.Data))
+	for _, embedding := range response.Data {
+		vectors = append(vectors, embedding.Embedding)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,121 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmbeddingRepository is a mock implementation of EmbeddingRepository
+type MockEmbeddingRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingRepository) Embed(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmbeddingResponse), args.Error(1)
+}
+
+func TestNewEmbeddingUseCase_DefaultBatchSize(t *testing.T) {
+	uc := NewEmbeddingUseCase(&MockEmbeddingRepository{}, 0)
+
+	assert.Equal(t, defaultEmbeddingBatchSize, uc.batchSize)
+}
+
+func TestEmbeddingUseCaseImpl_CreateEmbeddings(t *testing.T) {
+	t.Run("batches the inputs", func(t *testing.T) {
+		mockRepo := &MockEmbeddingRepository{}
+		mockRepo.On("Embed", domain.EmbeddingRequest{Input: domain.EmbeddingInput{"a", "b"}, Model: "embed"}).
+			Return(&domain.EmbeddingResponse{
+				Data: []domain.Embedding{
+					{Index: 1, Embedding: []float32{2}},
+					{Index: 0, Embedding: []float32{1}},
+				},
+				Model: "embed-v1",
+				Usage: domain.EmbeddingUsage{PromptTokens: 2, TotalTokens: 2},
+			}, nil)
+		mockRepo.On("Embed", domain.EmbeddingRequest{Input: domain.EmbeddingInput{"c"}, Model: "embed"}).
+			Return(&domain.EmbeddingResponse{
+				Data:  []domain.Embedding{{Index: 0, Embedding: []float32{3}}},
+				Model: "embed-v1",
+				Usage: domain.EmbeddingUsage{PromptTokens: 1, TotalTokens: 1},
+			}, nil)
+		uc := NewEmbeddingUseCase(mockRepo, 2)
+
+		response, err := uc.CreateEmbeddings(context.Background(), domain.EmbeddingRequest{
+			Input: domain.EmbeddingInput{"a", "b", "c"}, Model: "embed",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.EmbeddingResponse{
+			Object: "list",
+			Data: []domain.Embedding{
+				{Object: "embedding", Index: 0, Embedding: []float32{1}},
+				{Object: "embedding", Index: 1, Embedding: []float32{2}},
+				{Object: "embedding", Index: 2, Embedding: []float32{3}},
+			},
+			Model: "embed-v1",
+			Usage: domain.EmbeddingUsage{PromptTokens: 3, TotalTokens: 3},
+		}, response)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		mockRepo := &MockEmbeddingRepository{}
+		mockRepo.On("Embed", mock.Anything).Return(nil, errors.New("provider down"))
+		uc := NewEmbeddingUseCase(mockRepo, 2)
+
+		response, err := uc.CreateEmbeddings(context.Background(), domain.EmbeddingRequest{Input: domain.EmbeddingInput{"a"}})
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "provider down")
+	})
+
+	t.Run("missing embeddings", func(t *testing.T) {
+		mockRepo := &MockEmbeddingRepository{}
+		mockRepo.On("Embed", mock.Anything).Return(&domain.EmbeddingResponse{
+			Data: []domain.Embedding{{Index: 0, Embedding: []float32{1}}},
+		}, nil)
+		uc := NewEmbeddingUseCase(mockRepo, 2)
+
+		_, err := uc.CreateEmbeddings(context.Background(), domain.EmbeddingRequest{Input: domain.EmbeddingInput{"a", "b"}})
+
+		assert.EqualError(t, err, "embeddings provider returned 1 embeddings for 2 inputs")
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		mockRepo := &MockEmbeddingRepository{}
+		mockRepo.On("Embed", mock.Anything).Return(&domain.EmbeddingResponse{
+			Data: []domain.Embedding{{Index: 5, Embedding: []float32{1}}},
+		}, nil)
+		uc := NewEmbeddingUseCase(mockRepo, 2)
+
+		_, err := uc.CreateEmbeddings(context.Background(), domain.EmbeddingRequest{Input: domain.EmbeddingInput{"a"}})
+
+		assert.EqualError(t, err, "embeddings provider returned an unknown index 5")
+	})
+}
+
+func TestEmbeddingUseCaseImpl_Embed(t *testing.T) {
+	mockRepo := &MockEmbeddingRepository{}
+	mockRepo.On("Embed", domain.EmbeddingRequest{Input: domain.EmbeddingInput{"a", "b"}}).
+		Return(&domain.EmbeddingResponse{
+			Data: []domain.Embedding{
+				{Index: 0, Embedding: []float32{1, 0}},
+				{Index: 1, Embedding: []float32{0, 1}},
+			},
+		}, nil)
+	uc := NewEmbeddingUseCase(mockRepo, 0)
+
+	vectors, err := uc.Embed(context.Background(), []string{"a", "b"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+}
@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"hash/fnv"
+	"math"
+	"prompthor/internal/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of latest latencies kept per variant to compute its percentiles
+const latencySamples = 1000
+
+// variantStats accumulates the statistics of a variant
+type variantStats struct {
+	requests     int
+	errors       int
+	latency      time.Duration
+	samples      []time.Duration
+	next         int
+	inputTokens  int
+	outputTokens int
+	cost         float64
+	feedback     int
+	score        float64
+}
+
+// ExperimentUseCaseImpl implements ExperimentUseCase around a chat use case
+type ExperimentUseCaseImpl struct {
+	chatUseCase domain.ChatUseCase
+	experiments []domain.Experiment
+	prices      map[string]domain.ModelPrice
+	mu          sync.Mutex
+	stats       map[string]map[string]*variantStats
+}
+
+// NewExperimentUseCase creates a new instance of the experiment use case sending the requests to chatUseCase
+func NewExperimentUseCase(chatUseCase domain.ChatUseCase, experiments domain.Experiments) (*ExperimentUseCaseImpl, error) {
+	uc := &ExperimentUseCaseImpl{
+		chatUseCase: chatUseCase,
+		experiments: experiments.Experiments,
+		prices:      experiments.Prices,
+		stats:       make(map[string]map[string]*variantStats),
+	}
+	templates := make(map[string]string)
+	for _, experiment := range experiments.Experiments {
+		if experiment.Name == "" {
+			return nil, fmt.Errorf("experiments need a name")
+		}
+		if _, ok := uc.stats[experiment.Name]; ok {
+			return nil, fmt.Errorf("experiment %s is declared twice", experiment.Name)
+		}
+		if other, ok := templates[experiment.Template]; ok {
+			return nil, fmt.Errorf("experiments %s and %s split the same requests", other, experiment.Name)
+		}
+		templates[experiment.Template] = experiment.Name
+		if len(experiment.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %s has no variants", experiment.Name)
+		}
+		uc.stats[experiment.Name] = make(map[string]*variantStats)
+		for _, variant := range experiment.Variants {
+			if variant.Name == "" || variant.Weight <= 0 {
+				return nil, fmt.Errorf("variants of experiment %s need a name and a positive weight", experiment.Name)
+			}
+			if _, ok := uc.stats[experiment.Name][variant.Name]; ok {
+				return nil, fmt.Errorf("variant %s of experiment %s is declared twice", variant.Name, experiment.Name)
+			}
+			uc.stats[experiment.Name][variant.Name] = &variantStats{}
+		}
+	}
+	return uc, nil
+}
+
+// ProcessChat assigns the request to a variant of its experiment, processes it with the variant overrides and records
+// the latency, cost and errors of the variant. Requests without X-Routing-Key nor client_id are not assigned.
+func (uc *ExperimentUseCaseImpl) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
+	experiment := uc.experimentFor(prompt)
+	if experiment == nil {
+		return uc.chatUseCase.ProcessChat(ctx, prompt)
+	}
+	key := subjectKey(ctx, prompt)
+	if key == "" {
+		log.Ctx(ctx).Debug().Msgf("request without routing key nor client id left out of experiment %s", experiment.Name)
+		return uc.chatUseCase.ProcessChat(ctx, prompt)
+	}
+	variant := assignVariant(*experiment, key)
+	prompt = applyVariant(prompt, variant)
+
+	start := time.Now()
+	response, err := uc.chatUseCase.ProcessChat(ctx, prompt)
+	latency := time.Since(start)
+	uc.record(experiment.Name, variant.Name, latency, prompt.Model, response, err)
+	log.Ctx(ctx).Info().
+		Str("experiment", experiment.Name).
+		Str("variant", variant.Name).
+		Dur("latency", latency).
+		Bool("failed", err != nil).
+		Msg("experiment variant processed")
+	if err != nil {
+		return nil, err
+	}
+	response.Experiment = &domain.ExperimentAssignment{Experiment: experiment.Name, Variant: variant.Name}
+	return response, nil
+}
+
+// Stats returns the statistics of the variants of every experiment
+func (uc *ExperimentUseCaseImpl) Stats(ctx context.Context) ([]domain.ExperimentStats, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	experiments := make([]domain.ExperimentStats, 0, len(uc.experiments))
+	for _, experiment := range uc.experiments {
+		stats := domain.ExperimentStats{
+			Name:     experiment.Name,
+			Template: experiment.Template,
+			Variants: make([]domain.VariantStats, 0, len(experiment.Variants)),
+		}
+		for _, variant := range experiment.Variants {
+			stats.Variants = append(stats.Variants, uc.stats[experiment.Name][variant.Name].summary(variant))
+		}
+		experiments = append(experiments, stats)
+	}
+	return experiments, nil
+}
+
+// Feedback records the score given to an answer of a variant
+func (uc *ExperimentUseCaseImpl) Feedback(ctx context.Context, experiment string, feedback domain.Feedback) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	stats, ok := uc.stats[experiment][feedback.Variant]
+	if !ok {
+		return domain.ErrExperimentNotFound
+	}
+	stats.feedback++
+	stats.score += *feedback.Score
+	return nil
+}
+
+// experimentFor returns the experiment splitting the requests of the template of the prompt, or every request
+func (uc *ExperimentUseCaseImpl) experimentFor(prompt domain.PromptRequest) *domain.Experiment {
+	var fallback *domain.Experiment
+	for i := range uc.experiments {
+		switch uc.experiments[i].Template {
+		case prompt.Template:
+			return &uc.experiments[i]
+		case "":
+			fallback = &uc.experiments[i]
+		}
+	}
+	return fallback
+}
+
+// record adds the outcome of a request to the statistics of its variant
+func (uc *ExperimentUseCaseImpl) record(experiment, variant string, latency time.Duration, model string, response *domain.ChatResponse, err error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	stats := uc.stats[experiment][variant]
+	stats.requests++
+	stats.latency += latency
+	if len(stats.samples) < latencySamples {
+		stats.samples = append(stats.samples, latency)
+	} else {
+		stats.samples[stats.next] = latency
+		stats.next = (stats.next + 1) % latencySamples
+	}
+	if err != nil {
+		stats.errors++
+		return
+	}
+	if response.Usage == nil {
+		return
+	}
+	stats.inputTokens += response.Usage.InputTokens
+	stats.outputTokens += response.Usage.OutputTokens
+	if response.Model != "" {
+		model = response.Model
+	}
+	if price, ok := uc.prices[model]; ok {
+		stats.cost += (float64(response.Usage.InputTokens)*price.Input + float64(response.Usage.OutputTokens)*price.Output) / 1e6
+	}
+}
+
+// summary returns the statistics of the variant
+func (s *variantStats) summary(variant domain.Variant) domain.VariantStats {
+	summary := domain.VariantStats{
+		Name:         variant.Name,
+		Weight:       variant.Weight,
+		Requests:     s.requests,
+		Errors:       s.errors,
+		InputTokens:  s.inputTokens,
+		OutputTokens: s.outputTokens,
+		CostUSD:      s.cost,
+		Feedback:     s.feedback,
+	}
+	if s.requests > 0 {
+		summary.AvgLatencyMs = milliseconds(s.latency) / float64(s.requests)
+		samples := append([]time.Duration(nil), s.samples...)
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i] < samples[j]
+		})
+		summary.P95LatencyMs = milliseconds(samples[int(math.Ceil(0.95*float64(len(samples))))-1])
+	}
+	if s.feedback > 0 {
+		summary.AvgScore = s.score / float64(s.feedback)
+	}
+	return summary
+}
+
+// subjectKey returns the key assigning the request to a variant, its X-Routing-Key or its client id
+func subjectKey(ctx context.Context, prompt domain.PromptRequest) string {
+	if key := domain.RequestIDsFrom(ctx).RoutingKey; key != "" {
+		return key
+	}
+	return prompt.ClientID
+}
+
+// assignVariant picks the variant of the key by its weight, the same key always gets the same variant
+func assignVariant(experiment domain.Experiment, key string) domain.Variant {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(experiment.Name + "\x00" + key))
+	bucket := int(hash.Sum64() % uint64(total))
+	for _, variant := range experiment.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return experiment.Variants[len(experiment.Variants)-1]
+}
+
+// applyVariant overrides the template version, the model and the parameters of the prompt with the ones of the variant
+func applyVariant(prompt domain.PromptRequest, variant domain.Variant) domain.PromptRequest {
+	if variant.TemplateVersion > 0 && prompt.Template != "" {
+		prompt.TemplateVersion = variant.TemplateVersion
+	}
+	if variant.Model != "" {
+		prompt.Model = variant.Model
+	}
+	if variant.Temperature != nil {
+		prompt.Temperature = variant.Temperature
+	}
+	if variant.MaxTokens > 0 {
+		prompt.MaxTokens = variant.MaxTokens
+	}
+	return prompt
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
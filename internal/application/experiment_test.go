@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// supportExperiment runs every request of the support template on a single variant
+var supportExperiment = domain.Experiment{
+	Name:     "support-prompt",
+	Template: "support",
+	Variants: []domain.Variant{{Name: "v3-gpt-4o", Weight: 1, TemplateVersion: 3, Model: "gpt-4o", MaxTokens: 400}},
+}
+
+func TestNewExperimentUseCase_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		experiments []domain.Experiment
+		expected    string
+	}{
+		{
+			name:        "no variants",
+			experiments: []domain.Experiment{{Name: "empty"}},
+			expected:    "experiment empty has no variants",
+		},
+		{
+			name:        "no weight",
+			experiments: []domain.Experiment{{Name: "zero", Variants: []domain.Variant{{Name: "a"}}}},
+			expected:    "variants of experiment zero need a name and a positive weight",
+		},
+		{
+			name: "duplicated variant",
+			experiments: []domain.Experiment{{Name: "twice", Variants: []domain.Variant{
+				{Name: "a", Weight: 1}, {Name: "a", Weight: 1},
+			}}},
+			expected: "variant a of experiment twice is declared twice",
+		},
+		{
+			name:        "same template",
+			experiments: []domain.Experiment{supportExperiment, {Name: "other", Template: "support", Variants: supportExperiment.Variants}},
+			expected:    "experiments support-prompt and other split the same requests",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExperimentUseCase(&MockChatUseCase{}, domain.Experiments{Experiments: tt.experiments})
+
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestExperimentUseCaseImpl_ProcessChat(t *testing.T) {
+	prices := map[string]domain.ModelPrice{"gpt-4o": {Input: 2.5, Output: 10}}
+
+	t.Run("variant overrides the request and is recorded", func(t *testing.T) {
+		mockChat := &MockChatUseCase{}
+		mockChat.On("ProcessChat", domain.PromptRequest{
+			Prompt: "Hi", Template: "support", TemplateVersion: 3, Model: "gpt-4o", MaxTokens: 400, ClientID: "client-1",
+		}).Return(&domain.ChatResponse{
+			Response: "Hello",
+			Model:    "gpt-4o-2024-08-06",
+			Usage:    &domain.Usage{InputTokens: 1000, OutputTokens: 500, TotalTokens: 1500},
+		}, nil)
+		uc, err := NewExperimentUseCase(mockChat, domain.Experiments{
+			Prices:      map[string]domain.ModelPrice{"gpt-4o-2024-08-06": {Input: 2.5, Output: 10}},
+			Experiments: []domain.Experiment{supportExperiment},
+		})
+		require.NoError(t, err)
+
+		response, err := uc.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Template: "support", ClientID: "client-1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.ExperimentAssignment{Experiment: "support-prompt", Variant: "v3-gpt-4o"}, response.Experiment)
+		stats, _ := uc.Stats(context.Background())
+		require.Len(t, stats, 1)
+		variant := stats[0].Variants[0]
+		assert.Equal(t, 1, variant.Requests)
+		assert.Equal(t, 1000, variant.InputTokens)
+		assert.Equal(t, 500, variant.OutputTokens)
+		assert.InDelta(t, 0.0075, variant.CostUSD, 1e-9)
+		assert.Equal(t, variant.AvgLatencyMs, variant.P95LatencyMs)
+	})
+
+	t.Run("routing key assigns the variant", func(t *testing.T) {
+		mockChat := &MockChatUseCase{}
+		mockChat.On("ProcessChat", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			return prompt.Model == "gpt-4o"
+		})).Return(&domain.ChatResponse{Response: "Hello"}, nil)
+		uc, _ := NewExperimentUseCase(mockChat, domain.Experiments{Prices: prices, Experiments: []domain.Experiment{supportExperiment}})
+		ctx := domain.WithRequestIDs(context.Background(), domain.RequestIDs{RoutingKey: "telegram:12345"})
+
+		response, err := uc.ProcessChat(ctx, domain.PromptRequest{Prompt: "Hi", Template: "support"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "v3-gpt-4o", response.Experiment.Variant)
+	})
+
+	t.Run("requests without key are not assigned", func(t *testing.T) {
+		mockChat := &MockChatUseCase{}
+		mockChat.On("ProcessChat", domain.PromptRequest{Prompt: "Hi", Template: "support"}).Return(&domain.ChatResponse{Response: "Hello"}, nil)
+		uc, _ := NewExperimentUseCase(mockChat, domain.Experiments{Experiments: []domain.Experiment{supportExperiment}})
+
+		response, err := uc.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Template: "support"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, response.Experiment)
+	})
+
+	t.Run("requests of other templates are not assigned", func(t *testing.T) {
+		mockChat := &MockChatUseCase{}
+		mockChat.On("ProcessChat", mock.Anything).Return(&domain.ChatResponse{Response: "Hello"}, nil)
+		uc, _ := NewExperimentUseCase(mockChat, domain.Experiments{Experiments: []domain.Experiment{supportExperiment}})
+
+		response, err := uc.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", ClientID: "client-1"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, response.Experiment)
+	})
+
+	t.Run("errors are recorded", func(t *testing.T) {
+		mockChat := &MockChatUseCase{}
+		mockChat.On("ProcessChat", mock.Anything).Return(nil, errors.New("provider down"))
+		uc, _ := NewExperimentUseCase(mockChat, domain.Experiments{Experiments: []domain.Experiment{supportExperiment}})
+
+		_, err := uc.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Template: "support", ClientID: "client-1"})
+
+		assert.EqualError(t, err, "provider down")
+		stats, _ := uc.Stats(context.Background())
+		assert.Equal(t, 1, stats[0].Variants[0].Errors)
+	})
+}
+
+func TestAssignVariant(t *testing.T) {
+	experiment := domain.Experiment{Name: "split", Variants: []domain.Variant{
+		{Name: "control", Weight: 80},
+		{Name: "candidate", Weight: 20},
+	}}
+
+	t.Run("same key same variant", func(t *testing.T) {
+		first := assignVariant(experiment, "telegram:12345")
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, first, assignVariant(experiment, "telegram:12345"))
+		}
+	})
+
+	t.Run("traffic follows the weights", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			counts[assignVariant(experiment, fmt.Sprintf("client-%d", i)).Name]++
+		}
+		assert.InDelta(t, 8000, counts["control"], 300)
+		assert.InDelta(t, 2000, counts["candidate"], 300)
+	})
+}
+
+func TestExperimentUseCaseImpl_Feedback(t *testing.T) {
+	uc, _ := NewExperimentUseCase(&MockChatUseCase{}, domain.Experiments{Experiments: []domain.Experiment{supportExperiment}})
+	good, bad := 1.0, 0.0
+
+	assert.NoError(t, uc.Feedback(context.Background(), "support-prompt", domain.Feedback{Variant: "v3-gpt-4o", Score: &good}))
+	assert.NoError(t, uc.Feedback(context.Background(), "support-prompt", domain.Feedback{Variant: "v3-gpt-4o", Score: &bad}))
+	assert.ErrorIs(t, uc.Feedback(context.Background(), "support-prompt", domain.Feedback{Variant: "missing", Score: &good}),
+		domain.ErrExperimentNotFound)
+
+	stats, _ := uc.Stats(context.Background())
+	assert.Equal(t, 2, stats[0].Variants[0].Feedback)
+	assert.Equal(t, 0.5, stats[0].Variants[0].AvgScore)
+}
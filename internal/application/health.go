@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"sync"
+	"time"
+)
+
+// HealthCheck is a dependency checked by the readiness, the API is not ready when a required one is down
+type HealthCheck struct {
+	domain.HealthChecker
+	Required bool
+}
+
+// HealthUseCaseImpl implements HealthUseCase, caching the result of every check
+type HealthUseCaseImpl struct {
+	checks  []HealthCheck
+	ttl     time.Duration
+	timeout time.Duration
+	metrics domain.Metrics
+	mu      sync.Mutex
+	results []domain.DependencyStatus
+}
+
+// NewHealthUseCase creates a new instance of the health use case. The checks are run again when their result is older
+// than ttl and fail when they take longer than timeout. The lookups of their cached results are recorded in metrics,
+// when not nil.
+func NewHealthUseCase(ttl, timeout time.Duration, metrics domain.Metrics, checks ...HealthCheck) *HealthUseCaseImpl {
+	return &HealthUseCaseImpl{
+		checks:  checks,
+		ttl:     ttl,
+		timeout: timeout,
+		metrics: metrics,
+		results: make([]domain.DependencyStatus, len(checks)),
+	}
+}
+
+// Readiness returns the status of every dependency, checking concurrently the ones without a recent result
+func (uc *HealthUseCaseImpl) Readiness(ctx context.Context) domain.Readiness {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i, check := range uc.checks {
+		cached := !uc.results[i].CheckedAt.IsZero() && now.Sub(uc.results[i].CheckedAt) < uc.ttl
+		if uc.metrics != nil {
+			uc.metrics.CacheLookup(domain.CacheReadiness, cached)
+		}
+		if cached {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uc.results[i] = uc.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	readiness := domain.Readiness{
+		Status:       domain.StatusReady,
+		Dependencies: append([]domain.DependencyStatus{}, uc.results...),
+	}
+	for _, result := range uc.results {
+		if result.Required && result.Status == domain.DependencyDown {
+			readiness.Status = domain.StatusNotReady
+		}
+	}
+	return readiness
+}
+
+// run checks a dependency within the timeout, a probe giving up doesn't cancel it since its result is cached
+func (uc *HealthUseCaseImpl) run(ctx context.Context, check HealthCheck) domain.DependencyStatus {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), uc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	status := domain.DependencyStatus{
+		Name:      check.Name(),
+		Status:    domain.DependencyUp,
+		Required:  check.Required,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("dependency %s is down", status.Name)
+		status.Status = domain.DependencyDown
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockHealthChecker is a mock implementation of HealthChecker
+type MockHealthChecker struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockHealthChecker) Name() string {
+	return m.name
+}
+
+func (m *MockHealthChecker) Check(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestHealthUseCaseImpl_Readiness(t *testing.T) {
+	t.Run("ready when every required dependency is up", func(t *testing.T) {
+		provider := &MockHealthChecker{name: "openai"}
+		provider.On("Check", mock.Anything).Return(nil)
+		gateway := &MockHealthChecker{name: "gateway"}
+		gateway.On("Check", mock.Anything).Return(errors.New("connection refused"))
+
+		readiness := NewHealthUseCase(time.Minute, time.Second, nil,
+			HealthCheck{HealthChecker: provider, Required: true},
+			HealthCheck{HealthChecker: gateway},
+		).Readiness(context.Background())
+
+		assert.Equal(t, domain.StatusReady, readiness.Status)
+		require.Len(t, readiness.Dependencies, 2)
+		assert.Equal(t, "openai", readiness.Dependencies[0].Name)
+		assert.Equal(t, domain.DependencyUp, readiness.Dependencies[0].Status)
+		assert.True(t, readiness.Dependencies[0].Required)
+		assert.False(t, readiness.Dependencies[0].CheckedAt.IsZero())
+		assert.Equal(t, domain.DependencyDown, readiness.Dependencies[1].Status)
+		assert.Equal(t, "connection refused", readiness.Dependencies[1].Error)
+	})
+
+	t.Run("not ready when a required dependency is down", func(t *testing.T) {
+		provider := &MockHealthChecker{name: "openai"}
+		provider.On("Check", mock.Anything).Return(errors.New("credentials rejected with status 401"))
+
+		readiness := NewHealthUseCase(time.Minute, time.Second, nil,
+			HealthCheck{HealthChecker: provider, Required: true},
+		).Readiness(context.Background())
+
+		assert.Equal(t, domain.StatusNotReady, readiness.Status)
+		assert.Equal(t, "credentials rejected with status 401", readiness.Dependencies[0].Error)
+	})
+
+	t.Run("caches the results until they expire", func(t *testing.T) {
+		provider := &MockHealthChecker{name: "openai"}
+		provider.On("Check", mock.Anything).Return(nil)
+		cached := NewHealthUseCase(time.Minute, time.Second, nil, HealthCheck{HealthChecker: provider, Required: true})
+
+		cached.Readiness(context.Background())
+		cached.Readiness(context.Background())
+		provider.AssertNumberOfCalls(t, "Check", 1)
+
+		expired := &MockHealthChecker{name: "openai"}
+		expired.On("Check", mock.Anything).Return(nil)
+		uncached := NewHealthUseCase(0, time.Second, nil, HealthCheck{HealthChecker: expired, Required: true})
+
+		uncached.Readiness(context.Background())
+		uncached.Readiness(context.Background())
+		expired.AssertNumberOfCalls(t, "Check", 2)
+	})
+
+	t.Run("records the cache hits and misses", func(t *testing.T) {
+		provider := &MockHealthChecker{name: "openai"}
+		provider.On("Check", mock.Anything).Return(nil)
+		metrics := &MockMetrics{}
+		metrics.On("CacheLookup", domain.CacheReadiness, false).Return().Once()
+		metrics.On("CacheLookup", domain.CacheReadiness, true).Return().Twice()
+		readiness := NewHealthUseCase(time.Minute, time.Second, metrics, HealthCheck{HealthChecker: provider, Required: true})
+
+		readiness.Readiness(context.Background())
+		readiness.Readiness(context.Background())
+		readiness.Readiness(context.Background())
+
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("slow checks time out", func(t *testing.T) {
+		provider := &MockHealthChecker{name: "openai"}
+		provider.On("Check", mock.Anything).Return(context.DeadlineExceeded).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		})
+
+		readiness := NewHealthUseCase(time.Minute, 10*time.Millisecond, nil,
+			HealthCheck{HealthChecker: provider, Required: true},
+		).Readiness(context.Background())
+
+		assert.Equal(t, domain.StatusNotReady, readiness.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), readiness.Dependencies[0].Error)
+	})
+
+	t.Run("ready without dependencies", func(t *testing.T) {
+		readiness := NewHealthUseCase(time.Minute, time.Second, nil).Readiness(context.Background())
+
+		assert.Equal(t, domain.StatusReady, readiness.Status)
+		assert.Empty(t, readiness.Dependencies)
+	})
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactionRule replaces the matches of its pattern, the ones accepted by valid when it is set
+type redactionRule struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// builtinRules are the built-in redaction rules, cards go before phones so their digits aren't taken as a phone number
+var builtinRules = []redactionRule{
+	{name: "email", pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+	{name: "card", pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+	{name: "phone", pattern: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)|\d{1,4})[\s.-]?\d{3,4}[\s.-]?\d{3,4}\b`)},
+}
+
+// Redactor replaces the personal data of the texts with [REDACTED:<rule>]
+type Redactor struct {
+	rules []redactionRule
+}
+
+// NewRedactor creates a redactor with the built-in rules, email, phone and card, and the custom patterns,
+// given as name=regex pairs
+func NewRedactor(rules []string, patterns []string) (*Redactor, error) {
+	enabled := make(map[string]bool, len(rules))
+	for _, name := range rules {
+		known := false
+		for _, rule := range builtinRules {
+			known = known || rule.name == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown redaction rule %q, use email, phone or card", name)
+		}
+		enabled[name] = true
+	}
+	redactor := &Redactor{}
+	for _, rule := range builtinRules {
+		if enabled[rule.name] {
+			redactor.rules = append(redactor.rules, rule)
+		}
+	}
+	for _, pattern := range patterns {
+		name, expression, ok := strings.Cut(pattern, "=")
+		if !ok || name == "" || expression == "" {
+			return nil, fmt.Errorf("invalid redaction pattern %q, use name=regex", pattern)
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+		}
+		redactor.rules = append(redactor.rules, redactionRule{name: name, pattern: compiled})
+	}
+	return redactor, nil
+}
+
+// Redact returns the text with the matches of every rule replaced
+func (r *Redactor) Redact(text string) string {
+	for _, rule := range r.rules {
+		replacement := "[REDACTED:" + rule.name + "]"
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return replacement
+		})
+	}
+	return text
+}
+
+// luhn reports whether the digits of the number pass the Luhn checksum of the card numbers
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+		digit := int(number[i] - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
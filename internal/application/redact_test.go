@@ -0,0 +1,66 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	redactor, err := NewRedactor([]string{"email", "phone", "card"}, []string{`employee=EMP-\d{6}`})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"email", "write to ada.lovelace@example.co.uk please", "write to [REDACTED:email] please"},
+		{"phone", "call me at +1 (555) 123-4567", "call me at [REDACTED:phone]"},
+		{"local phone", "my number is 11 4444-5555", "my number is [REDACTED:phone]"},
+		{"card", "card 4111 1111 1111 1111 expires soon", "card [REDACTED:card] expires soon"},
+		{"card without separators", "pay with 5500005555555559", "pay with [REDACTED:card]"},
+		{"custom pattern", "employee EMP-123456 asked", "employee [REDACTED:employee] asked"},
+		{"dates and amounts are kept", "on 2024-01-01 we paid 1500 USD", "on 2024-01-01 we paid 1500 USD"},
+		{"no personal data", "What is the capital of France?", "What is the capital of France?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactor.Redact(tt.text))
+		})
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	t.Run("only the enabled rules are applied", func(t *testing.T) {
+		redactor, err := NewRedactor([]string{"email"}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "[REDACTED:email] +1 555 123 4567", redactor.Redact("ada@example.com +1 555 123 4567"))
+	})
+
+	t.Run("without rules nothing is redacted", func(t *testing.T) {
+		redactor, err := NewRedactor(nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ada@example.com", redactor.Redact("ada@example.com"))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		_, err := NewRedactor([]string{"passport"}, nil)
+		assert.EqualError(t, err, `unknown redaction rule "passport", use email, phone or card`)
+
+		_, err = NewRedactor(nil, []string{`EMP-\d{6}`})
+		assert.EqualError(t, err, `invalid redaction pattern "EMP-\\d{6}", use name=regex`)
+
+		_, err = NewRedactor(nil, []string{`employee=EMP-(\d{6}`})
+		assert.ErrorContains(t, err, "invalid redaction pattern employee")
+	})
+}
+
+func TestLuhn(t *testing.T) {
+	assert.True(t, luhn("4111 1111 1111 1111"))
+	assert.True(t, luhn("5500-0055-5555-5559"))
+	assert.False(t, luhn("4111 1111 1111 1112"))
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConfigSnapshot is the part of the API built from the configuration, swapped at once by a reload
+type ConfigSnapshot struct {
+	ChatUseCase domain.ChatUseCase
+	// ExperimentUseCase splits the chat requests across the variants of the experiments, nil without experiments
+	ExperimentUseCase domain.ExperimentUseCase
+	HealthUseCase     domain.HealthUseCase
+	// AdminToken reads the bearer token of the administration routes when a request arrives, so a rotated token applies
+	// to the next one. They are not served without it.
+	AdminToken func() (string, error)
+	// Checksum identifies the configuration the snapshot was built from
+	Checksum string
+}
+
+// SnapshotBuilder builds the snapshot of the current configuration
+type SnapshotBuilder func() (ConfigSnapshot, error)
+
+// versionedSnapshot is a snapshot with the version of the configuration it was built from
+type versionedSnapshot struct {
+	ConfigSnapshot
+	version domain.ConfigVersion
+}
+
+// ReloadableUseCase serves the chat requests, the experiments, the readiness and the admin token of the snapshot of
+// the latest valid configuration. A reload swaps the snapshot atomically, the requests in flight finish with the one
+// they started with.
+type ReloadableUseCase struct {
+	build   SnapshotBuilder
+	source  string
+	mu      sync.Mutex
+	current atomic.Pointer[versionedSnapshot]
+}
+
+// NewReloadableUseCase creates the use case serving snapshot, built from the configuration read from source, until
+// build returns the one of a new configuration
+func NewReloadableUseCase(snapshot ConfigSnapshot, source string, build SnapshotBuilder) *ReloadableUseCase {
+	uc := &ReloadableUseCase{
+		build:  build,
+		source: source,
+	}
+	uc.current.Store(&versionedSnapshot{
+		ConfigSnapshot: snapshot,
+		version:        uc.version(1, snapshot.Checksum),
+	})
+	return uc
+}
+
+// ProcessChat processes the request with the snapshot active when it arrives, through its experiments when it has
+func (uc *ReloadableUseCase) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
+	snapshot := uc.current.Load()
+	if snapshot.ExperimentUseCase != nil {
+		return snapshot.ExperimentUseCase.ProcessChat(ctx, prompt)
+	}
+	return snapshot.ChatUseCase.ProcessChat(ctx, prompt)
+}
+
+// Stats returns the statistics of the experiments of the active snapshot, none without experiments
+func (uc *ReloadableUseCase) Stats(ctx context.Context) ([]domain.ExperimentStats, error) {
+	snapshot := uc.current.Load()
+	if snapshot.ExperimentUseCase == nil {
+		return []domain.ExperimentStats{}, nil
+	}
+	return snapshot.ExperimentUseCase.Stats(ctx)
+}
+
+// Feedback records the feedback in the experiments of the active snapshot
+func (uc *ReloadableUseCase) Feedback(ctx context.Context, experiment string, feedback domain.Feedback) error {
+	snapshot := uc.current.Load()
+	if snapshot.ExperimentUseCase == nil {
+		return domain.ErrExperimentNotFound
+	}
+	return snapshot.ExperimentUseCase.Feedback(ctx, experiment, feedback)
+}
+
+// Readiness checks the dependencies of the active snapshot
+func (uc *ReloadableUseCase) Readiness(ctx context.Context) domain.Readiness {
+	return uc.current.Load().HealthUseCase.Readiness(ctx)
+}
+
+// AdminToken reads the admin token of the active snapshot, empty when it has none
+func (uc *ReloadableUseCase) AdminToken(ctx context.Context) (string, error) {
+	adminToken := uc.current.Load().AdminToken
+	if adminToken == nil {
+		return "", nil
+	}
+	return adminToken()
+}
+
+// Version returns the version of the active configuration
+func (uc *ReloadableUseCase) Version(ctx context.Context) domain.ConfigVersion {
+	return uc.current.Load().version
+}
+
+// Reload builds the snapshot of the configuration and swaps it in when the configuration changed.
+// An invalid configuration is rejected with ErrInvalidConfig and the active one keeps serving.
+func (uc *ReloadableUseCase) Reload(ctx context.Context) (domain.ConfigVersion, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current := uc.current.Load()
+	snapshot, err := uc.build()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("configuration rejected, version %d keeps serving", current.version.Version)
+		return current.version, fmt.Errorf("%w: %w", domain.ErrInvalidConfig, err)
+	}
+	if snapshot.Checksum == current.version.Checksum {
+		log.Ctx(ctx).Debug().Msgf("configuration unchanged, version %d keeps serving", current.version.Version)
+		return current.version, nil
+	}
+	next := &versionedSnapshot{
+		ConfigSnapshot: snapshot,
+		version:        uc.version(current.version.Version+1, snapshot.Checksum),
+	}
+	uc.current.Store(next)
+	log.Ctx(ctx).Info().Msgf("🔄 Configuration version %d (%s) applied", next.version.Version, snapshot.Checksum)
+	return next.version, nil
+}
+
+// version creates the version number of the configuration with checksum, loaded now
+func (uc *ReloadableUseCase) version(number int, checksum string) domain.ConfigVersion {
+	return domain.ConfigVersion{
+		Version:  number,
+		Checksum: checksum,
+		Source:   uc.source,
+		LoadedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// staticReadiness is a health use case always returning its readiness
+type staticReadiness domain.Readiness
+
+func (r staticReadiness) Readiness(ctx context.Context) domain.Readiness {
+	return domain.Readiness(r)
+}
+
+// builds returns a builder returning the snapshots in order, then err
+func builds(err error, snapshots ...ConfigSnapshot) SnapshotBuilder {
+	return func() (ConfigSnapshot, error) {
+		if len(snapshots) == 0 {
+			return ConfigSnapshot{}, err
+		}
+		snapshot := snapshots[0]
+		snapshots = snapshots[1:]
+		return snapshot, nil
+	}
+}
+
+// snapshot returns a snapshot of chatUseCase with checksum
+func snapshot(chatUseCase domain.ChatUseCase, checksum string) ConfigSnapshot {
+	return ConfigSnapshot{ChatUseCase: chatUseCase, Checksum: checksum}
+}
+
+func TestNewReloadableUseCase(t *testing.T) {
+	uc := NewReloadableUseCase(snapshot(&MockChatUseCase{}, "abc"), "prompthor.yaml", builds(nil))
+
+	version := uc.Version(context.Background())
+	assert.Equal(t, 1, version.Version)
+	assert.Equal(t, "abc", version.Checksum)
+	assert.Equal(t, "prompthor.yaml", version.Source)
+	assert.False(t, version.LoadedAt.IsZero())
+}
+
+func TestReloadableUseCase_Reload(t *testing.T) {
+	ctx := context.Background()
+	prompt := domain.PromptRequest{Prompt: "hello"}
+
+	t.Run("swaps the chat use case of a new configuration", func(t *testing.T) {
+		first := &MockChatUseCase{}
+		second := &MockChatUseCase{}
+		second.On("ProcessChat", prompt).Return(&domain.ChatResponse{Response: "from the new provider"}, nil)
+		uc := NewReloadableUseCase(snapshot(first, "abc"), "", builds(nil, snapshot(second, "def")))
+
+		version, err := uc.Reload(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, version.Version)
+		assert.Equal(t, "def", version.Checksum)
+		assert.Equal(t, version, uc.Version(ctx))
+		response, err := uc.ProcessChat(ctx, prompt)
+		require.NoError(t, err)
+		assert.Equal(t, "from the new provider", response.Response)
+		first.AssertNotCalled(t, "ProcessChat", mock.Anything)
+	})
+
+	t.Run("swaps the readiness, the experiments and the admin token with the chat use case", func(t *testing.T) {
+		first := snapshot(&MockChatUseCase{}, "abc")
+		first.HealthUseCase = staticReadiness{Status: domain.StatusReady}
+		first.AdminToken = func() (string, error) { return "first-token", nil }
+		experiments, err := NewExperimentUseCase(&MockChatUseCase{}, domain.Experiments{Experiments: []domain.Experiment{
+			{Name: "tone", Variants: []domain.Variant{{Name: "formal", Weight: 1}}},
+		}})
+		require.NoError(t, err)
+		second := snapshot(&MockChatUseCase{}, "def")
+		second.HealthUseCase = staticReadiness{Status: domain.StatusNotReady}
+		second.AdminToken = func() (string, error) { return "second-token", nil }
+		second.ExperimentUseCase = experiments
+		uc := NewReloadableUseCase(first, "", builds(nil, second))
+		token, err := uc.AdminToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "first-token", token)
+		stats, err := uc.Stats(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+		assert.ErrorIs(t, uc.Feedback(ctx, "tone", domain.Feedback{Variant: "formal"}), domain.ErrExperimentNotFound)
+
+		_, err = uc.Reload(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusNotReady, uc.Readiness(ctx).Status)
+		token, err = uc.AdminToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "second-token", token)
+		stats, err = uc.Stats(ctx)
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, "tone", stats[0].Name)
+	})
+
+	t.Run("reads the admin token on every call", func(t *testing.T) {
+		first := snapshot(&MockChatUseCase{}, "abc")
+		reads := 0
+		first.AdminToken = func() (string, error) {
+			reads++
+			if reads > 1 {
+				return "", errors.New("secret file /run/secrets/admin_token is empty")
+			}
+			return "admin-token", nil
+		}
+		uc := NewReloadableUseCase(first, "", builds(nil))
+
+		token, err := uc.AdminToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "admin-token", token)
+		_, err = uc.AdminToken(ctx)
+		assert.EqualError(t, err, "secret file /run/secrets/admin_token is empty")
+		token, err = NewReloadableUseCase(snapshot(&MockChatUseCase{}, "abc"), "", builds(nil)).AdminToken(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, token)
+	})
+
+	t.Run("keeps the version of an unchanged configuration", func(t *testing.T) {
+		uc := NewReloadableUseCase(snapshot(&MockChatUseCase{}, "abc"), "", builds(nil, snapshot(&MockChatUseCase{}, "abc")))
+
+		version, err := uc.Reload(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, version.Version)
+	})
+
+	t.Run("rejects an invalid configuration and keeps serving the active one", func(t *testing.T) {
+		active := &MockChatUseCase{}
+		active.On("ProcessChat", prompt).Return(&domain.ChatResponse{Response: "from the active provider"}, nil)
+		uc := NewReloadableUseCase(snapshot(active, "abc"), "", builds(errors.New("GROQ_API_KEY: required by the groq chat provider")))
+
+		version, err := uc.Reload(ctx)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "GROQ_API_KEY: required by the groq chat provider")
+		assert.Equal(t, 1, version.Version)
+		response, err := uc.ProcessChat(ctx, prompt)
+		require.NoError(t, err)
+		assert.Equal(t, "from the active provider", response.Response)
+	})
+
+	t.Run("finishes the requests in flight with the chat use case they started with", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		first := &MockChatUseCase{}
+		first.On("ProcessChat", prompt).Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).Return(&domain.ChatResponse{Response: "from the old provider"}, nil)
+		second := &MockChatUseCase{}
+		second.On("ProcessChat", prompt).Return(&domain.ChatResponse{Response: "from the new provider"}, nil)
+		uc := NewReloadableUseCase(snapshot(first, "abc"), "", builds(nil, snapshot(second, "def")))
+
+		inFlight := make(chan *domain.ChatResponse)
+		go func() {
+			response, _ := uc.ProcessChat(ctx, prompt)
+			inFlight <- response
+		}()
+		<-started
+		_, err := uc.Reload(ctx)
+		require.NoError(t, err)
+		response, err := uc.ProcessChat(ctx, prompt)
+		require.NoError(t, err)
+		close(release)
+
+		assert.Equal(t, "from the new provider", response.Response)
+		assert.Equal(t, "from the old provider", (<-inFlight).Response)
+	})
+}
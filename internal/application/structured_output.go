@@ -0,0 +1,68 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/jsonschema-go/jsonschema"
+	"prompthor/internal/domain"
+	"strings"
+)
+
+// structuredOutput validates the answers of the model against the response format of a request
+type structuredOutput struct {
+	schema *jsonschema.Resolved
+}
+
+// newStructuredOutput compiles the schema of the response format, failing when the schema itself is invalid
+func newStructuredOutput(format *domain.ResponseFormat) (*structuredOutput, error) {
+	output := &structuredOutput{}
+	if format.Type != domain.ResponseFormatJSONSchema {
+		return output, nil
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(format.Schema, &schema); err != nil {
+		return nil, &domain.ValidationError{Message: fmt.Sprintf("invalid response_format schema: %v", err)}
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, &domain.ValidationError{Message: fmt.Sprintf("invalid response_format schema: %v", err)}
+	}
+	output.schema = resolved
+	return output, nil
+}
+
+// parse returns the JSON object answered by the model once validated against the schema
+func (o *structuredOutput) parse(content string) (json.RawMessage, error) {
+	raw := json.RawMessage(stripCodeFence(content))
+	var instance interface{}
+	if err := json.Unmarshal(raw, &instance); err != nil {
+		return nil, fmt.Errorf("the answer is not valid JSON: %v", err)
+	}
+	if _, ok := instance.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("the answer is not a JSON object")
+	}
+	if o.schema != nil {
+		if err := o.schema.Validate(instance); err != nil {
+			return nil, fmt.Errorf("the answer doesn't match the schema: %v", err)
+		}
+	}
+	return raw, nil
+}
+
+// repairPrompt asks the model to fix its previous answer
+func repairPrompt(err error) string {
+	return fmt.Sprintf("Your previous answer is invalid, %v. Answer again with only the corrected JSON object, without any other text.", err)
+}
+
+// stripCodeFence removes the markdown code fence some models wrap their JSON answers with
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	if newline := strings.IndexByte(content, '\n'); newline >= 0 {
+		content = content[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
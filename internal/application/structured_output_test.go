@@ -0,0 +1,68 @@
+package application
+
+import (
+	"encoding/json"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var personFormat = &domain.ResponseFormat{
+	Type:   domain.ResponseFormatJSONSchema,
+	Name:   "person",
+	Schema: json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"required":["name","age"]}`),
+}
+
+func TestStructuredOutput_Parse(t *testing.T) {
+	output, err := newStructuredOutput(personFormat)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		err      string
+	}{
+		{name: "valid object", content: `{"name":"Ada","age":36}`, expected: `{"name":"Ada","age":36}`},
+		{name: "code fence", content: "```json\n{\"name\":\"Ada\",\"age\":36}\n```", expected: `{"name":"Ada","age":36}`},
+		{name: "not JSON", content: "Ada is 36", err: "the answer is not valid JSON"},
+		{name: "not an object", content: `["Ada",36]`, err: "the answer is not a JSON object"},
+		{name: "schema mismatch", content: `{"name":"Ada","age":"36"}`, err: "the answer doesn't match the schema"},
+		{name: "missing property", content: `{"name":"Ada"}`, err: "the answer doesn't match the schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := output.parse(tt.content)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(parsed))
+		})
+	}
+}
+
+func TestStructuredOutput_JSONObject(t *testing.T) {
+	output, err := newStructuredOutput(&domain.ResponseFormat{Type: domain.ResponseFormatJSONObject})
+	require.NoError(t, err)
+
+	parsed, err := output.parse(`{"anything":true}`)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"anything":true}`, string(parsed))
+}
+
+func TestNewStructuredOutput_InvalidSchema(t *testing.T) {
+	_, err := newStructuredOutput(&domain.ResponseFormat{
+		Type:   domain.ResponseFormatJSONSchema,
+		Schema: json.RawMessage(`{"type":"object","properties":{"name":{"$ref":"#/$defs/missing"}}}`),
+	})
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, validationErr.Message, "invalid response_format schema")
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"prompthor/internal/domain"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateName are the valid template names
+var templateName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// variableName are the valid template variable names, usable as {{.name}}
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TemplateUseCaseImpl implements TemplateUseCase
+type TemplateUseCaseImpl struct {
+	store domain.TemplateStore
+}
+
+// NewTemplateUseCase creates a new instance of the template use case
+func NewTemplateUseCase(store domain.TemplateStore) domain.TemplateUseCase {
+	return &TemplateUseCaseImpl{store: store}
+}
+
+// Create validates the template and stores it as a new version, which becomes the current one
+func (uc *TemplateUseCaseImpl) Create(ctx context.Context, request domain.TemplateRequest) (*domain.Template, error) {
+	role, err := validateTemplate(request)
+	if err != nil {
+		return nil, err
+	}
+	variables := append([]string{}, request.Variables...)
+	created, err := uc.store.Create(ctx, domain.Template{
+		Name:      request.Name,
+		Role:      role,
+		Body:      request.Body,
+		Variables: variables,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store template: %w", err)
+	}
+	log.Ctx(ctx).Info().Msgf("created version %d of template %s", created.Version, created.Name)
+	return created, nil
+}
+
+// ValidateTemplates validates every version of the templates of the store like Create does, so the templates loaded
+// from a directory can't fail when they are rendered
+func ValidateTemplates(ctx context.Context, store domain.TemplateStore) error {
+	templates, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, info := range templates {
+		versions, err := store.Versions(ctx, info.Name)
+		if err != nil {
+			return err
+		}
+		for _, template := range versions {
+			_, err := validateTemplate(domain.TemplateRequest{
+				Name:      template.Name,
+				Role:      template.Role,
+				Body:      template.Body,
+				Variables: template.Variables,
+			})
+			if err != nil {
+				return fmt.Errorf("template %s version %d: %w", template.Name, template.Version, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplate validates the name, variables, body and role of the template and returns its role, user by default
+func validateTemplate(request domain.TemplateRequest) (string, error) {
+	if !templateName.MatchString(request.Name) {
+		return "", &domain.InputError{Message: fmt.Sprintf("invalid template name %q, use up to 64 letters, digits, - or _", request.Name)}
+	}
+	declared := make(map[string]bool, len(request.Variables))
+	for _, variable := range request.Variables {
+		if !variableName.MatchString(variable) {
+			return "", &domain.InputError{Message: fmt.Sprintf("invalid variable name %q, use letters, digits or _", variable)}
+		}
+		if declared[variable] {
+			return "", &domain.InputError{Message: fmt.Sprintf("variable %s is declared twice", variable)}
+		}
+		declared[variable] = true
+	}
+	parsed, err := parseTemplate(request.Name, request.Body)
+	if err != nil {
+		return "", &domain.InputError{Message: fmt.Sprintf("invalid template body: %v", err)}
+	}
+	var undeclared []string
+	for _, field := range templateFields(parsed.Tree.Root, true) {
+		if !declared[field] && !slices.Contains(undeclared, field) {
+			undeclared = append(undeclared, field)
+		}
+	}
+	if len(undeclared) > 0 {
+		return "", &domain.InputError{Message: fmt.Sprintf("undeclared variables in the template body: %s", strings.Join(undeclared, ", "))}
+	}
+	switch request.Role {
+	case "":
+		return domain.RoleUser, nil
+	case domain.RoleSystem, domain.RoleUser:
+		return request.Role, nil
+	default:
+		return "", &domain.InputError{Message: fmt.Sprintf("invalid template role %q, use system or user", request.Role)}
+	}
+}
+
+// Get returns a version of the template, the current one when version is 0
+func (uc *TemplateUseCaseImpl) Get(ctx context.Context, name string, version int) (*domain.Template, error) {
+	return uc.store.Get(ctx, name, version)
+}
+
+// Versions returns every version of the template
+func (uc *TemplateUseCaseImpl) Versions(ctx context.Context, name string) ([]domain.Template, error) {
+	return uc.store.Versions(ctx, name)
+}
+
+// List returns the templates with their current and latest versions
+func (uc *TemplateUseCaseImpl) List(ctx context.Context) ([]domain.TemplateInfo, error) {
+	return uc.store.List(ctx)
+}
+
+// SetCurrent makes a version the current one of the template, rolling it back or forward, and returns it
+func (uc *TemplateUseCaseImpl) SetCurrent(ctx context.Context, name string, version int) (*domain.Template, error) {
+	if err := uc.store.SetCurrent(ctx, name, version); err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Msgf("version %d is the current version of template %s", version, name)
+	return uc.store.Get(ctx, name, version)
+}
+
+// Render renders a version of the template, the current one when version is 0, with the variables.
+// Every declared variable is required, a body failing to render with them is a template bug.
+func (uc *TemplateUseCaseImpl) Render(ctx context.Context, name string, version int, variables map[string]any) (*domain.RenderedTemplate, error) {
+	stored, err := uc.store.Get(ctx, name, version)
+	if errors.Is(err, domain.ErrTemplateNotFound) {
+		if version == 0 {
+			return nil, &domain.InputError{Message: fmt.Sprintf("template %s not found", name)}
+		}
+		return nil, &domain.InputError{Message: fmt.Sprintf("template %s version %d not found", name, version)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, variable := range stored.Variables {
+		if _, ok := variables[variable]; !ok {
+			missing = append(missing, variable)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &domain.InputError{Message: fmt.Sprintf("missing variables for template %s: %s", name, strings.Join(missing, ", "))}
+	}
+	parsed, err := parseTemplate(stored.Name, stored.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s version %d: %w", name, stored.Version, err)
+	}
+	if variables == nil {
+		variables = map[string]any{}
+	}
+	var text bytes.Buffer
+	if err := parsed.Execute(&text, variables); err != nil {
+		return nil, fmt.Errorf("failed to render template %s version %d: %w", name, stored.Version, err)
+	}
+	log.Ctx(ctx).Info().Msgf("rendered version %d of template %s", stored.Version, name)
+	return &domain.RenderedTemplate{
+		Name:    stored.Name,
+		Version: stored.Version,
+		Role:    stored.Role,
+		Text:    text.String(),
+	}, nil
+}
+
+// parseTemplate parses a template body, failing on execution when it uses an undeclared variable
+func parseTemplate(name, body string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(body)
+}
+
+// templateFields returns the fields of the template variables referenced by the node, as {{.name}} where dot is
+// the variables or as {{$.name}}. Dot is the variables in the node when root, range and with bodies move it.
+func templateFields(node parse.Node, root bool) []string {
+	var fields []string
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			fields = append(fields, templateFields(child, root)...)
+		}
+	case *parse.ActionNode:
+		fields = templateFields(node.Pipe, root)
+	case *parse.TemplateNode:
+		fields = templateFields(node.Pipe, root)
+	case *parse.IfNode:
+		fields = branchFields(&node.BranchNode, root, root)
+	case *parse.RangeNode:
+		fields = branchFields(&node.BranchNode, false, root)
+	case *parse.WithNode:
+		fields = branchFields(&node.BranchNode, false, root)
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, command := range node.Cmds {
+			for _, arg := range command.Args {
+				fields = append(fields, templateFields(arg, root)...)
+			}
+		}
+	case *parse.ChainNode:
+		fields = templateFields(node.Node, root)
+	case *parse.FieldNode:
+		if root {
+			fields = []string{node.Ident[0]}
+		}
+	case *parse.VariableNode:
+		if node.Ident[0] == "$" && len(node.Ident) > 1 {
+			fields = []string{node.Ident[1]}
+		}
+	}
+	return fields
+}
+
+// branchFields returns the fields of the template variables referenced by an if, range or with node, dot is the
+// variables in its body when root and in its else branch when elseRoot
+func branchFields(node *parse.BranchNode, root, elseRoot bool) []string {
+	fields := templateFields(node.Pipe, elseRoot)
+	fields = append(fields, templateFields(node.List, root)...)
+	return append(fields, templateFields(node.ElseList, elseRoot)...)
+}
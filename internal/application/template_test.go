@@ -0,0 +1,315 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTemplateStore is a mock implementation of TemplateStore
+type MockTemplateStore struct {
+	mock.Mock
+}
+
+func (m *MockTemplateStore) Create(ctx context.Context, template domain.Template) (*domain.Template, error) {
+	args := m.Called(template)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateStore) Get(ctx context.Context, name string, version int) (*domain.Template, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateStore) Versions(ctx context.Context, name string) ([]domain.Template, error) {
+	args := m.Called(name)
+	return args.Get(0).([]domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateStore) List(ctx context.Context) ([]domain.TemplateInfo, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.TemplateInfo), args.Error(1)
+}
+
+func (m *MockTemplateStore) SetCurrent(ctx context.Context, name string, version int) error {
+	return m.Called(name, version).Error(0)
+}
+
+// supportTemplate is a stored template with two declared variables
+var supportTemplate = &domain.Template{
+	Name:      "support",
+	Version:   2,
+	Role:      domain.RoleSystem,
+	Body:      "You are the support bot of {{.company}}. Answer in {{.language}}.",
+	Variables: []string{"company", "language"},
+}
+
+func TestTemplateUseCaseImpl_Create(t *testing.T) {
+	t.Run("stores a new version", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Create", domain.Template{
+			Name: "greeting", Role: domain.RoleUser, Body: "Say hi to {{.name}}", Variables: []string{"name"},
+		}).Return(&domain.Template{Name: "greeting", Version: 1}, nil)
+		uc := NewTemplateUseCase(mockStore)
+
+		created, err := uc.Create(context.Background(), domain.TemplateRequest{
+			Name: "greeting", Body: "Say hi to {{.name}}", Variables: []string{"name"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, created.Version)
+		mockStore.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name     string
+		request  domain.TemplateRequest
+		expected string
+	}{
+		{
+			name:     "invalid name",
+			request:  domain.TemplateRequest{Name: "support/bot", Body: "Hi"},
+			expected: `invalid template name "support/bot", use up to 64 letters, digits, - or _`,
+		},
+		{
+			name:     "invalid variable",
+			request:  domain.TemplateRequest{Name: "support", Body: "Hi", Variables: []string{"first-name"}},
+			expected: `invalid variable name "first-name", use letters, digits or _`,
+		},
+		{
+			name:     "duplicated variable",
+			request:  domain.TemplateRequest{Name: "support", Body: "Hi", Variables: []string{"name", "name"}},
+			expected: "variable name is declared twice",
+		},
+		{
+			name:     "invalid role",
+			request:  domain.TemplateRequest{Name: "support", Role: domain.RoleAssistant, Body: "Hi"},
+			expected: `invalid template role "assistant", use system or user`,
+		},
+		{
+			name: "undeclared variables",
+			request: domain.TemplateRequest{
+				Name:      "support",
+				Body:      "Hi {{.name}}{{if .vip}}, {{$.title}}{{end}}{{range .items}}{{.label}} {{.name}}{{end}}",
+				Variables: []string{"name", "items"},
+			},
+			expected: "undeclared variables in the template body: vip, title",
+		},
+		{
+			name:     "invalid body",
+			request:  domain.TemplateRequest{Name: "support", Body: "Hi {{.name"},
+			expected: "invalid template body: template: support:1: unclosed action",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := &MockTemplateStore{}
+			uc := NewTemplateUseCase(mockStore)
+
+			_, err := uc.Create(context.Background(), tt.request)
+
+			var inputErr *domain.InputError
+			assert.ErrorAs(t, err, &inputErr)
+			assert.EqualError(t, err, tt.expected)
+			mockStore.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	t.Run("accepts the valid versions", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("List").Return([]domain.TemplateInfo{{Name: "support", Current: 2, Latest: 2}}, nil)
+		mockStore.On("Versions", "support").Return([]domain.Template{*supportTemplate}, nil)
+
+		assert.NoError(t, ValidateTemplates(context.Background(), mockStore))
+	})
+
+	t.Run("rejects an invalid version", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("List").Return([]domain.TemplateInfo{{Name: "support", Current: 2, Latest: 3}}, nil)
+		mockStore.On("Versions", "support").Return([]domain.Template{
+			*supportTemplate,
+			{Name: "support", Version: 3, Role: domain.RoleUser, Body: "Hello {{.name}}"},
+		}, nil)
+
+		err := ValidateTemplates(context.Background(), mockStore)
+
+		assert.EqualError(t, err, "template support version 3: undeclared variables in the template body: name")
+	})
+
+	t.Run("rejects an unknown role", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("List").Return([]domain.TemplateInfo{{Name: "support", Current: 1, Latest: 1}}, nil)
+		mockStore.On("Versions", "support").Return([]domain.Template{
+			{Name: "support", Version: 1, Role: domain.RoleAssistant, Body: "Hello"},
+		}, nil)
+
+		err := ValidateTemplates(context.Background(), mockStore)
+
+		assert.EqualError(t, err, `template support version 1: invalid template role "assistant", use system or user`)
+	})
+}
+
+func TestTemplateUseCaseImpl_Render(t *testing.T) {
+	t.Run("renders the current version", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 0).Return(supportTemplate, nil)
+		uc := NewTemplateUseCase(mockStore)
+
+		rendered, err := uc.Render(context.Background(), "support", 0, map[string]any{"company": "Acme", "language": "Spanish"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.RenderedTemplate{
+			Name:    "support",
+			Version: 2,
+			Role:    domain.RoleSystem,
+			Text:    "You are the support bot of Acme. Answer in Spanish.",
+		}, rendered)
+	})
+
+	t.Run("missing variables", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 0).Return(supportTemplate, nil)
+		uc := NewTemplateUseCase(mockStore)
+
+		_, err := uc.Render(context.Background(), "support", 0, map[string]any{"language": "Spanish"})
+
+		var inputErr *domain.InputError
+		assert.ErrorAs(t, err, &inputErr)
+		assert.EqualError(t, err, "missing variables for template support: company")
+	})
+
+	t.Run("undeclared variable in the body is a template bug", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "greeting", 1).Return(&domain.Template{Name: "greeting", Version: 1, Body: "Hi {{.name}}"}, nil)
+		uc := NewTemplateUseCase(mockStore)
+
+		_, err := uc.Render(context.Background(), "greeting", 1, nil)
+
+		var inputErr *domain.InputError
+		assert.False(t, errors.As(err, &inputErr))
+		assert.ErrorContains(t, err, "failed to render template greeting version 1")
+		assert.ErrorContains(t, err, `map has no entry for key "name"`)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 7).Return(nil, domain.ErrTemplateNotFound)
+		uc := NewTemplateUseCase(mockStore)
+
+		_, err := uc.Render(context.Background(), "support", 7, nil)
+
+		assert.EqualError(t, err, "template support version 7 not found")
+	})
+}
+
+func TestTemplateUseCaseImpl_SetCurrent(t *testing.T) {
+	t.Run("rolls back", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("SetCurrent", "support", 1).Return(nil)
+		mockStore.On("Get", "support", 1).Return(&domain.Template{Name: "support", Version: 1}, nil)
+		uc := NewTemplateUseCase(mockStore)
+
+		template, err := uc.SetCurrent(context.Background(), "support", 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, template.Version)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("SetCurrent", "support", 9).Return(domain.ErrTemplateNotFound)
+		uc := NewTemplateUseCase(mockStore)
+
+		_, err := uc.SetCurrent(context.Background(), "support", 9)
+
+		assert.ErrorIs(t, err, domain.ErrTemplateNotFound)
+	})
+}
+
+func TestChatUseCaseImpl_ProcessChat_Template(t *testing.T) {
+	variables := map[string]any{"company": "Acme", "language": "Spanish"}
+
+	t.Run("system template is sent before the conversation", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 0).Return(supportTemplate, nil)
+		mockRepo := &MockLLMRepository{}
+		mockRepo.On("Send", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			conversation := prompt.Conversation()
+			return len(conversation) == 2 &&
+				conversation[0].Role == domain.RoleSystem &&
+				conversation[0].Content == "You are the support bot of Acme. Answer in Spanish." &&
+				conversation[1].Content == "Where is my order?"
+		})).Return(&domain.LLMResponse{Content: "Está en camino."}, nil)
+		useCase := NewChatUseCase(mockRepo, WithTemplates(NewTemplateUseCase(mockStore)))
+
+		response, err := useCase.ProcessChat(context.Background(),
+			domain.PromptRequest{Prompt: "Where is my order?", Template: "support", Variables: variables})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Está en camino.", response.Response)
+	})
+
+	t.Run("user template is the prompt", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "summary", 3).Return(&domain.Template{
+			Name: "summary", Version: 3, Role: domain.RoleUser, Body: "Summarize: {{.text}}", Variables: []string{"text"},
+		}, nil)
+		mockRepo := &MockLLMRepository{}
+		mockRepo.On("Send", mock.MatchedBy(func(prompt domain.PromptRequest) bool {
+			return prompt.Prompt == "Summarize: a long text"
+		})).Return(&domain.LLMResponse{Content: "A text."}, nil)
+		useCase := NewChatUseCase(mockRepo, WithTemplates(NewTemplateUseCase(mockStore)))
+
+		response, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{
+			Template: "summary", TemplateVersion: 3, Variables: map[string]any{"text": "a long text"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "A text.", response.Response)
+	})
+
+	t.Run("system template without prompt", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 0).Return(supportTemplate, nil)
+		mockRepo := &MockLLMRepository{}
+		useCase := NewChatUseCase(mockRepo, WithTemplates(NewTemplateUseCase(mockStore)))
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Template: "support", Variables: variables})
+
+		assert.EqualError(t, err, "template support is a system prompt, send a prompt with it")
+		mockRepo.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("missing variables", func(t *testing.T) {
+		mockStore := &MockTemplateStore{}
+		mockStore.On("Get", "support", 0).Return(supportTemplate, nil)
+		mockRepo := &MockLLMRepository{}
+		useCase := NewChatUseCase(mockRepo, WithTemplates(NewTemplateUseCase(mockStore)))
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hi", Template: "support"})
+
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+		mockRepo.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("templates not enabled", func(t *testing.T) {
+		useCase := NewChatUseCase(&MockLLMRepository{})
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Template: "support"})
+
+		assert.EqualError(t, err, "templates are not enabled")
+	})
+}
@@ -2,31 +2,378 @@ package application
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"prompthor/internal/domain"
+	"time"
 )
 
+// defaultAgentMaxIterations is the number of model calls allowed per request when tools are registered
+const defaultAgentMaxIterations = 5
+
+// defaultRetrieveTopK is the number of chunks of a collection sent with the prompt
+const defaultRetrieveTopK = 4
+
+// defaultMaxRepairs is the number of times the model is asked to fix an answer not matching the response format
+const defaultMaxRepairs = 2
+
 // ChatUseCaseImpl implements ChatUseCase
 type ChatUseCaseImpl struct {
-	chatRepository domain.LLMRepository
+	chatRepository     domain.LLMRepository
+	stripReasoning     bool
+	toolRegistry       domain.ToolRegistry
+	agentMaxIterations int
+	agentTimeout       time.Duration
+	maxRepairs         int
+	maxImageBytes      int
+	retriever          domain.Retriever
+	retrieveTopK       int
+	templates          domain.TemplateRenderer
+	metrics            domain.Metrics
+}
+
+// Option configures optional behavior of the chat use case
+type Option func(*ChatUseCaseImpl)
+
+// WithStripReasoning removes the reasoning of reasoning models from the responses sent to the clients
+func WithStripReasoning(strip bool) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.stripReasoning = strip
+	}
+}
+
+// WithToolRegistry offers the registered tools to the model and executes them until it gives a final answer
+func WithToolRegistry(registry domain.ToolRegistry) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.toolRegistry = registry
+	}
+}
+
+// WithAgentBudget limits the model calls and the total time spent answering a request that runs tools.
+// Zero values keep the defaults: 5 iterations and no timeout.
+func WithAgentBudget(maxIterations int, timeout time.Duration) Option {
+	return func(uc *ChatUseCaseImpl) {
+		if maxIterations > 0 {
+			uc.agentMaxIterations = maxIterations
+		}
+		uc.agentTimeout = timeout
+	}
+}
+
+// WithMaxRepairs sets how many times the model is asked to fix an answer not matching the requested response format
+func WithMaxRepairs(maxRepairs int) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.maxRepairs = maxRepairs
+	}
+}
+
+// WithMaxImageBytes limits the size of the base64 encoded images sent in the requests
+func WithMaxImageBytes(maxImageBytes int) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.maxImageBytes = maxImageBytes
+	}
+}
+
+// WithRetriever answers the prompts requesting a collection with its topK chunks closest to the prompt
+func WithRetriever(retriever domain.Retriever, topK int) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.retriever = retriever
+		if topK > 0 {
+			uc.retrieveTopK = topK
+		}
+	}
+}
+
+// WithTemplates renders the stored templates requested by the prompts
+func WithTemplates(templates domain.TemplateRenderer) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.templates = templates
+	}
+}
+
+// WithMetrics counts the requests sent again to the model, like the structured output repairs
+func WithMetrics(metrics domain.Metrics) Option {
+	return func(uc *ChatUseCaseImpl) {
+		uc.metrics = metrics
+	}
 }
 
 // NewChatUseCase creates a new instance of the chat use case
-func NewChatUseCase(chatRepository domain.LLMRepository) domain.ChatUseCase {
-	return &ChatUseCaseImpl{
-		chatRepository: chatRepository,
+func NewChatUseCase(chatRepository domain.LLMRepository, options ...Option) domain.ChatUseCase {
+	uc := &ChatUseCaseImpl{
+		chatRepository:     chatRepository,
+		agentMaxIterations: defaultAgentMaxIterations,
+		maxRepairs:         defaultMaxRepairs,
+		retrieveTopK:       defaultRetrieveTopK,
+	}
+	for _, option := range options {
+		option(uc)
 	}
+	return uc
 }
 
-// ProcessChat processes the chat request
-func (uc *ChatUseCaseImpl) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
-	messageResponse, err := uc.chatRepository.Send(ctx, prompt)
+// ProcessChat processes the chat request.
+// Prompts requesting a template are rendered with their variables, as the prompt or as a system message.
+// Prompts requesting a collection are sent with the documents of the collection closest to them.
+// When the model calls registered tools they are executed and their results sent back to the model,
+// until it answers without tool calls or calls a tool that only the client can run.
+// JSON answers are validated against the response format and the model is asked to repair the invalid ones.
+func (uc *ChatUseCaseImpl) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (_ *domain.ChatResponse, err error) {
+	ctx, span := startSpan(ctx, "ProcessChat")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if err := uc.applyTemplate(ctx, &prompt); err != nil {
+		return nil, err
+	}
+	if err := uc.validateImages(prompt); err != nil {
+		return nil, err
+	}
+	sources, err := uc.retrieve(ctx, &prompt)
 	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("Failed to send message")
 		return nil, err
 	}
+	var output *structuredOutput
+	if prompt.ResponseFormat.IsJSON() {
+		if output, err = newStructuredOutput(prompt.ResponseFormat); err != nil {
+			return nil, err
+		}
+	}
+	request := uc.withRegisteredTools(prompt)
+	if uc.toolRegistry != nil && uc.agentTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, uc.agentTimeout)
+		defer cancel()
+	}
+
+	var usage domain.Usage
+	iterations, repairs := 0, 0
+	for {
+		messageResponse, err := uc.chatRepository.Send(ctx, request)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to send message")
+			return nil, err
+		}
+		usage.InputTokens += messageResponse.Usage.InputTokens
+		usage.OutputTokens += messageResponse.Usage.OutputTokens
+		usage.TotalTokens += messageResponse.Usage.TotalTokens
+
+		if uc.runsToolCalls(prompt, messageResponse.ToolCalls) {
+			iterations++
+			span.SetAttributes(attribute.Int("prompthor.agent.iterations", iterations))
+			if iterations >= uc.agentMaxIterations {
+				log.Ctx(ctx).Error().Msgf("agent stopped after %d iterations", iterations)
+				return nil, domain.ErrAgentMaxIterations
+			}
+			request.Messages = append(request.Conversation(), domain.Message{
+				Role:      domain.RoleAssistant,
+				Content:   messageResponse.Content,
+				ToolCalls: messageResponse.ToolCalls,
+			})
+			request.Messages = append(request.Messages, uc.executeToolCalls(ctx, messageResponse.ToolCalls)...)
+			request.Prompt = ""
+			// a forced tool has already been called, let the model answer with its result
+			if request.ToolChoice != domain.ToolChoiceNone {
+				request.ToolChoice = ""
+			}
+			continue
+		}
+
+		response := uc.chatResponse(messageResponse, usage)
+		response.Sources = sources
+		if output == nil || len(messageResponse.ToolCalls) > 0 || messageResponse.Refusal != "" {
+			return response, nil
+		}
+		parsed, err := uc.parseOutput(ctx, output, messageResponse.Content)
+		if err == nil {
+			response.Parsed = parsed
+			return response, nil
+		}
+		if repairs >= uc.maxRepairs {
+			log.Ctx(ctx).Error().Err(err).Msgf("structured output still invalid after %d repairs", repairs)
+			return nil, &domain.ValidationError{Message: err.Error(), Output: messageResponse.Content}
+		}
+		repairs++
+		span.SetAttributes(attribute.Int("prompthor.structured_output.repairs", repairs))
+		if uc.metrics != nil {
+			uc.metrics.Retry(domain.RetryStructuredOutput)
+		}
+		log.Ctx(ctx).Warn().Err(err).Msgf("asking the model to repair its answer, attempt %d", repairs)
+		request.Messages = append(request.Conversation(),
+			domain.Message{Role: domain.RoleAssistant, Content: messageResponse.Content},
+			domain.Message{Role: domain.RoleUser, Content: repairPrompt(err)})
+		request.Prompt = ""
+	}
+}
+
+// parseOutput validates the answer of the model against the response format
+func (uc *ChatUseCaseImpl) parseOutput(ctx context.Context, output *structuredOutput, content string) (parsed json.RawMessage, err error) {
+	_, span := startSpan(ctx, "ParseStructuredOutput")
+	defer func() {
+		endSpan(span, err)
+	}()
+	return output.parse(content)
+}
+
+// applyTemplate renders the requested template, a user template is the prompt and a system template is sent before the conversation
+func (uc *ChatUseCaseImpl) applyTemplate(ctx context.Context, prompt *domain.PromptRequest) (err error) {
+	if prompt.Template == "" {
+		return nil
+	}
+	ctx, span := startSpan(ctx, "RenderTemplate", attribute.String("prompthor.template", prompt.Template))
+	defer func() {
+		endSpan(span, err)
+	}()
+	if uc.templates == nil {
+		return &domain.InputError{Message: "templates are not enabled"}
+	}
+	rendered, err := uc.templates.Render(ctx, prompt.Template, prompt.TemplateVersion, prompt.Variables)
+	if err != nil {
+		return err
+	}
+	if rendered.Role == domain.RoleSystem {
+		if len(prompt.Conversation()) == 0 {
+			return &domain.InputError{Message: fmt.Sprintf("template %s is a system prompt, send a prompt with it", rendered.Name)}
+		}
+		prompt.Messages = append([]domain.Message{{Role: domain.RoleSystem, Content: rendered.Text}}, prompt.Messages...)
+		return nil
+	}
+	if prompt.Prompt != "" {
+		return &domain.InputError{Message: fmt.Sprintf("template %s is the prompt, send either a prompt or the template", rendered.Name)}
+	}
+	prompt.Prompt = rendered.Text
+	return nil
+}
+
+// validateImages checks the images of every message of the conversation
+func (uc *ChatUseCaseImpl) validateImages(prompt domain.PromptRequest) error {
+	for _, message := range prompt.Conversation() {
+		for _, image := range message.Images {
+			if err := image.Validate(uc.maxImageBytes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// retrieve sends the chunks of the requested collection closest to the prompt as a system message and returns their sources
+func (uc *ChatUseCaseImpl) retrieve(ctx context.Context, prompt *domain.PromptRequest) (_ []domain.Source, err error) {
+	if prompt.Collection == "" {
+		return nil, nil
+	}
+	ctx, span := startSpan(ctx, "Retrieve", attribute.String("prompthor.collection", prompt.Collection))
+	defer func() {
+		endSpan(span, err)
+	}()
+	if uc.retriever == nil {
+		return nil, &domain.InputError{Message: "collections are not enabled"}
+	}
+	query := prompt.Prompt
+	for i := len(prompt.Messages) - 1; query == "" && i >= 0; i-- {
+		if prompt.Messages[i].Role == domain.RoleUser {
+			query = prompt.Messages[i].Content
+		}
+	}
+	found, err := uc.retriever.Retrieve(ctx, prompt.Collection, query, uc.retrieveTopK)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to retrieve from collection %s", prompt.Collection)
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	chunks := make([]chunk, 0, len(found))
+	for i, c := range found {
+		chunks = append(chunks, chunk{index: i, file: c.File, page: c.Page, text: c.Text, score: c.Score})
+	}
+	instructions, sources := documentsContext(chunks)
+	prompt.Messages = append([]domain.Message{{Role: domain.RoleSystem, Content: instructions}}, prompt.Messages...)
+	return sources, nil
+}
+
+// withRegisteredTools adds the registered tools to the ones declared by the client, the client ones win on a name clash
+func (uc *ChatUseCaseImpl) withRegisteredTools(prompt domain.PromptRequest) domain.PromptRequest {
+	if uc.toolRegistry == nil {
+		return prompt
+	}
+	declared := clientTools(prompt)
+	tools := append([]domain.Tool{}, prompt.Tools...)
+	for _, tool := range uc.toolRegistry.Tools() {
+		if !declared[tool.Name] {
+			tools = append(tools, tool)
+		}
+	}
+	prompt.Tools = tools
+	return prompt
+}
+
+// runsToolCalls reports whether prompthor executes the tool calls, calls to client tools are returned to the client
+func (uc *ChatUseCaseImpl) runsToolCalls(prompt domain.PromptRequest, calls []domain.ToolCall) bool {
+	if uc.toolRegistry == nil || len(calls) == 0 {
+		return false
+	}
+	declared := clientTools(prompt)
+	for _, call := range calls {
+		if _, ok := uc.toolRegistry.Get(call.Name); !ok || declared[call.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// executeToolCalls runs the tool calls and returns a tool message with the result of each one.
+// Failures are sent to the model as the tool result so it can recover from them.
+func (uc *ChatUseCaseImpl) executeToolCalls(ctx context.Context, calls []domain.ToolCall) []domain.Message {
+	messages := make([]domain.Message, 0, len(calls))
+	for _, call := range calls {
+		tool, _ := uc.toolRegistry.Get(call.Name)
+		log.Ctx(ctx).Info().Msgf("executing tool %s", call.Name)
+		toolCtx, span := startSpan(ctx, semconv.GenAIOperationNameExecuteTool.Value.AsString()+" "+call.Name,
+			semconv.GenAIOperationNameExecuteTool, semconv.GenAIToolName(call.Name), semconv.GenAIToolCallID(call.ID))
+		result, err := tool.Execute(toolCtx, call.Arguments)
+		endSpan(span, err)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("tool %s failed", call.Name)
+			result = "error: " + err.Error()
+		}
+		messages = append(messages, domain.Message{
+			Role:       domain.RoleTool,
+			Content:    result,
+			ToolCallID: call.ID,
+		})
+	}
+	return messages
+}
+
+// chatResponse builds the response sent to the client from the last llm answer and the usage of every call
+func (uc *ChatUseCaseImpl) chatResponse(messageResponse *domain.LLMResponse, usage domain.Usage) *domain.ChatResponse {
 	response := domain.ChatResponse{
-		Response: messageResponse,
+		Response:     messageResponse.Content,
+		Refusal:      messageResponse.Refusal,
+		Annotations:  messageResponse.Annotations,
+		ToolCalls:    messageResponse.ToolCalls,
+		Model:        messageResponse.Model,
+		FinishReason: messageResponse.FinishReason,
+	}
+	if !uc.stripReasoning {
+		response.Reasoning = messageResponse.Reasoning
+	}
+	if usage.TotalTokens > 0 {
+		response.Usage = &usage
+	}
+	return &response
+}
+
+// clientTools returns the names of the tools declared in the request
+func clientTools(prompt domain.PromptRequest) map[string]bool {
+	declared := make(map[string]bool, len(prompt.Tools))
+	for _, tool := range prompt.Tools {
+		declared[tool.Name] = true
 	}
-	return &response, nil
+	return declared
 }
@@ -4,10 +4,17 @@ import (
 	"context"
 	"errors"
 	"prompthor/internal/domain"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MockLLMRepository is a mock implementation of LLMRepository
@@ -15,9 +22,9 @@ type MockLLMRepository struct {
 	mock.Mock
 }
 
-func (m *MockLLMRepository) Send(ctx context.Context, prompt domain.PromptRequest) (string, error) {
+func (m *MockLLMRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
 	args := m.Called(prompt)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(*domain.LLMResponse), args.Error(1)
 }
 
 // MockProducerRepository is a mock implementation of ProducerRepository
@@ -33,6 +40,35 @@ func (m *MockProducerRepository) Send(ctx context.Context, message []byte) error
 	return args.Error(0)
 }
 
+// MockMetrics is a mock implementation of Metrics
+type MockMetrics struct {
+	mock.Mock
+}
+
+func (m *MockMetrics) HTTPRequestStarted() {
+	m.Called()
+}
+
+func (m *MockMetrics) HTTPRequestFinished(method, route string, status int, duration time.Duration) {
+	m.Called(method, route, status, duration)
+}
+
+func (m *MockMetrics) LLMRequestStarted(provider string) {
+	m.Called(provider)
+}
+
+func (m *MockMetrics) LLMRequestFinished(provider, model string, duration time.Duration, usage domain.Usage, err error) {
+	m.Called(provider, model, duration, usage, err)
+}
+
+func (m *MockMetrics) Retry(reason string) {
+	m.Called(reason)
+}
+
+func (m *MockMetrics) CacheLookup(cache string, hit bool) {
+	m.Called(cache, hit)
+}
+
 func TestNewChatUseCase(t *testing.T) {
 	mockChatRepo := &MockLLMRepository{}
 	useCase := NewChatUseCase(mockChatRepo)
@@ -41,6 +77,45 @@ func TestNewChatUseCase(t *testing.T) {
 	assert.IsType(t, &ChatUseCaseImpl{}, useCase)
 }
 
+func TestNewChatUseCase_WithOptions(t *testing.T) {
+	mockChatRepo := &MockLLMRepository{}
+	useCase := NewChatUseCase(mockChatRepo, WithStripReasoning(true))
+
+	assert.True(t, useCase.(*ChatUseCaseImpl).stripReasoning)
+}
+
+func TestChatUseCaseImpl_ProcessChat_Reasoning(t *testing.T) {
+	promptRequest := domain.PromptRequest{Prompt: "Why is the sky blue?", ReasoningEffort: "high"}
+	llmResponse := &domain.LLMResponse{
+		Content:   "Because of Rayleigh scattering.",
+		Reasoning: "The user asks about the sky color.",
+	}
+
+	t.Run("reasoning is returned in its own field", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", promptRequest).Return(llmResponse, nil)
+		useCase := NewChatUseCase(mockChatRepo)
+
+		result, err := useCase.ProcessChat(context.Background(), promptRequest)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Because of Rayleigh scattering.", result.Response)
+		assert.Equal(t, "The user asks about the sky color.", result.Reasoning)
+	})
+
+	t.Run("reasoning is stripped", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", promptRequest).Return(llmResponse, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithStripReasoning(true))
+
+		result, err := useCase.ProcessChat(context.Background(), promptRequest)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Because of Rayleigh scattering.", result.Response)
+		assert.Empty(t, result.Reasoning)
+	})
+}
+
 func TestChatUseCaseImpl_ProcessChat_Success(t *testing.T) {
 	mockChatRepo := &MockLLMRepository{}
 	useCase := &ChatUseCaseImpl{
@@ -50,7 +125,7 @@ func TestChatUseCaseImpl_ProcessChat_Success(t *testing.T) {
 	promptRequest := domain.PromptRequest{Prompt: "Hello, how are you?"}
 	expectedResponse := "I'm doing well, thank you!"
 
-	mockChatRepo.On("Send", promptRequest).Return(expectedResponse, nil)
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{Content: expectedResponse}, nil)
 
 	result, err := useCase.ProcessChat(context.Background(), promptRequest)
 
@@ -69,7 +144,7 @@ func TestChatUseCaseImpl_ProcessChat_Error(t *testing.T) {
 	promptRequest := domain.PromptRequest{Prompt: "Hello, how are you?"}
 	expectedError := errors.New("API connection failed")
 
-	mockChatRepo.On("Send", promptRequest).Return("", expectedError)
+	mockChatRepo.On("Send", promptRequest).Return((*domain.LLMResponse)(nil), expectedError)
 
 	result, err := useCase.ProcessChat(context.Background(), promptRequest)
 
@@ -88,7 +163,7 @@ func TestChatUseCaseImpl_ProcessChat_EmptyPrompt(t *testing.T) {
 	promptRequest := domain.PromptRequest{Prompt: ""}
 	expectedResponse := "Please provide a valid prompt"
 
-	mockChatRepo.On("Send", promptRequest).Return(expectedResponse, nil)
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{Content: expectedResponse}, nil)
 
 	result, err := useCase.ProcessChat(context.Background(), promptRequest)
 
@@ -112,7 +187,7 @@ func TestChatUseCaseImpl_ProcessChat_LongPrompt(t *testing.T) {
 	promptRequest := domain.PromptRequest{Prompt: longPrompt}
 	expectedResponse := "Response to long prompt"
 
-	mockChatRepo.On("Send", promptRequest).Return(expectedResponse, nil)
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{Content: expectedResponse}, nil)
 
 	result, err := useCase.ProcessChat(context.Background(), promptRequest)
 
@@ -131,7 +206,7 @@ func TestChatUseCaseImpl_ProcessChat_SpecialCharacters(t *testing.T) {
 	promptRequest := domain.PromptRequest{Prompt: "Hello! @#$%^&*()_+ Ã¤Â½ Ã¥Â¥Â½ Ã°Å¸Å¡â‚¬"}
 	expectedResponse := "Response with special characters handled"
 
-	mockChatRepo.On("Send", promptRequest).Return(expectedResponse, nil)
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{Content: expectedResponse}, nil)
 
 	result, err := useCase.ProcessChat(context.Background(), promptRequest)
 
@@ -140,3 +215,356 @@ func TestChatUseCaseImpl_ProcessChat_SpecialCharacters(t *testing.T) {
 	assert.Equal(t, expectedResponse, result.Response)
 	mockChatRepo.AssertExpectations(t)
 }
+
+func TestChatUseCaseImpl_ProcessChat_WithUsage(t *testing.T) {
+	mockChatRepo := &MockLLMRepository{}
+	useCase := &ChatUseCaseImpl{
+		chatRepository: mockChatRepo,
+	}
+
+	promptRequest := domain.PromptRequest{Prompt: "Hello"}
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{
+		Content:      "World",
+		Model:        "mistral-small-latest",
+		FinishReason: domain.FinishReasonStop,
+		Usage:        domain.Usage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8},
+	}, nil)
+
+	result, err := useCase.ProcessChat(context.Background(), promptRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "World", result.Response)
+	assert.Equal(t, "mistral-small-latest", result.Model)
+	assert.Equal(t, domain.FinishReasonStop, result.FinishReason)
+	assert.Equal(t, &domain.Usage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8}, result.Usage)
+	mockChatRepo.AssertExpectations(t)
+}
+
+func TestChatUseCaseImpl_ProcessChat_RefusalAndAnnotations(t *testing.T) {
+	mockChatRepo := &MockLLMRepository{}
+	useCase := NewChatUseCase(mockChatRepo)
+
+	promptRequest := domain.PromptRequest{Prompt: "Hello"}
+	annotations := []domain.Annotation{{Type: "url_citation", URL: "https://example.com", StartIndex: 0, EndIndex: 5}}
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{
+		Content:      "Hello",
+		Refusal:      "I can't help with the second part.",
+		Annotations:  annotations,
+		FinishReason: domain.FinishReasonStop,
+	}, nil)
+
+	result, err := useCase.ProcessChat(context.Background(), promptRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "I can't help with the second part.", result.Refusal)
+	assert.Equal(t, annotations, result.Annotations)
+	assert.Nil(t, result.Usage)
+}
+
+func TestChatUseCaseImpl_ProcessChat_ToolCalls(t *testing.T) {
+	mockChatRepo := &MockLLMRepository{}
+	useCase := NewChatUseCase(mockChatRepo)
+
+	promptRequest := domain.PromptRequest{
+		Prompt: "What is the weather in Paris?",
+		Tools:  []domain.Tool{{Name: "get_weather"}},
+	}
+	toolCalls := []domain.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}
+	mockChatRepo.On("Send", promptRequest).Return(&domain.LLMResponse{
+		ToolCalls:    toolCalls,
+		FinishReason: domain.FinishReasonToolCalls,
+	}, nil)
+
+	result, err := useCase.ProcessChat(context.Background(), promptRequest)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Response)
+	assert.Equal(t, toolCalls, result.ToolCalls)
+	assert.Equal(t, domain.FinishReasonToolCalls, result.FinishReason)
+	mockChatRepo.AssertExpectations(t)
+}
+
+// MockToolExecutor is a mock implementation of ToolExecutor
+type MockToolExecutor struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockToolExecutor) Definition() domain.Tool {
+	return domain.Tool{Name: m.name}
+}
+
+func (m *MockToolExecutor) Execute(ctx context.Context, arguments string) (string, error) {
+	args := m.Called(arguments)
+	return args.String(0), args.Error(1)
+}
+
+// mockToolRegistry is a ToolRegistry backed by a map
+type mockToolRegistry map[string]domain.ToolExecutor
+
+func (r mockToolRegistry) Tools() []domain.Tool {
+	var tools []domain.Tool
+	for _, tool := range r {
+		tools = append(tools, tool.Definition())
+	}
+	return tools
+}
+
+func (r mockToolRegistry) Get(name string) (domain.ToolExecutor, bool) {
+	tool, ok := r[name]
+	return tool, ok
+}
+
+func TestChatUseCaseImpl_ProcessChat_Agent(t *testing.T) {
+	toolCall := domain.ToolCall{ID: "call_1", Name: "calculator", Arguments: `{"expression":"2+2"}`}
+	firstRequest := domain.PromptRequest{
+		Prompt: "How much is 2+2?",
+		Tools:  []domain.Tool{{Name: "calculator"}},
+	}
+	followUp := domain.PromptRequest{
+		Messages: []domain.Message{
+			{Role: domain.RoleUser, Content: "How much is 2+2?"},
+			{Role: domain.RoleAssistant, ToolCalls: []domain.ToolCall{toolCall}},
+			{Role: domain.RoleTool, ToolCallID: "call_1", Content: "4"},
+		},
+		Tools: []domain.Tool{{Name: "calculator"}},
+	}
+
+	t.Run("executes registered tools until the final answer", func(t *testing.T) {
+		calculator := &MockToolExecutor{name: "calculator"}
+		calculator.On("Execute", `{"expression":"2+2"}`).Return("4", nil)
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", firstRequest).Return(&domain.LLMResponse{
+			ToolCalls:    []domain.ToolCall{toolCall},
+			FinishReason: domain.FinishReasonToolCalls,
+			Usage:        domain.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		}, nil)
+		mockChatRepo.On("Send", followUp).Return(&domain.LLMResponse{
+			Content:      "2+2 is 4.",
+			FinishReason: domain.FinishReasonStop,
+			Usage:        domain.Usage{InputTokens: 20, OutputTokens: 5, TotalTokens: 25},
+		}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithToolRegistry(mockToolRegistry{"calculator": calculator}))
+
+		result, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "How much is 2+2?"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2+2 is 4.", result.Response)
+		assert.Empty(t, result.ToolCalls)
+		assert.Equal(t, &domain.Usage{InputTokens: 30, OutputTokens: 10, TotalTokens: 40}, result.Usage)
+		mockChatRepo.AssertExpectations(t)
+		calculator.AssertExpectations(t)
+	})
+
+	t.Run("tool failures are sent to the model", func(t *testing.T) {
+		calculator := &MockToolExecutor{name: "calculator"}
+		calculator.On("Execute", `{"expression":"2+2"}`).Return("", errors.New("division by zero"))
+		failedFollowUp := followUp
+		failedFollowUp.Messages = append(append([]domain.Message{}, followUp.Messages[:2]...),
+			domain.Message{Role: domain.RoleTool, ToolCallID: "call_1", Content: "error: division by zero"})
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", firstRequest).Return(&domain.LLMResponse{ToolCalls: []domain.ToolCall{toolCall}}, nil)
+		mockChatRepo.On("Send", failedFollowUp).Return(&domain.LLMResponse{Content: "I couldn't compute it."}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithToolRegistry(mockToolRegistry{"calculator": calculator}))
+
+		result, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "How much is 2+2?"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "I couldn't compute it.", result.Response)
+		mockChatRepo.AssertExpectations(t)
+	})
+
+	t.Run("client tools are returned to the client", func(t *testing.T) {
+		calculator := &MockToolExecutor{name: "calculator"}
+		weatherCall := domain.ToolCall{ID: "call_2", Name: "get_weather", Arguments: `{"city":"Paris"}`}
+		prompt := domain.PromptRequest{Prompt: "Weather in Paris?", Tools: []domain.Tool{{Name: "get_weather"}}}
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", domain.PromptRequest{
+			Prompt: "Weather in Paris?",
+			Tools:  []domain.Tool{{Name: "get_weather"}, {Name: "calculator"}},
+		}).Return(&domain.LLMResponse{ToolCalls: []domain.ToolCall{weatherCall}}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithToolRegistry(mockToolRegistry{"calculator": calculator}))
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.ToolCall{weatherCall}, result.ToolCalls)
+		calculator.AssertNotCalled(t, "Execute", mock.Anything)
+	})
+
+	t.Run("stops after the maximum iterations", func(t *testing.T) {
+		calculator := &MockToolExecutor{name: "calculator"}
+		calculator.On("Execute", mock.Anything).Return("4", nil)
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", mock.Anything).Return(&domain.LLMResponse{ToolCalls: []domain.ToolCall{toolCall}}, nil)
+		useCase := NewChatUseCase(mockChatRepo,
+			WithToolRegistry(mockToolRegistry{"calculator": calculator}),
+			WithAgentBudget(2, time.Minute))
+
+		result, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "How much is 2+2?"})
+
+		assert.ErrorIs(t, err, domain.ErrAgentMaxIterations)
+		assert.Nil(t, result)
+		mockChatRepo.AssertNumberOfCalls(t, "Send", 2)
+		calculator.AssertNumberOfCalls(t, "Execute", 1)
+	})
+}
+
+func TestChatUseCaseImpl_ProcessChat_StructuredOutput(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "Who wrote the first program?", ResponseFormat: personFormat}
+	isRepair := func(answer string) interface{} {
+		return mock.MatchedBy(func(request domain.PromptRequest) bool {
+			return request.Prompt == "" && len(request.Messages) == 3 &&
+				request.Messages[1].Role == domain.RoleAssistant && request.Messages[1].Content == answer &&
+				request.Messages[2].Role == domain.RoleUser && strings.Contains(request.Messages[2].Content, "doesn't match the schema")
+		})
+	}
+
+	t.Run("returns the parsed object", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", prompt).Return(&domain.LLMResponse{Content: `{"name":"Ada Lovelace","age":36}`}, nil)
+		useCase := NewChatUseCase(mockChatRepo)
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"Ada Lovelace","age":36}`, string(result.Parsed))
+		mockChatRepo.AssertExpectations(t)
+	})
+
+	t.Run("repairs an invalid answer", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", prompt).Return(&domain.LLMResponse{Content: `{"name":"Ada Lovelace"}`}, nil)
+		mockChatRepo.On("Send", isRepair(`{"name":"Ada Lovelace"}`)).Return(&domain.LLMResponse{Content: `{"name":"Ada Lovelace","age":36}`}, nil)
+		useCase := NewChatUseCase(mockChatRepo)
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"Ada Lovelace","age":36}`, string(result.Parsed))
+		mockChatRepo.AssertNumberOfCalls(t, "Send", 2)
+	})
+
+	t.Run("fails after the maximum repairs", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", mock.Anything).Return(&domain.LLMResponse{Content: "Ada Lovelace"}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithMaxRepairs(1))
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "Ada Lovelace", validationErr.Output)
+		assert.Nil(t, result)
+		mockChatRepo.AssertNumberOfCalls(t, "Send", 2)
+	})
+
+	t.Run("counts the repairs as retries", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", mock.Anything).Return(&domain.LLMResponse{Content: "Ada Lovelace"}, nil)
+		metrics := &MockMetrics{}
+		metrics.On("Retry", domain.RetryStructuredOutput).Return()
+		useCase := NewChatUseCase(mockChatRepo, WithMaxRepairs(2), WithMetrics(metrics))
+
+		_, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.Error(t, err)
+		metrics.AssertNumberOfCalls(t, "Retry", 2)
+	})
+
+	t.Run("refusals are not repaired", func(t *testing.T) {
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", prompt).Return(&domain.LLMResponse{Refusal: "I can't help with that."}, nil)
+		useCase := NewChatUseCase(mockChatRepo)
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "I can't help with that.", result.Refusal)
+		assert.Nil(t, result.Parsed)
+	})
+}
+
+func TestChatUseCaseImpl_ProcessChat_Images(t *testing.T) {
+	tests := []struct {
+		name   string
+		images []domain.Image
+		err    string
+	}{
+		{name: "neither url nor data", images: []domain.Image{{MimeType: "image/png"}}, err: "an image must have either an url or data"},
+		{name: "both url and data", images: []domain.Image{{URL: "https://example.com/a.png", Data: "iVBORw0KGgo=", MimeType: "image/png"}}, err: "an image must have either an url or data"},
+		{name: "too big", images: []domain.Image{{Data: "aGVsbG8gd29ybGQ=", MimeType: "image/png"}}, err: "image of 11 bytes exceeds the maximum of 10 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockChatRepo := &MockLLMRepository{}
+			useCase := NewChatUseCase(mockChatRepo, WithMaxImageBytes(10))
+
+			_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "What is this?", Images: tt.images})
+
+			var inputErr *domain.InputError
+			assert.ErrorAs(t, err, &inputErr)
+			assert.EqualError(t, err, tt.err)
+			mockChatRepo.AssertNotCalled(t, "Send", mock.Anything)
+		})
+	}
+
+	t.Run("valid image", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "What is this?", Images: []domain.Image{{Data: "aGVsbG8=", MimeType: "image/png"}}}
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", prompt).Return(&domain.LLMResponse{Content: "A greeting."}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithMaxImageBytes(10))
+
+		result, err := useCase.ProcessChat(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "A greeting.", result.Response)
+	})
+}
+
+func TestChatUseCaseImpl_ProcessChat_Tracing(t *testing.T) {
+	original := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(original)
+	})
+	spans := func() map[string]sdktrace.ReadOnlySpan {
+		byName := make(map[string]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			byName[span.Name()] = span
+		}
+		return byName
+	}
+
+	t.Run("tool executions are children of the request", func(t *testing.T) {
+		toolCall := domain.ToolCall{ID: "call_1", Name: "calculator", Arguments: `{"expression":"2+2"}`}
+		calculator := &MockToolExecutor{name: "calculator"}
+		calculator.On("Execute", `{"expression":"2+2"}`).Return("4", nil)
+		mockChatRepo := &MockLLMRepository{}
+		mockChatRepo.On("Send", mock.MatchedBy(func(request domain.PromptRequest) bool {
+			return request.Prompt != ""
+		})).Return(&domain.LLMResponse{ToolCalls: []domain.ToolCall{toolCall}}, nil)
+		mockChatRepo.On("Send", mock.Anything).Return(&domain.LLMResponse{Content: "2+2 is 4."}, nil)
+		useCase := NewChatUseCase(mockChatRepo, WithToolRegistry(mockToolRegistry{"calculator": calculator}))
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "How much is 2+2?"})
+
+		assert.NoError(t, err)
+		ended := spans()
+		require.Contains(t, ended, "ProcessChat")
+		require.Contains(t, ended, "execute_tool calculator")
+		assert.Equal(t, ended["ProcessChat"].SpanContext().SpanID(), ended["execute_tool calculator"].Parent().SpanID())
+	})
+
+	t.Run("failed requests are marked as errors", func(t *testing.T) {
+		recorder.Reset()
+		useCase := NewChatUseCase(&MockLLMRepository{})
+
+		_, err := useCase.ProcessChat(context.Background(), domain.PromptRequest{Prompt: "Hello", Collection: "handbook"})
+
+		assert.Error(t, err)
+		require.Contains(t, spans(), "ProcessChat")
+		assert.Equal(t, codes.Error, spans()["ProcessChat"].Status().Code)
+	})
+}
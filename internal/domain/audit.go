@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditRecord is the audit trail entry of a chat request: who asked what, to which provider and model, and the answer
+type AuditRecord struct {
+	Time            time.Time         `json:"time"`
+	RequestID       string            `json:"request_id,omitempty"`
+	CorrelationID   string            `json:"correlation_id,omitempty"`
+	RoutingKey      string            `json:"routing_key,omitempty"`
+	ClientID        string            `json:"client_id,omitempty"`
+	Caller          string            `json:"caller,omitempty"`
+	Provider        string            `json:"provider"`
+	Model           string            `json:"model,omitempty"`
+	Template        string            `json:"template,omitempty"`
+	TemplateVersion int               `json:"template_version,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	Prompt          string            `json:"prompt,omitempty"`
+	Messages        []AuditMessage    `json:"messages,omitempty"`
+	Response        string            `json:"response,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	LatencyMs       int64             `json:"latency_ms"`
+}
+
+// AuditMessage is a message of the conversation of an audited request, its images are not recorded
+type AuditMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// AuditSink persists the audit records, in the order they are written
+type AuditSink interface {
+	Write(ctx context.Context, record AuditRecord) error
+	Close() error
+}
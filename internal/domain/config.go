@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidConfig is returned when a reloaded configuration is rejected, the previous one keeps serving
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ConfigVersion identifies the configuration serving the requests, the version increases on every applied reload
+type ConfigVersion struct {
+	Version  int       `json:"version"`
+	Checksum string    `json:"checksum"`
+	Source   string    `json:"source,omitempty"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// ConfigUseCase reloads the configuration while serving. AdminToken is the bearer token of the administration routes
+// of the active configuration, empty when they are disabled.
+type ConfigUseCase interface {
+	Version(ctx context.Context) ConfigVersion
+	Reload(ctx context.Context) (ConfigVersion, error)
+	AdminToken(ctx context.Context) (string, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "context"
+
+// File is an uploaded file whose text is used to answer a prompt
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Document is the text extracted from a file, one entry per page.
+// Files without pages, eg: text or CSV, have a single page.
+type Document struct {
+	Name  string
+	Pages []string
+	Paged bool
+}
+
+// Source is a part of a document sent to the model, cited in the answer by its ID, eg: [1].
+// Score is the similarity to the prompt of the parts retrieved from a collection.
+type Source struct {
+	ID    int     `json:"id"`
+	File  string  `json:"file"`
+	Page  int     `json:"page,omitempty"`
+	Score float64 `json:"score,omitempty"`
+}
+
+// DocumentExtractor extracts the text of the uploaded files
+type DocumentExtractor interface {
+	Extract(file File) (*Document, error)
+}
+
+// DocumentUseCase answers prompts about uploaded files
+type DocumentUseCase interface {
+	AskWithFiles(ctx context.Context, prompt PromptRequest, files []File) (*ChatResponse, error)
+}
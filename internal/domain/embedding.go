@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Encoding formats of the embeddings
+const (
+	EncodingFormatFloat  = "float"
+	EncodingFormatBase64 = "base64"
+)
+
+// EmbeddingInput is the text or the list of texts to embed
+type EmbeddingInput []string
+
+// UnmarshalJSON accepts a single string or an array of strings
+func (i *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*i = EmbeddingInput{text}
+		return nil
+	}
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return errors.New("input must be a string or an array of strings")
+	}
+	*i = texts
+	return nil
+}
+
+// EmbeddingRequest represents the embeddings request, shaped as the OpenAI embeddings API
+type EmbeddingRequest struct {
+	Input          EmbeddingInput `json:"input" binding:"required,min=1,max=2048,dive,required"`
+	Model          string         `json:"model,omitempty"`
+	Dimensions     int            `json:"dimensions,omitempty" binding:"omitempty,min=1"`
+	EncodingFormat string         `json:"encoding_format,omitempty" binding:"omitempty,oneof=float base64"`
+	User           string         `json:"user,omitempty"`
+}
+
+// EmbeddingResponse represents the embeddings response, shaped as the OpenAI embeddings API
+type EmbeddingResponse struct {
+	Object string         `json:"object"`
+	Data   []Embedding    `json:"data"`
+	Model  string         `json:"model"`
+	Usage  EmbeddingUsage `json:"usage"`
+}
+
+// Embedding is the vector of the input at Index
+type Embedding struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingUsage represents the tokens consumed by an embeddings call
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// EmbeddingUseCase defines the interface for the embeddings use case
+type EmbeddingUseCase interface {
+	CreateEmbeddings(ctx context.Context, request EmbeddingRequest) (*EmbeddingResponse, error)
+}
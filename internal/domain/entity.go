@@ -1,11 +1,116 @@
 package domain
 
-// PromptRequest represents the chat request
+import "encoding/json"
+
+// Finish reasons normalized across the llm providers
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length"
+	FinishReasonToolCalls     = "tool_calls"
+	FinishReasonContentFilter = "content_filter"
+)
+
+// Roles of the messages of a conversation
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// PromptRequest represents the chat request.
+// Messages carries the previous turns of the conversation, eg: tool results, and Prompt is sent with its Images as the last user message.
+// Collection answers the prompt with the documents of that collection closest to it.
+// Template renders that stored template with Variables, at its current version unless TemplateVersion is set.
+// ClientID identifies the client, it assigns the variants of the experiments to requests without X-Routing-Key.
 type PromptRequest struct {
-	Prompt string `json:"prompt" binding:"required"`
+	Prompt          string          `json:"prompt" binding:"required_without_all=Messages Images Template"`
+	Images          []Image         `json:"images,omitempty" binding:"omitempty,dive"`
+	Messages        []Message       `json:"messages,omitempty" binding:"omitempty,dive"`
+	Tools           []Tool          `json:"tools,omitempty" binding:"omitempty,dive"`
+	ToolChoice      string          `json:"tool_choice,omitempty"`
+	Model           string          `json:"model,omitempty"`
+	ReasoningEffort string          `json:"reasoning_effort,omitempty" binding:"omitempty,oneof=low medium high"`
+	Temperature     *float64        `json:"temperature,omitempty" binding:"omitempty,min=0,max=2"`
+	MaxTokens       int             `json:"max_tokens,omitempty" binding:"omitempty,min=1"`
+	ResponseFormat  *ResponseFormat `json:"response_format,omitempty"`
+	Collection      string          `json:"collection,omitempty"`
+	Template        string          `json:"template,omitempty"`
+	TemplateVersion int             `json:"template_version,omitempty" binding:"omitempty,min=1"`
+	Variables       map[string]any  `json:"variables,omitempty"`
+	ClientID        string          `json:"client_id,omitempty"`
+}
+
+// Conversation returns the messages to send to the llm, with the prompt and its images as the last user message
+func (p PromptRequest) Conversation() []Message {
+	messages := make([]Message, 0, len(p.Messages)+1)
+	messages = append(messages, p.Messages...)
+	if p.Prompt != "" || len(p.Images) > 0 {
+		messages = append(messages, Message{Role: RoleUser, Content: p.Prompt, Images: p.Images})
+	}
+	return messages
+}
+
+// HasImages reports whether any message of the conversation has images
+func (p PromptRequest) HasImages() bool {
+	for _, message := range p.Conversation() {
+		if len(message.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is a single message of a conversation.
+// Assistant messages may carry the tool calls requested by the model and tool messages answer one of them.
+type Message struct {
+	Role       string     `json:"role" binding:"required,oneof=system user assistant tool"`
+	Content    string     `json:"content"`
+	Images     []Image    `json:"images,omitempty" binding:"omitempty,dive"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
 
 // ChatResponse represents the chat response
 type ChatResponse struct {
-	Response string `json:"response"`
+	Response     string                `json:"response"`
+	Parsed       json.RawMessage       `json:"parsed,omitempty"`
+	Reasoning    string                `json:"reasoning,omitempty"`
+	Refusal      string                `json:"refusal,omitempty"`
+	Annotations  []Annotation          `json:"annotations,omitempty"`
+	ToolCalls    []ToolCall            `json:"tool_calls,omitempty"`
+	Model        string                `json:"model,omitempty"`
+	FinishReason string                `json:"finish_reason,omitempty"`
+	Usage        *Usage                `json:"usage,omitempty"`
+	Sources      []Source              `json:"sources,omitempty"`
+	Experiment   *ExperimentAssignment `json:"experiment,omitempty"`
+}
+
+// LLMResponse represents the answer returned by a llm repository.
+// Reasoning and Refusal are never part of Content.
+type LLMResponse struct {
+	Content      string
+	Reasoning    string
+	Refusal      string
+	Annotations  []Annotation
+	ToolCalls    []ToolCall
+	Model        string
+	FinishReason string
+	Usage        Usage
+}
+
+// Usage represents the tokens consumed by a llm call
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// Annotation is a citation attached by the provider to a range of the response
+type Annotation struct {
+	Type       string `json:"type"`
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
 }
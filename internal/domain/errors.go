@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProviderError represents an error body returned by a llm provider API
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+}
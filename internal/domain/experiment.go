@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrExperimentNotFound is returned when an experiment or one of its variants does not exist
+var ErrExperimentNotFound = errors.New("experiment not found")
+
+// Experiments configures the experiments and the prices used to compute the cost of their variants
+type Experiments struct {
+	Prices      map[string]ModelPrice `json:"prices"`
+	Experiments []Experiment          `json:"experiments"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Experiment splits the requests of a template, or every request when Template is empty, across its variants
+type Experiment struct {
+	Name     string    `json:"name"`
+	Template string    `json:"template,omitempty"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant overrides the template version, the model or the parameters of the requests assigned to it.
+// Weight is its share of the traffic relative to the other variants.
+type Variant struct {
+	Name            string   `json:"name"`
+	Weight          int      `json:"weight"`
+	TemplateVersion int      `json:"template_version,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxTokens       int      `json:"max_tokens,omitempty"`
+}
+
+// ExperimentAssignment is the variant of an experiment a request was assigned to
+type ExperimentAssignment struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+// Feedback rates an answer given by a variant, Score goes from 0 (bad) to 1 (good)
+type Feedback struct {
+	Variant string   `json:"variant" binding:"required"`
+	Score   *float64 `json:"score" binding:"required,min=0,max=1"`
+}
+
+// ExperimentStats are the statistics of the variants of an experiment
+type ExperimentStats struct {
+	Name     string         `json:"name"`
+	Template string         `json:"template,omitempty"`
+	Variants []VariantStats `json:"variants"`
+}
+
+// VariantStats are the statistics of the requests assigned to a variant since the start
+type VariantStats struct {
+	Name         string  `json:"name"`
+	Weight       int     `json:"weight"`
+	Requests     int     `json:"requests"`
+	Errors       int     `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	Feedback     int     `json:"feedback"`
+	AvgScore     float64 `json:"avg_score"`
+}
+
+// ExperimentUseCase runs the chat requests through the experiments and reports the statistics of their variants
+type ExperimentUseCase interface {
+	ChatUseCase
+	Stats(ctx context.Context) ([]ExperimentStats, error)
+	Feedback(ctx context.Context, experiment string, feedback Feedback) error
+}
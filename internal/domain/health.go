@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Status of the dependencies and of the readiness
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// HealthChecker checks that a dependency can be used, eg: a provider accepting the API key
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// DependencyStatus is the result of the last check of a dependency
+type DependencyStatus struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Required  bool      `json:"required"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Readiness reports whether the API can serve requests, it is not ready when a required dependency is down
+type Readiness struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// HealthUseCase checks the dependencies of the API
+type HealthUseCase interface {
+	Readiness(ctx context.Context) Readiness
+}
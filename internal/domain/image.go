@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Image is an image attached to a message, either a http(s) URL or base64 encoded data with its MIME type
+type Image struct {
+	URL      string `json:"url,omitempty" binding:"omitempty,http_url"`
+	Data     string `json:"data,omitempty" binding:"omitempty,base64"`
+	MimeType string `json:"mime_type,omitempty" binding:"required_with=Data,omitempty,oneof=image/png image/jpeg image/webp image/gif"`
+}
+
+// Source returns the URL of the image, as a data URL for base64 encoded images
+func (i Image) Source() string {
+	if i.URL != "" {
+		return i.URL
+	}
+	return "data:" + i.MimeType + ";base64," + i.Data
+}
+
+// Validate checks that the image has either an URL or data, and that the data isn't bigger than maxBytes
+func (i Image) Validate(maxBytes int) error {
+	if (i.URL == "") == (i.Data == "") {
+		return &InputError{Message: "an image must have either an url or data"}
+	}
+	if size := base64.StdEncoding.DecodedLen(len(i.Data)) - strings.Count(i.Data, "="); maxBytes > 0 && size > maxBytes {
+		return &InputError{Message: fmt.Sprintf("image of %d bytes exceeds the maximum of %d bytes", size, maxBytes)}
+	}
+	return nil
+}
+
+// InputError is returned when a request can't be sent to the llm as is, eg: images for a text-only model
+type InputError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *InputError) Error() string {
+	return e.Message
+}
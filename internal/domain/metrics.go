@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Reasons of the retries recorded in the metrics
+const (
+	RetryStructuredOutput = "structured_output"
+)
+
+// Caches whose lookups are recorded in the metrics
+const (
+	CacheReadiness = "readiness"
+)
+
+// Metrics records the operational metrics of the API requests and of the llm provider calls
+type Metrics interface {
+	HTTPRequestStarted()
+	HTTPRequestFinished(method, route string, status int, duration time.Duration)
+	LLMRequestStarted(provider string)
+	LLMRequestFinished(provider, model string, duration time.Duration, usage Usage, err error)
+	Retry(reason string)
+	CacheLookup(cache string, hit bool)
+}
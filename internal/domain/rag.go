@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCollectionNotFound is returned when a collection has no documents
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// Embedder turns texts into vectors whose cosine similarity reflects how close their meanings are
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Chunk is a part of a document stored in a collection with its embedding
+type Chunk struct {
+	File   string
+	Page   int
+	Text   string
+	Vector []float32
+}
+
+// ScoredChunk is a chunk found by a search with its cosine similarity to the query
+type ScoredChunk struct {
+	Chunk
+	Score float64
+}
+
+// Collection summarizes a collection of documents
+type Collection struct {
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	Chunks    int    `json:"chunks"`
+}
+
+// VectorStore stores the chunks of the collections and finds the closest ones to a vector.
+// Adding the chunks of a file replaces the ones previously stored for it.
+type VectorStore interface {
+	Add(ctx context.Context, collection string, chunks []Chunk) error
+	Search(ctx context.Context, collection string, vector []float32, k int) ([]ScoredChunk, error)
+	Collections(ctx context.Context) ([]Collection, error)
+	Delete(ctx context.Context, collection string) error
+}
+
+// Retriever finds the chunks of a collection closest to a query
+type Retriever interface {
+	Retrieve(ctx context.Context, collection, query string, k int) ([]ScoredChunk, error)
+}
+
+// CollectionUseCase ingests documents into named collections and retrieves their chunks
+type CollectionUseCase interface {
+	Retriever
+	Ingest(ctx context.Context, collection string, files []File) (*Collection, error)
+	Collections(ctx context.Context) ([]Collection, error)
+	Delete(ctx context.Context, collection string) error
+}
@@ -4,5 +4,10 @@ import "context"
 
 // LLMRepository defines the interface for the llm repository
 type LLMRepository interface {
-	Send(ctx context.Context, prompt PromptRequest) (string, error)
+	Send(ctx context.Context, prompt PromptRequest) (*LLMResponse, error)
+}
+
+// EmbeddingRepository defines the interface for the embeddings repository
+type EmbeddingRepository interface {
+	Embed(ctx context.Context, request EmbeddingRequest) (*EmbeddingResponse, error)
 }
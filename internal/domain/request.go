@@ -0,0 +1,46 @@
+package domain
+
+import "context"
+
+// Headers identifying a request and the conversation it belongs to
+const (
+	RequestIDHeader     = "X-Request-Id"
+	CorrelationIDHeader = "X-Correlation-Id"
+	RoutingKeyHeader    = "X-Routing-Key"
+)
+
+// RequestIDs identifies a request, and the conversation it belongs to, across the services it goes through.
+// Caller is the subject of the verified client certificate of the mutual TLS callers.
+type RequestIDs struct {
+	RequestID     string
+	CorrelationID string
+	RoutingKey    string
+	Caller        string
+}
+
+// requestIDsKey is the context key of the request ids
+type requestIDsKey struct{}
+
+// WithRequestIDs returns a copy of the context carrying the request ids
+func WithRequestIDs(ctx context.Context, ids RequestIDs) context.Context {
+	return context.WithValue(ctx, requestIDsKey{}, ids)
+}
+
+// RequestIDsFrom returns the request ids of the context, empty when the context has none
+func RequestIDsFrom(ctx context.Context) RequestIDs {
+	ids, _ := ctx.Value(requestIDsKey{}).(RequestIDs)
+	return ids
+}
+
+// Headers returns the headers forwarding the request and correlation ids to another service, only the ones that are set.
+// The routing key identifies the user and stays in prompthor.
+func (ids RequestIDs) Headers() map[string]string {
+	headers := make(map[string]string, 2)
+	if ids.RequestID != "" {
+		headers[RequestIDHeader] = ids.RequestID
+	}
+	if ids.CorrelationID != "" {
+		headers[CorrelationIDHeader] = ids.CorrelationID
+	}
+	return headers
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Response format types
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// DefaultResponseFormatName is the schema name sent to the providers when the request doesn't name it
+const DefaultResponseFormatName = "response"
+
+// ResponseFormat asks the model to answer with a JSON object, optionally matching a JSON schema
+type ResponseFormat struct {
+	Type   string          `json:"type" binding:"required,oneof=text json_object json_schema"`
+	Name   string          `json:"name,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty" binding:"required_if=Type json_schema"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// IsJSON reports whether the answer must be a JSON object
+func (f *ResponseFormat) IsJSON() bool {
+	return f != nil && (f.Type == ResponseFormatJSONObject || f.Type == ResponseFormatJSONSchema)
+}
+
+// SchemaName returns the name of the schema, defaulting to DefaultResponseFormatName
+func (f *ResponseFormat) SchemaName() string {
+	if f.Name == "" {
+		return DefaultResponseFormatName
+	}
+	return f.Name
+}
+
+// ValidationError is returned when the model answer doesn't match the requested response format, even after repairs
+type ValidationError struct {
+	Message string
+	Output  string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("structured output validation failed: %s", e.Message)
+}
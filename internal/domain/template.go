@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTemplateNotFound is returned when a template or one of its versions does not exist
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Template is an immutable version of a prompt template.
+// Body is a Go text/template rendered with the declared Variables and sent with Role, system or user.
+type Template struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	Role      string    `json:"role"`
+	Body      string    `json:"body"`
+	Variables []string  `json:"variables"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TemplateRequest represents the request creating a new version of a template
+type TemplateRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Role      string   `json:"role,omitempty" binding:"omitempty,oneof=system user"`
+	Body      string   `json:"body" binding:"required"`
+	Variables []string `json:"variables,omitempty"`
+}
+
+// TemplateInfo summarizes a template with its current and latest versions
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Current int    `json:"current"`
+	Latest  int    `json:"latest"`
+}
+
+// RenderedTemplate is the text of a template version rendered with the variables of a request
+type RenderedTemplate struct {
+	Name    string
+	Version int
+	Role    string
+	Text    string
+}
+
+// TemplateStore stores the versions of the templates and their current alias.
+// Versions are never modified, Get returns the current version when version is 0.
+type TemplateStore interface {
+	Create(ctx context.Context, template Template) (*Template, error)
+	Get(ctx context.Context, name string, version int) (*Template, error)
+	Versions(ctx context.Context, name string) ([]Template, error)
+	List(ctx context.Context) ([]TemplateInfo, error)
+	SetCurrent(ctx context.Context, name string, version int) error
+}
+
+// TemplateRenderer renders a template version with the variables of a request
+type TemplateRenderer interface {
+	Render(ctx context.Context, name string, version int, variables map[string]any) (*RenderedTemplate, error)
+}
+
+// TemplateUseCase manages the prompt templates and renders them
+type TemplateUseCase interface {
+	TemplateRenderer
+	Create(ctx context.Context, request TemplateRequest) (*Template, error)
+	Get(ctx context.Context, name string, version int) (*Template, error)
+	Versions(ctx context.Context, name string) ([]Template, error)
+	List(ctx context.Context) ([]TemplateInfo, error)
+	SetCurrent(ctx context.Context, name string, version int) (*Template, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Tool choices understood by every provider, any other value forces the tool with that name
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ErrAgentMaxIterations is returned when the model keeps calling tools after the allowed number of iterations
+var ErrAgentMaxIterations = errors.New("agent reached the maximum number of iterations without a final answer")
+
+// Tool is a provider-neutral definition of a function the model can call.
+// Parameters is the JSON schema of the function arguments.
+type Tool struct {
+	Name        string          `json:"name" binding:"required"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall is a call to a tool requested by the model, Arguments is a JSON encoded object
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolExecutor is a tool executed by prompthor itself instead of the client
+type ToolExecutor interface {
+	// Definition returns the name, description and parameters schema sent to the model
+	Definition() Tool
+	// Execute runs the tool with the JSON encoded arguments chosen by the model and returns its result
+	Execute(ctx context.Context, arguments string) (string, error)
+}
+
+// ToolRegistry is the catalog of the tools executed by prompthor
+type ToolRegistry interface {
+	Tools() []Tool
+	Get(name string) (ToolExecutor, bool)
+}
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedLayout is the timestamp added to the name of the rotated files, eg: audit-20250102T150405.000000000.jsonl.
+// It is the time of the rotation when the file is full, and the end of the day it covers when the day changes.
+const rotatedLayout = "20060102T150405.000000000"
+
+// FileSink appends the audit records to a JSONL file. The file is rotated every day and when it reaches maxBytes,
+// and the rotated files older than the retention are deleted.
+type FileSink struct {
+	mu        sync.Mutex
+	path      string
+	maxBytes  int64
+	retention time.Duration
+	file      *os.File
+	size      int64
+	day       string
+	now       func() time.Time
+}
+
+// NewFileSink opens the audit file at path, creating it and its directory when they don't exist.
+// A zero maxBytes rotates only every day and a zero retention keeps the rotated files forever.
+func NewFileSink(path string, maxBytes int64, retention time.Duration) (*FileSink, error) {
+	sink := &FileSink{
+		path:      path,
+		maxBytes:  maxBytes,
+		retention: retention,
+		now:       time.Now,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	sink.removeExpired()
+	return sink, nil
+}
+
+// Write appends the record as a line of the file, rotating it first when needed
+func (s *FileSink) Write(ctx context.Context, record domain.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("audit file %s is closed", s.path)
+	}
+	var rotateErr error
+	switch {
+	case s.size > 0 && s.day != day(s.now()):
+		rotateErr = s.rotate(dayEnd(s.day))
+	case s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes:
+		rotateErr = s.rotate(s.now())
+	}
+	if rotateErr != nil {
+		log.Ctx(ctx).Error().Err(rotateErr).Msgf("failed to rotate audit file %s, appending to it", s.path)
+	}
+	written, err := s.file.Write(line)
+	s.size += int64(written)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the audit file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open opens the audit file for appending, the day of an existing file is the day it was last written
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	s.day = day(s.now())
+	if s.size > 0 {
+		s.day = day(info.ModTime())
+	}
+	return nil
+}
+
+// rotate renames the audit file adding covered, the last time it covers, to its name and opens a new one.
+// The renamed file is only closed once the new one is open, so a failed rotation keeps appending to the current file.
+func (s *FileSink) rotate(covered time.Time) error {
+	previous := s.file
+	extension := filepath.Ext(s.path)
+	rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(s.path, extension), covered.UTC().Format(rotatedLayout), extension)
+	if err := os.Rename(s.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		log.Error().Err(err).Msgf("failed to close rotated audit file %s", rotated)
+	}
+	s.removeExpired()
+	return nil
+}
+
+// removeExpired deletes the rotated files whose name times are older than the retention, the files named like the
+// audit file with a rotation timestamp
+func (s *FileSink) removeExpired() {
+	if s.retention <= 0 {
+		return
+	}
+	extension := filepath.Ext(s.path)
+	base := strings.TrimSuffix(s.path, extension) + "-"
+	rotated, err := filepath.Glob(base + "*" + extension)
+	if err != nil {
+		return
+	}
+	for _, path := range rotated {
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(path, base), extension)
+		covered, err := time.Parse(rotatedLayout, timestamp)
+		if err != nil || s.now().Sub(covered) <= s.retention {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Error().Err(err).Msgf("failed to delete expired audit file %s", path)
+			continue
+		}
+		log.Info().Msgf("deleted expired audit file %s", path)
+	}
+}
+
+// day returns the UTC date of the time
+func day(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// dayEnd returns the last instant of the UTC date
+func dayEnd(date string) time.Time {
+	start, _ := time.Parse(time.DateOnly, date)
+	return start.Add(24*time.Hour - time.Nanosecond)
+}
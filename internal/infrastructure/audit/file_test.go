@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readRecords reads the records of an audit file
+func readRecords(t *testing.T, path string) []domain.AuditRecord {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []domain.AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record domain.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestFileSink_Write(t *testing.T) {
+	t.Run("appends a line per record", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+		sink, err := NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{ClientID: "client-1", Prompt: "Hello", Provider: "groq"}))
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{ClientID: "client-2", Prompt: "Hi", Provider: "groq"}))
+		require.NoError(t, sink.Close())
+
+		records := readRecords(t, path)
+		require.Len(t, records, 2)
+		assert.Equal(t, "client-1", records[0].ClientID)
+		assert.Equal(t, "Hi", records[1].Prompt)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("keeps the records of an existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink, err := NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "first"}))
+		require.NoError(t, sink.Close())
+
+		sink, err = NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "second"}))
+		require.NoError(t, sink.Close())
+
+		assert.Len(t, readRecords(t, path), 2)
+	})
+
+	t.Run("fails when closed", func(t *testing.T) {
+		sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, sink.Close())
+
+		assert.Error(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "Hello"}))
+	})
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	t.Run("rotates when the file reaches the maximum size", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "audit.jsonl")
+		sink, err := NewFileSink(path, 100, 0)
+		require.NoError(t, err)
+
+		for _, prompt := range []string{"first", "second", "third"} {
+			require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: prompt, Provider: "openai"}))
+		}
+		require.NoError(t, sink.Close())
+
+		rotated, err := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+		require.NoError(t, err)
+		assert.Len(t, rotated, 2)
+		records := readRecords(t, path)
+		require.Len(t, records, 1)
+		assert.Equal(t, "third", records[0].Prompt)
+	})
+
+	t.Run("rotates when the day changes", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "audit.jsonl")
+		sink, err := NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		now := time.Now()
+		sink.now = func() time.Time { return now }
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "today"}))
+
+		today := now.UTC().Format("20060102")
+		now = now.Add(24 * time.Hour)
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "tomorrow"}))
+		require.NoError(t, sink.Close())
+
+		rotated, err := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+		require.NoError(t, err)
+		require.Len(t, rotated, 1)
+		assert.Equal(t, filepath.Join(dir, "audit-"+today+"T235959.999999999.jsonl"), rotated[0])
+		assert.Equal(t, "today", readRecords(t, rotated[0])[0].Prompt)
+		assert.Equal(t, "tomorrow", readRecords(t, path)[0].Prompt)
+	})
+
+	t.Run("keeps appending when the rotation fails", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "audit.jsonl")
+		sink, err := NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		now := time.Now()
+		sink.now = func() time.Time { return now }
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "today"}))
+		// the rotated name is taken by a directory, so the audit file can't be renamed
+		blocked := filepath.Join(dir, "audit-"+dayEnd(day(now)).Format(rotatedLayout)+".jsonl", "file")
+		now = now.Add(24 * time.Hour)
+		require.NoError(t, os.MkdirAll(blocked, 0o750))
+
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "tomorrow"}))
+		require.NoError(t, sink.Write(context.Background(), domain.AuditRecord{Prompt: "later"}))
+		require.NoError(t, sink.Close())
+
+		records := readRecords(t, path)
+		require.Len(t, records, 3)
+		assert.Equal(t, "later", records[2].Prompt)
+	})
+}
+
+func TestFileSink_Retention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	old := time.Now().Add(-48 * time.Hour)
+	// the files are aged by the time in their names, not by when they were last written
+	expired := filepath.Join(dir, "audit-"+old.UTC().Format(rotatedLayout)+".jsonl")
+	kept := filepath.Join(dir, "audit-"+time.Now().UTC().Add(-time.Hour).Format(rotatedLayout)+".jsonl")
+	unrelated := filepath.Join(dir, "other.jsonl")
+	prefixed := filepath.Join(dir, "audit-export.jsonl")
+	for _, file := range []string{expired, kept, unrelated, prefixed} {
+		require.NoError(t, os.WriteFile(file, []byte("{}\n"), 0o600))
+	}
+	for _, file := range []string{kept, unrelated, prefixed} {
+		require.NoError(t, os.Chtimes(file, old, old))
+	}
+
+	sink, err := NewFileSink(path, 0, 24*time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, kept)
+	assert.FileExists(t, unrelated)
+	assert.FileExists(t, prefixed)
+}
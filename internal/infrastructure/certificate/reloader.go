@@ -0,0 +1,154 @@
+package certificate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkInterval is how often the files are checked for changes, at most once per interval
+const checkInterval = 5 * time.Second
+
+// Reloader serves the certificate of the server and the CA bundle verifying the clients, loading them again when
+// their files change, so rotated certificates are used without a restart
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	// mu guards the checks of the files, the handshakes read the loaded pair without waiting for them
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  map[string]time.Time
+	loaded    atomic.Pointer[loaded]
+}
+
+// loaded is the certificate of the server and the CA bundle of the clients loaded from the files
+type loaded struct {
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// NewReloader loads the certificate and key of the server, and the CA bundle of the clients when caFile is not empty
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: checkInterval,
+	}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	r.checkedAt = time.Now()
+	return r, nil
+}
+
+// Config returns the TLS configuration of the server. When there is a CA bundle the clients are authenticated with
+// clientAuth, eg: tls.RequireAndVerifyClientCert.
+func (r *Reloader) Config(clientAuth tls.ClientAuthType) *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.GetCertificate,
+	}
+	if r.caFile == "" {
+		return config
+	}
+	config.ClientAuth = clientAuth
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := config.Clone()
+		handshake.GetConfigForClient = nil
+		_, handshake.ClientCAs = r.current()
+		return handshake, nil
+	}
+	return config
+}
+
+// GetCertificate returns the current certificate of the server
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certificate, _ := r.current()
+	return certificate, nil
+}
+
+// current returns the certificate and the CA bundle, checking the files when the last check is older than the interval
+// and no other handshake is checking them. A failed reload keeps the previous ones until the files change again, they
+// may be in the middle of a rotation.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	if r.mu.TryLock() {
+		if time.Since(r.checkedAt) >= r.interval {
+			r.check()
+			r.checkedAt = time.Now()
+		}
+		r.mu.Unlock()
+	}
+	current := r.loaded.Load()
+	return current.certificate, current.clientCAs
+}
+
+// check loads the files again when one of them changed
+func (r *Reloader) check() {
+	modTimes, err := r.stat()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check the TLS certificates, keeping the loaded ones")
+		return
+	}
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			if err := r.load(modTimes); err != nil {
+				log.Error().Err(err).Msg("failed to reload the TLS certificates, keeping the loaded ones")
+				r.modTimes = modTimes
+			} else {
+				log.Info().Msg("🔐 TLS certificates reloaded")
+			}
+			return
+		}
+	}
+}
+
+// stat returns the modification time of every file
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// load reads the files, which were modified at modTimes
+func (r *Reloader) load(modTimes map[string]time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s: %w", r.certFile, err)
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		bundle, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle %s: %w", r.caFile, err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("CA bundle %s has no PEM certificates", r.caFile)
+		}
+	}
+	r.loaded.Store(&loaded{certificate: &certificate, clientCAs: clientCAs})
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,261 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issued is a certificate with its key, written to certFile and keyFile
+type issued struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certFile    string
+	keyFile     string
+}
+
+// issue creates a certificate for commonName signed by parent, or self-signed CA when parent is nil
+func issue(t *testing.T, dir, commonName string, parent *issued) issued {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Acme"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv6loopback, net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	result := issued{
+		certificate: certificate,
+		key:         key,
+		certFile:    filepath.Join(dir, commonName+".crt"),
+		keyFile:     filepath.Join(dir, commonName+".key"),
+	}
+	require.NoError(t, os.WriteFile(result.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(result.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return result
+}
+
+// clientFor returns a client trusting ca and presenting the certificate of client, if any, even when the server
+// doesn't accept its issuer
+func clientFor(ca issued, client *issued) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	config := &tls.Config{RootCAs: roots}
+	if client != nil {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &tls.Certificate{Certificate: [][]byte{client.certificate.Raw}, PrivateKey: client.key}, nil
+		}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+}
+
+// startServer serves the subject of the verified client certificate with the TLS config of reloader and returns its url
+func startServer(t *testing.T, reloader *Reloader, clientAuth tls.ClientAuthType) string {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) > 0 {
+			_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.String()))
+		}
+	}))
+	// StartTLS would add its own certificate, which is served before GetCertificate to the clients without SNI
+	server.Listener = tls.NewListener(server.Listener, reloader.Config(clientAuth))
+	server.Start()
+	t.Cleanup(server.Close)
+	return "https://" + server.Listener.Addr().String()
+}
+
+// get returns the body of the GET request
+func get(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body := make([]byte, 256)
+	n, _ := resp.Body.Read(body)
+	return string(body[:n]), nil
+}
+
+func TestNewReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+
+	t.Run("loads the certificate", func(t *testing.T) {
+		reloader, err := NewReloader(server.certFile, server.keyFile, ca.certFile)
+
+		require.NoError(t, err)
+		certificate, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, server.certificate.Raw, certificate.Certificate[0])
+	})
+
+	t.Run("fails with a missing file", func(t *testing.T) {
+		_, err := NewReloader(filepath.Join(dir, "missing.crt"), server.keyFile, "")
+
+		assert.ErrorContains(t, err, "missing.crt")
+	})
+
+	t.Run("fails with a key of another certificate", func(t *testing.T) {
+		_, err := NewReloader(server.certFile, ca.keyFile, "")
+
+		assert.ErrorContains(t, err, "failed to load certificate")
+	})
+
+	t.Run("fails with an invalid CA bundle", func(t *testing.T) {
+		_, err := NewReloader(server.certFile, server.keyFile, server.keyFile)
+
+		assert.ErrorContains(t, err, "has no PEM certificates")
+	})
+}
+
+func TestReloader_Config(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+	client := issue(t, dir, "billing", &ca)
+	stranger := issue(t, t.TempDir(), "billing", nil)
+
+	t.Run("TLS without client authentication", func(t *testing.T) {
+		reloader, err := NewReloader(server.certFile, server.keyFile, "")
+		require.NoError(t, err)
+		url := startServer(t, reloader, tls.RequireAndVerifyClientCert)
+
+		body, err := get(clientFor(ca, nil), url)
+
+		require.NoError(t, err)
+		assert.Empty(t, body)
+	})
+
+	t.Run("mutual TLS requires a certificate of the CA", func(t *testing.T) {
+		reloader, err := NewReloader(server.certFile, server.keyFile, ca.certFile)
+		require.NoError(t, err)
+		url := startServer(t, reloader, tls.RequireAndVerifyClientCert)
+
+		body, err := get(clientFor(ca, &client), url)
+		require.NoError(t, err)
+		assert.Equal(t, "CN=billing,O=Acme", body)
+
+		_, err = get(clientFor(ca, nil), url)
+		assert.Error(t, err)
+
+		_, err = get(clientFor(ca, &stranger), url)
+		assert.Error(t, err)
+	})
+
+	t.Run("optional mutual TLS accepts clients without certificate", func(t *testing.T) {
+		reloader, err := NewReloader(server.certFile, server.keyFile, ca.certFile)
+		require.NoError(t, err)
+		url := startServer(t, reloader, tls.VerifyClientCertIfGiven)
+
+		body, err := get(clientFor(ca, nil), url)
+		require.NoError(t, err)
+		assert.Empty(t, body)
+
+		_, err = get(clientFor(ca, &stranger), url)
+		assert.Error(t, err)
+	})
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+	reloader, err := NewReloader(server.certFile, server.keyFile, ca.certFile)
+	require.NoError(t, err)
+	// Check the files on every handshake
+	reloader.interval = 0
+
+	t.Run("reloads the rotated certificate", func(t *testing.T) {
+		rotated := issue(t, dir, "server", &ca)
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(rotated.certFile, later, later))
+
+		certificate, err := reloader.GetCertificate(nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, rotated.certificate.Raw, certificate.Certificate[0])
+		server = rotated
+	})
+
+	t.Run("keeps the certificate when the rotated one is invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(server.certFile, []byte("half written"), 0600))
+		later := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(server.certFile, later, later))
+
+		certificate, err := reloader.GetCertificate(nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, server.certificate.Raw, certificate.Certificate[0])
+	})
+
+	t.Run("reloads the CA bundle", func(t *testing.T) {
+		newCA := issue(t, dir, "ca", nil)
+		later := time.Now().Add(3 * time.Minute)
+		require.NoError(t, os.Chtimes(newCA.certFile, later, later))
+		rotated := issue(t, dir, "server", &newCA)
+		require.NoError(t, os.Chtimes(rotated.certFile, later, later))
+
+		_, clientCAs := reloader.current()
+
+		subjects := clientCAs.Subjects()
+		require.Len(t, subjects, 1)
+		assert.Equal(t, newCA.certificate.RawSubject, subjects[0])
+	})
+}
+
+func TestReloader_CheckInterval(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+	reloader, err := NewReloader(server.certFile, server.keyFile, "")
+	require.NoError(t, err)
+
+	rotated := issue(t, dir, "server", &ca)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(rotated.certFile, later, later))
+
+	certificate, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, server.certificate.Raw, certificate.Certificate[0], "checked again before the interval")
+
+	reloader.checkedAt = time.Now().Add(-checkInterval)
+	certificate, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.certificate.Raw, certificate.Certificate[0])
+}
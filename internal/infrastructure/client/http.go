@@ -0,0 +1,19 @@
+package client
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"net/http"
+)
+
+// NewHTTPClient creates the HTTP client of the calls to the providers, tracing them and sending the trace context
+// of the request in the traceparent header
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: tracedTransport(),
+	}
+}
+
+// tracedTransport returns the default transport creating a client span for each call
+func tracedTransport() http.RoundTripper {
+	return otelhttp.NewTransport(http.DefaultTransport)
+}
@@ -1,7 +1,14 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -9,6 +16,7 @@ import (
 // OpenAIClient interface for dependency injection
 type OpenAIClient interface {
 	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error)
 }
 
 // OpenAIClientImpl wraps the standard OpenAI client
@@ -16,12 +24,130 @@ type OpenAIClientImpl struct {
 	client *openai.Client
 }
 
-func NewOpenAIClient(apiKey string) OpenAIClient {
+// NewOpenAIClient creates an OpenAI client with the API key, organization, project, base URL and timeout of the configuration.
+// The API key is set by the transport, so a rotated key file is used by the next request.
+func NewOpenAIClient(config config.Config) OpenAIClient {
+	clientConfig := openai.DefaultConfig("")
+	if config.OpenAIBaseUrl != "" {
+		clientConfig.BaseURL = config.OpenAIBaseUrl
+	}
+	clientConfig.OrgID = config.OpenAIOrgID
+
+	var transport http.RoundTripper = &secretTransport{
+		base:   &zeroTemperatureTransport{base: &requestIDTransport{base: tracedTransport()}},
+		apiKey: config.OpenAIKey,
+	}
+	if config.OpenAIProjectID != "" {
+		transport = &headerTransport{
+			base:    transport,
+			headers: map[string]string{"OpenAI-Project": config.OpenAIProjectID},
+		}
+	}
+	clientConfig.HTTPClient = &http.Client{
+		Timeout:   config.OpenAITimeout,
+		Transport: transport,
+	}
 	return &OpenAIClientImpl{
-		client: openai.NewClient(apiKey),
+		client: openai.NewClientWithConfig(clientConfig),
 	}
 }
 
 func (c *OpenAIClientImpl) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
 	return c.client.CreateChatCompletion(ctx, request)
 }
+
+func (c *OpenAIClientImpl) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	return c.client.CreateEmbeddings(ctx, request)
+}
+
+// requestIDTransport forwards the request and correlation ids of the context of every request sent to OpenAI
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := domain.RequestIDsFrom(req.Context()).Headers()
+	if len(headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// secretTransport authenticates every request sent to OpenAI with the current value of the API key
+type secretTransport struct {
+	base   http.RoundTripper
+	apiKey config.Secret
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *secretTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	apiKey, err := t.apiKey.Value()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OPENAI_API_KEY: %w", err)
+	}
+	if apiKey == "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return t.base.RoundTrip(req)
+}
+
+// headerTransport adds fixed headers to every request sent to OpenAI
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// zeroTemperatureKey is the context key marking the requests sent with a zero temperature
+type zeroTemperatureKey struct{}
+
+// WithZeroTemperature returns a context sending the chat completion requests with a zero temperature.
+// The client omits a zero Temperature from the request, so the API would use its default one instead.
+func WithZeroTemperature(ctx context.Context) context.Context {
+	return context.WithValue(ctx, zeroTemperatureKey{}, true)
+}
+
+// zeroTemperatureTransport sets the temperature of the requests with a zero temperature context to 0
+type zeroTemperatureTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *zeroTemperatureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if zero, _ := req.Context().Value(zeroTemperatureKey{}).(bool); !zero || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAI request: %w", err)
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI request: %w", err)
+	}
+	payload["temperature"] = json.RawMessage("0")
+	if body, err = json.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAI request: %w", err)
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return t.base.RoundTrip(req)
+}
@@ -1,14 +1,25 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"prompthor/config"
+	"prompthor/internal/domain"
 	"testing"
+	"time"
 
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOpenAIClientImpl_CreateChatCompletion(t *testing.T) {
 	// Test the actual OpenAI client implementation
-	client := NewOpenAIClient("test-key")
+	client := NewOpenAIClient(config.Config{OpenAIKey: "test-key"})
 	assert.NotNil(t, client)
 
 	// We can't test the actual API call without a real key,
@@ -17,3 +28,96 @@ func TestOpenAIClientImpl_CreateChatCompletion(t *testing.T) {
 	assert.True(t, ok)
 	assert.NotNil(t, clientImpl.client)
 }
+
+func TestNewOpenAIClient_WithConfiguration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "org-test", r.Header.Get("OpenAI-Organization"))
+		assert.Equal(t, "proj_test", r.Header.Get("OpenAI-Project"))
+		assert.Equal(t, "request-1", r.Header.Get("X-Request-Id"))
+		assert.Equal(t, "correlation-1", r.Header.Get("X-Correlation-Id"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-test","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(config.Config{
+		OpenAIKey:       "test-key",
+		OpenAIOrgID:     "org-test",
+		OpenAIProjectID: "proj_test",
+		OpenAIBaseUrl:   server.URL + "/v1",
+		OpenAITimeout:   5 * time.Second,
+	})
+
+	ctx := domain.WithRequestIDs(context.Background(), domain.RequestIDs{RequestID: "request-1", CorrelationID: "correlation-1"})
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi", resp.Choices[0].Message.Content)
+}
+
+func TestNewOpenAIClient_RotatedAPIKey(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-test","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "openai_api_key")
+	require.NoError(t, os.WriteFile(path, []byte("sk-first-key\n"), 0600))
+	client := NewOpenAIClient(config.Config{OpenAIKey: config.FileSecret(path), OpenAIBaseUrl: server.URL})
+	request := openai.ChatCompletionRequest{Model: "gpt-4o"}
+
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("sk-rotated-key\n"), 0600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Bearer sk-first-key", "Bearer sk-rotated-key"}, authorizations)
+}
+
+func TestNewOpenAIClient_ZeroTemperature(t *testing.T) {
+	var temperatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		temperatures = append(temperatures, string(request["temperature"]))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-test","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+	client := NewOpenAIClient(config.Config{OpenAIKey: "test-key", OpenAIBaseUrl: server.URL})
+	request := openai.ChatCompletionRequest{Model: "gpt-4o"}
+
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	_, err = client.CreateChatCompletion(WithZeroTemperature(context.Background()), request)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "0"}, temperatures)
+}
+
+func TestNewOpenAIClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(config.Config{
+		OpenAIKey:     "test-key",
+		OpenAIBaseUrl: server.URL,
+		OpenAITimeout: 20 * time.Millisecond,
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4o"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package document
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/ledongthuc/pdf"
+	"io"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported file kinds
+const (
+	kindPDF  = "pdf"
+	kindText = "text"
+	kindCSV  = "csv"
+)
+
+// extensions maps the supported file extensions to their kind
+var extensions = map[string]string{
+	".pdf":      kindPDF,
+	".txt":      kindText,
+	".text":     kindText,
+	".md":       kindText,
+	".markdown": kindText,
+	".csv":      kindCSV,
+}
+
+// contentTypes maps the supported content types to their kind, used when the file name has no known extension
+var contentTypes = map[string]string{
+	"application/pdf": kindPDF,
+	"text/plain":      kindText,
+	"text/markdown":   kindText,
+	"text/csv":        kindCSV,
+}
+
+// Extractor extracts the text of PDF, text, markdown and CSV files
+type Extractor struct{}
+
+// NewExtractor creates a new document extractor
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+// Extract returns the text of the file, page by page for PDF files.
+// Unsupported or unreadable files are reported as an InputError.
+func (e *Extractor) Extract(file domain.File) (*domain.Document, error) {
+	var (
+		document *domain.Document
+		err      error
+	)
+	switch fileKind(file) {
+	case kindPDF:
+		document, err = extractPDF(file)
+	case kindText:
+		document, err = extractText(file)
+	case kindCSV:
+		document, err = extractCSV(file)
+	default:
+		return nil, &domain.InputError{Message: fmt.Sprintf("file %s is not a PDF, text, markdown or CSV file", file.Name)}
+	}
+	if err != nil {
+		return nil, &domain.InputError{Message: fmt.Sprintf("failed to read file %s: %v", file.Name, err)}
+	}
+	return document, nil
+}
+
+// fileKind returns the kind of the file from its extension or its content type
+func fileKind(file domain.File) string {
+	if kind, ok := extensions[strings.ToLower(filepath.Ext(file.Name))]; ok {
+		return kind
+	}
+	contentType, _, _ := strings.Cut(file.ContentType, ";")
+	return contentTypes[strings.TrimSpace(strings.ToLower(contentType))]
+}
+
+// extractPDF returns the text of every page of a PDF file
+func extractPDF(file domain.File) (*domain.Document, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(file.Data), int64(len(file.Data)))
+	if err != nil {
+		return nil, err
+	}
+	document := &domain.Document{Name: file.Name, Paged: true}
+	for number := 1; number <= reader.NumPage(); number++ {
+		page := reader.Page(number)
+		if page.V.IsNull() {
+			document.Pages = append(document.Pages, "")
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", number, err)
+		}
+		document.Pages = append(document.Pages, strings.TrimSpace(text))
+	}
+	return document, nil
+}
+
+// extractText returns the content of a text or markdown file
+func extractText(file domain.File) (*domain.Document, error) {
+	if !utf8.Valid(file.Data) {
+		return nil, errors.New("content is not UTF-8 text")
+	}
+	return &domain.Document{Name: file.Name, Pages: []string{string(file.Data)}}, nil
+}
+
+// extractCSV returns the rows of a CSV file, one per line, each value prefixed by its column header
+func extractCSV(file domain.File) (*domain.Document, error) {
+	reader := csv.NewReader(bytes.NewReader(file.Data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &domain.Document{Name: file.Name, Pages: []string{""}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]string, 0, len(record))
+		for i, value := range record {
+			if i < len(header) {
+				value = header[i] + ": " + value
+			}
+			fields = append(fields, value)
+		}
+		text.WriteString(strings.Join(fields, "; "))
+		text.WriteString("\n")
+	}
+	return &domain.Document{Name: file.Name, Pages: []string{text.String()}}, nil
+}
@@ -0,0 +1,75 @@
+package document
+
+import (
+	"errors"
+	"os"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractor_Extract(t *testing.T) {
+	extractor := NewExtractor()
+
+	t.Run("pdf pages", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/report.pdf")
+		require.NoError(t, err)
+
+		document, err := extractor.Extract(domain.File{Name: "report.pdf", Data: data})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Document{
+			Name:  "report.pdf",
+			Pages: []string{"Prompthor quarterly report", "Revenue grew 12 percent"},
+			Paged: true,
+		}, document)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		document, err := extractor.Extract(domain.File{Name: "notes.MD", Data: []byte("# Notes\n\nShip on friday.")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"# Notes\n\nShip on friday."}, document.Pages)
+		assert.False(t, document.Paged)
+	})
+
+	t.Run("text detected by content type", func(t *testing.T) {
+		document, err := extractor.Extract(domain.File{Name: "notes", ContentType: "text/plain; charset=utf-8", Data: []byte("hello")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hello"}, document.Pages)
+	})
+
+	t.Run("csv rows with their headers", func(t *testing.T) {
+		document, err := extractor.Extract(domain.File{Name: "sales.csv", Data: []byte("region,total\nnorth,10\nsouth,20,late\n")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"region: north; total: 10\nregion: south; total: 20; late\n"}, document.Pages)
+	})
+
+	t.Run("unsupported file", func(t *testing.T) {
+		document, err := extractor.Extract(domain.File{Name: "photo.png", ContentType: "image/png", Data: []byte{0x89}})
+
+		assert.Nil(t, document)
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+		assert.Equal(t, "file photo.png is not a PDF, text, markdown or CSV file", err.Error())
+	})
+
+	t.Run("invalid pdf", func(t *testing.T) {
+		document, err := extractor.Extract(domain.File{Name: "broken.pdf", Data: []byte("not a pdf")})
+
+		assert.Nil(t, document)
+		var inputErr *domain.InputError
+		assert.True(t, errors.As(err, &inputErr))
+		assert.Contains(t, err.Error(), "failed to read file broken.pdf")
+	})
+
+	t.Run("binary text file", func(t *testing.T) {
+		_, err := extractor.Extract(domain.File{Name: "notes.txt", Data: []byte{0xff, 0xfe}})
+
+		assert.EqualError(t, err, "failed to read file notes.txt: content is not UTF-8 text")
+	})
+}
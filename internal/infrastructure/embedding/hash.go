@@ -0,0 +1,61 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// defaultHashDimensions is the size of the vectors of the hash embedder
+const defaultHashDimensions = 512
+
+// HashEmbedder is a local stand-in for an embeddings API. It hashes the words and word pairs of a text into
+// a fixed size vector, so texts sharing words are similar. It runs in-process but doesn't capture meaning.
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder creates a hash embedder of the given dimensions, 512 when zero
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = defaultHashDimensions
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Embed returns a vector per text
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, e.embed(text))
+	}
+	return vectors, nil
+}
+
+// embed hashes the words and the pairs of consecutive words of the text, the sign of a feature is a bit of its hash
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, word := range words {
+		e.add(vector, word)
+		if i > 0 {
+			e.add(vector, words[i-1]+" "+word)
+		}
+	}
+	return vector
+}
+
+// add adds a feature to the vector
+func (e *HashEmbedder) add(vector []float32, feature string) {
+	hash := fnv.New64a()
+	hash.Write([]byte(feature))
+	sum := hash.Sum64()
+	if sum>>63 == 1 {
+		vector[sum%uint64(e.dimensions)]--
+	} else {
+		vector[sum%uint64(e.dimensions)]++
+	}
+}
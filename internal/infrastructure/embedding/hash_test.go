@@ -0,0 +1,48 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cosine returns the cosine similarity of two vectors
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+func TestHashEmbedder_Embed(t *testing.T) {
+	embedder := NewHashEmbedder(0)
+
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"When is the product launch?",
+		"The product launch is on friday.",
+		"Quarterly revenue grew 12 percent.",
+		"",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+	for _, vector := range vectors {
+		assert.Len(t, vector, defaultHashDimensions)
+	}
+	assert.Greater(t, cosine(vectors[0], vectors[1]), cosine(vectors[0], vectors[2]))
+	assert.Equal(t, make([]float32, defaultHashDimensions), vectors[3])
+}
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	first, _ := NewHashEmbedder(64).Embed(context.Background(), []string{"Hello, World"})
+	second, _ := NewHashEmbedder(64).Embed(context.Background(), []string{"hello world"})
+
+	assert.Len(t, first[0], 64)
+	assert.Equal(t, first, second)
+}
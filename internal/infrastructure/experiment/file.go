@@ -0,0 +1,24 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"prompthor/internal/domain"
+)
+
+// LoadFile reads the experiments and the model prices of a JSON file, rejecting unknown fields to catch typos
+func LoadFile(path string) (domain.Experiments, error) {
+	var experiments domain.Experiments
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return experiments, fmt.Errorf("failed to read experiments file %s: %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&experiments); err != nil {
+		return experiments, fmt.Errorf("failed to parse experiments file %s: %w", path, err)
+	}
+	return experiments, nil
+}
@@ -0,0 +1,44 @@
+package experiment
+
+import (
+	"path/filepath"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFile(t *testing.T) {
+	t.Run("experiments and prices", func(t *testing.T) {
+		experiments, err := LoadFile(filepath.Join("testdata", "experiments.json"))
+
+		temperature := 0.2
+		assert.NoError(t, err)
+		assert.Equal(t, domain.Experiments{
+			Prices: map[string]domain.ModelPrice{
+				"gpt-4o":      {Input: 2.5, Output: 10},
+				"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+			},
+			Experiments: []domain.Experiment{{
+				Name:     "support-prompt",
+				Template: "support",
+				Variants: []domain.Variant{
+					{Name: "control", Weight: 80},
+					{Name: "v3-gpt-4o", Weight: 20, TemplateVersion: 3, Model: "gpt-4o", Temperature: &temperature, MaxTokens: 400},
+				},
+			}},
+		}, experiments)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := LoadFile(filepath.Join("testdata", "unknown_field.json"))
+
+		assert.ErrorContains(t, err, `unknown field "variant"`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadFile(filepath.Join("testdata", "missing.json"))
+
+		assert.ErrorContains(t, err, "failed to read experiments file")
+	})
+}
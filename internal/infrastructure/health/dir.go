@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DirChecker checks that a storage directory can be written
+type DirChecker struct {
+	name string
+	dir  string
+}
+
+// NewDirChecker creates a checker of the directory, which may not exist yet when its parent can create it
+func NewDirChecker(name, dir string) *DirChecker {
+	return &DirChecker{
+		name: name,
+		dir:  dir,
+	}
+}
+
+// Name returns the name of the dependency
+func (c *DirChecker) Name() string {
+	return c.name
+}
+
+// Check writes and deletes a file in the directory, or in its closest existing parent
+func (c *DirChecker) Check(ctx context.Context) error {
+	dir := filepath.Clean(c.dir)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) || filepath.Dir(dir) == dir {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	file, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirChecker_Check(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "store.json")
+	assert.NoError(t, os.WriteFile(file, []byte("{}"), 0600))
+
+	t.Run("writable directory", func(t *testing.T) {
+		assert.NoError(t, NewDirChecker("templates", dir).Check(context.Background()))
+		entries, _ := os.ReadDir(dir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("directory created on the first write", func(t *testing.T) {
+		assert.NoError(t, NewDirChecker("rag_store", filepath.Join(dir, "rag", "collections")).Check(context.Background()))
+	})
+
+	t.Run("not a directory", func(t *testing.T) {
+		err := NewDirChecker("templates", file).Check(context.Background())
+
+		assert.EqualError(t, err, file+" is not a directory")
+	})
+
+	t.Run("read only directory", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root writes in read only directories")
+		}
+		readOnly := filepath.Join(dir, "read-only")
+		assert.NoError(t, os.Mkdir(readOnly, 0500))
+
+		assert.Error(t, NewDirChecker("audit", readOnly).Check(context.Background()))
+	})
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"prompthor/config"
+	"regexp"
+	"strings"
+)
+
+// apiVersion matches the version segment of the path of the provider APIs, eg: v1
+var apiVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// HTTPChecker checks a dependency with a GET request
+type HTTPChecker struct {
+	name      string
+	url       string
+	token     config.Secret
+	client    *http.Client
+	reachOnly bool
+}
+
+// NewAuthChecker creates a checker of a provider, the GET request with the token must succeed, eg: listing its models
+func NewAuthChecker(name, url string, token config.Secret, client *http.Client) *HTTPChecker {
+	return &HTTPChecker{
+		name:   name,
+		url:    url,
+		token:  token,
+		client: client,
+	}
+}
+
+// NewReachabilityChecker creates a checker of a service that only has to answer, any status but a server error will do
+func NewReachabilityChecker(name, url string, client *http.Client) *HTTPChecker {
+	return &HTTPChecker{
+		name:      name,
+		url:       url,
+		client:    client,
+		reachOnly: true,
+	}
+}
+
+// Name returns the name of the dependency
+func (c *HTTPChecker) Name() string {
+	return c.name
+}
+
+// Check sends the GET request and checks its status
+func (c *HTTPChecker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	token, err := c.token.Value()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	switch {
+	case c.reachOnly && resp.StatusCode < http.StatusInternalServerError:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("credentials rejected with status %d", resp.StatusCode)
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ModelsURL returns the url listing the models of the API of the endpoint, in the same version of the API,
+// eg: https://api.mistral.ai/v1/models for https://api.mistral.ai/v1/chat/completions
+func ModelsURL(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %s: %w", endpoint, err)
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i, segment := range segments {
+		if apiVersion.MatchString(segment) {
+			parsed.Path = "/" + strings.Join(append(segments[:i+1:i+1], "models"), "/")
+			parsed.RawQuery = ""
+			return parsed.String(), nil
+		}
+	}
+	return "", fmt.Errorf("url %s has no API version", endpoint)
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPChecker_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		reachOnly bool
+		expected  string
+	}{
+		{name: "provider accepts the key", status: http.StatusOK},
+		{name: "provider rejects the key", status: http.StatusUnauthorized, expected: "credentials rejected with status 401"},
+		{name: "provider forbids the key", status: http.StatusForbidden, expected: "credentials rejected with status 403"},
+		{name: "provider fails", status: http.StatusBadGateway, expected: "unexpected status 502"},
+		{name: "service answers", status: http.StatusNotFound, reachOnly: true},
+		{name: "service fails", status: http.StatusServiceUnavailable, reachOnly: true, expected: "unexpected status 503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization, method string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				method = r.Method
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			checker := NewAuthChecker("openai", server.URL+"/v1/models", "test-key", server.Client())
+			if tt.reachOnly {
+				checker = NewReachabilityChecker("gateway", server.URL, server.Client())
+			}
+			err := checker.Check(context.Background())
+
+			if tt.expected != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expected, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, http.MethodGet, method)
+			if tt.reachOnly {
+				assert.Empty(t, authorization)
+			} else {
+				assert.Equal(t, "Bearer test-key", authorization)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		err := NewReachabilityChecker("gateway", server.URL, http.DefaultClient).Check(context.Background())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPChecker_Name(t *testing.T) {
+	assert.Equal(t, "openai", NewAuthChecker("openai", "https://api.openai.com/v1/models", "", http.DefaultClient).Name())
+}
+
+func TestModelsURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		expected string
+		err      bool
+	}{
+		{endpoint: "https://api.mistral.ai/v1/chat/completions", expected: "https://api.mistral.ai/v1/models"},
+		{endpoint: "https://api.groq.com/openai/v1/responses", expected: "https://api.groq.com/openai/v1/models"},
+		{endpoint: "https://llm.example.com/api/v2/chat?beta=true", expected: "https://llm.example.com/api/v2/models"},
+		{endpoint: "https://api.openai.com/v1", expected: "https://api.openai.com/v1/models"},
+		{endpoint: "http://localhost:8080/chat", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			modelsURL, err := ModelsURL(tt.endpoint)
+
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, modelsURL)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+	"os/exec"
+	"prompthor/internal/domain"
+	"time"
+)
+
+// connectTimeout is the time allowed to connect to a server and list its tools
+const connectTimeout = 30 * time.Second
+
+// clientVersion is the version reported to the MCP servers
+const clientVersion = "1.0.0"
+
+// Client keeps a session with each configured MCP server and exposes their tools
+type Client struct {
+	sessions []*mcp.ClientSession
+	tools    []domain.ToolExecutor
+}
+
+// Connect connects to the MCP servers and lists their tools.
+// Servers that can't be reached are logged and skipped so they don't prevent the startup.
+func Connect(ctx context.Context, servers []ServerConfig) *Client {
+	client := &Client{}
+	for _, server := range servers {
+		session, tools, err := connectServer(ctx, server)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to connect to MCP server %s", server.Name)
+			continue
+		}
+		log.Info().Msgf("🔌 Connected to MCP server %s with %d tools", server.Name, len(tools))
+		client.sessions = append(client.sessions, session)
+		client.tools = append(client.tools, tools...)
+	}
+	return client
+}
+
+// Tools returns the tools of every connected server, ready to be registered in the tool registry
+func (c *Client) Tools() []domain.ToolExecutor {
+	return c.tools
+}
+
+// Close closes the sessions, stopping the stdio servers
+func (c *Client) Close() {
+	for _, session := range c.sessions {
+		if err := session.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close MCP session")
+		}
+	}
+}
+
+// connectServer opens a session with the server and lists its tools, skipping the ones the providers can't be offered
+func connectServer(ctx context.Context, server ServerConfig) (*mcp.ClientSession, []domain.ToolExecutor, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "prompthor", Version: clientVersion}, nil)
+	session, err := client.Connect(ctx, transport(server), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	var tools []domain.ToolExecutor
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			session.Close()
+			return nil, nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		definition, err := toolDefinition(server.Name, tool)
+		if err != nil {
+			session.Close()
+			return nil, nil, err
+		}
+		if !toolNamePattern.MatchString(definition.Name) {
+			log.Warn().Msgf("skipping MCP tool %s of server %s, %s isn't a valid tool name: up to 64 letters, digits, _ and -",
+				tool.Name, server.Name, definition.Name)
+			continue
+		}
+		tools = append(tools, &Tool{
+			definition: definition,
+			name:       tool.Name,
+			session:    session,
+		})
+	}
+	return session, tools, nil
+}
+
+// transport returns the streamable HTTP or stdio transport of the server
+func transport(server ServerConfig) mcp.Transport {
+	if server.URL != "" {
+		return &mcp.StreamableClientTransport{Endpoint: server.URL}
+	}
+	return &mcp.CommandTransport{Command: exec.Command(server.Command[0], server.Command[1:]...)}
+}
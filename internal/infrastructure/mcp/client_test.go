@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnect_Stdio(t *testing.T) {
+	t.Setenv(stubServerEnv, "1")
+	client := Connect(context.Background(), []ServerConfig{{Name: "weather", Command: []string{os.Args[0]}}})
+	defer client.Close()
+
+	assertWeatherTools(t, client)
+}
+
+func TestConnect_StreamableHTTP(t *testing.T) {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return newStubServer() }, nil)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := Connect(context.Background(), []ServerConfig{{Name: "weather", URL: server.URL}})
+	defer client.Close()
+
+	assertWeatherTools(t, client)
+}
+
+func TestConnect_SkipsUnreachableServers(t *testing.T) {
+	client := Connect(context.Background(), []ServerConfig{{Name: "missing", Command: []string{"/nonexistent/mcp-server"}}})
+	defer client.Close()
+
+	assert.Empty(t, client.Tools())
+}
+
+// assertWeatherTools checks the stub tools are listed and their calls routed to the stub server
+func assertWeatherTools(t *testing.T, client *Client) {
+	tools := client.Tools()
+	require.Len(t, tools, 1)
+	definition := tools[0].Definition()
+	assert.Equal(t, "weather__get_weather", definition.Name)
+	assert.Equal(t, "Gets the current weather of a city", definition.Description)
+	assert.Contains(t, string(definition.Parameters), `"city"`)
+
+	result, err := tools[0].Execute(context.Background(), `{"city":"Paris"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"temperature":18}`, result)
+
+	_, err = tools[0].Execute(context.Background(), `{"city":"Atlantis"}`)
+	assert.EqualError(t, err, "unknown city Atlantis")
+
+	_, err = tools[0].Execute(context.Background(), `not json`)
+	assert.EqualError(t, err, "invalid arguments for MCP tool weather__get_weather")
+}
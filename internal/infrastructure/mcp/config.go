@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// serverNamePattern restricts server names to characters allowed in the tool names sent to the providers
+var serverNamePattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// ServerConfig is a MCP server to connect to, either a streamable HTTP endpoint or a command speaking MCP over stdio
+type ServerConfig struct {
+	Name    string
+	URL     string
+	Command []string
+}
+
+// ParseServers parses the MCP servers configuration, each one as name=target.
+// Targets starting with http:// or https:// are streamable HTTP endpoints, any other target is a command line.
+func ParseServers(specs []string) ([]ServerConfig, error) {
+	servers := make([]ServerConfig, 0, len(specs))
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		name, target, found := strings.Cut(spec, "=")
+		name, target = strings.TrimSpace(name), strings.TrimSpace(target)
+		if !found || target == "" {
+			return nil, fmt.Errorf("invalid MCP server %q, expected name=target", spec)
+		}
+		if !serverNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid MCP server name %q, only letters, digits and - are allowed", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicated MCP server name %q", name)
+		}
+		names[name] = true
+
+		server := ServerConfig{Name: name}
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			server.URL = target
+		} else {
+			server.Command = strings.Fields(target)
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServers(t *testing.T) {
+	t.Run("http and stdio servers", func(t *testing.T) {
+		servers, err := ParseServers([]string{
+			"github=https://mcp.example.com/github",
+			"files = /usr/local/bin/files-mcp --root /data",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []ServerConfig{
+			{Name: "github", URL: "https://mcp.example.com/github"},
+			{Name: "files", Command: []string{"/usr/local/bin/files-mcp", "--root", "/data"}},
+		}, servers)
+	})
+
+	invalid := map[string][]string{
+		"missing target":  {"github"},
+		"empty target":    {"github="},
+		"invalid name":    {"git_hub=https://mcp.example.com"},
+		"duplicated name": {"github=https://a.example.com", "github=https://b.example.com"},
+	}
+	for name, specs := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseServers(specs)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// stubServerEnv makes the test binary run the stub MCP server over stdio instead of the tests
+const stubServerEnv = "PROMPTHOR_MCP_STUB_SERVER"
+
+// WeatherArguments are the arguments of the stub get_weather tool
+type WeatherArguments struct {
+	City string `json:"city" jsonschema:"the city name"`
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubServerEnv) != "" {
+		if err := newStubServer().Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// newStubServer creates a MCP server with a get_weather tool that fails for unknown cities, and tools named in a way
+// the providers reject
+func newStubServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: "Gets the current weather of a city"},
+		func(ctx context.Context, request *mcp.CallToolRequest, args WeatherArguments) (*mcp.CallToolResult, any, error) {
+			if args.City != "Paris" {
+				return nil, nil, errors.New("unknown city " + args.City)
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"temperature":18}`}}}, nil, nil
+		})
+	// The providers reject these names, they are skipped
+	mcp.AddTool(server, &mcp.Tool{Name: "weather.alerts", Description: "Lists the weather alerts of a city"},
+		func(ctx context.Context, request *mcp.CallToolRequest, args WeatherArguments) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	mcp.AddTool(server, &mcp.Tool{Name: strings.Repeat("forecast_", 7), Description: "Gets the forecast of a city"},
+		func(ctx context.Context, request *mcp.CallToolRequest, args WeatherArguments) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	return server
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"prompthor/internal/domain"
+	"regexp"
+	"strings"
+)
+
+// toolNameSeparator separates the server name from the tool name, tools of different servers may share a name
+const toolNameSeparator = "__"
+
+// toolNamePattern is the tool name accepted by the providers, a tool named otherwise fails every request offering it
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Tool is a tool of a MCP server, its calls are routed to the server session it was listed from
+type Tool struct {
+	definition domain.Tool
+	name       string
+	session    *mcp.ClientSession
+}
+
+// Definition implements domain.ToolExecutor
+func (t *Tool) Definition() domain.Tool {
+	return t.definition
+}
+
+// Execute implements domain.ToolExecutor, calling the tool on its server and returning its text content
+func (t *Tool) Execute(ctx context.Context, arguments string) (string, error) {
+	var args json.RawMessage
+	if arguments != "" {
+		args = json.RawMessage(arguments)
+		if !json.Valid(args) {
+			return "", fmt.Errorf("invalid arguments for MCP tool %s", t.definition.Name)
+		}
+	}
+	result, err := t.session.CallTool(ctx, &mcp.CallToolParams{Name: t.name, Arguments: args})
+	if err != nil {
+		return "", fmt.Errorf("MCP tool %s failed: %w", t.definition.Name, err)
+	}
+	text := resultText(result)
+	if result.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+// toolDefinition translates a MCP tool into a tool definition, prefixing its name with the server name
+func toolDefinition(server string, tool *mcp.Tool) (domain.Tool, error) {
+	definition := domain.Tool{
+		Name:        server + toolNameSeparator + tool.Name,
+		Description: tool.Description,
+	}
+	if tool.InputSchema != nil {
+		schema, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return domain.Tool{}, fmt.Errorf("invalid input schema for MCP tool %s: %w", tool.Name, err)
+		}
+		definition.Parameters = schema
+	}
+	return definition, nil
+}
+
+// resultText joins the text contents of a tool result, falling back to the structured content
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	if len(parts) == 0 && result.StructuredContent != nil {
+		if structured, err := json.Marshal(result.StructuredContent); err == nil {
+			return string(structured)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
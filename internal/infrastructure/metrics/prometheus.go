@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"prompthor/internal/domain"
+	"strconv"
+	"time"
+)
+
+// namespace prefixes the names of the metrics
+const namespace = "prompthor"
+
+// llmBuckets are the latency buckets of the llm calls, in seconds, longer than the ones of the API requests
+var llmBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// Prometheus implements Metrics with Prometheus collectors kept in their own registry
+type Prometheus struct {
+	registry     *prometheus.Registry
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+	llmRequests  *prometheus.CounterVec
+	llmDuration  *prometheus.HistogramVec
+	llmInFlight  *prometheus.GaugeVec
+	llmTokens    *prometheus.CounterVec
+	retries      *prometheus.CounterVec
+	cacheLookups *prometheus.CounterVec
+}
+
+// NewPrometheus creates the collectors of the API metrics, with the Go runtime and process metrics
+func NewPrometheus() *Prometheus {
+	m := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "API requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the API requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "API requests being processed.",
+		}),
+		llmRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_requests_total",
+			Help:      "Calls to the llm providers by provider, model and outcome, success or error.",
+		}, []string{"provider", "model", "outcome"}),
+		llmDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_request_duration_seconds",
+			Help:      "Latency of the calls to the llm providers by provider and model.",
+			Buckets:   llmBuckets,
+		}, []string{"provider", "model"}),
+		llmInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "llm_requests_in_flight",
+			Help:      "Calls to the llm providers waiting for an answer.",
+		}, []string{"provider"}),
+		llmTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_tokens_total",
+			Help:      "Tokens used by provider, model and type, input or output.",
+		}, []string{"provider", "model", "type"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Requests sent again to the model by reason.",
+		}, []string{"reason"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Lookups in the caches by cache and result, hit or miss.",
+		}, []string{"cache", "result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.httpInFlight,
+		m.llmRequests, m.llmDuration, m.llmInFlight, m.llmTokens,
+		m.retries, m.cacheLookups,
+	)
+	return m
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format
+func (m *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// HTTPRequestStarted counts an API request in flight
+func (m *Prometheus) HTTPRequestStarted() {
+	m.httpInFlight.Inc()
+}
+
+// HTTPRequestFinished records an API request, route is the route template, eg: /api/v1/collections/:name
+func (m *Prometheus) HTTPRequestFinished(method, route string, status int, duration time.Duration) {
+	m.httpInFlight.Dec()
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// LLMRequestStarted counts a call to the provider in flight
+func (m *Prometheus) LLMRequestStarted(provider string) {
+	m.llmInFlight.WithLabelValues(provider).Inc()
+}
+
+// LLMRequestFinished records a call to the provider with the tokens it used
+func (m *Prometheus) LLMRequestFinished(provider, model string, duration time.Duration, usage domain.Usage, err error) {
+	m.llmInFlight.WithLabelValues(provider).Dec()
+	m.llmDuration.WithLabelValues(provider, model).Observe(duration.Seconds())
+	if err != nil {
+		m.llmRequests.WithLabelValues(provider, model, "error").Inc()
+		return
+	}
+	m.llmRequests.WithLabelValues(provider, model, "success").Inc()
+	m.llmTokens.WithLabelValues(provider, model, "input").Add(float64(usage.InputTokens))
+	m.llmTokens.WithLabelValues(provider, model, "output").Add(float64(usage.OutputTokens))
+}
+
+// Retry counts a request sent again to the model
+func (m *Prometheus) Retry(reason string) {
+	m.retries.WithLabelValues(reason).Inc()
+}
+
+// CacheLookup counts a lookup in the cache, a hit or a miss
+func (m *Prometheus) CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus_HTTPRequests(t *testing.T) {
+	m := NewPrometheus()
+
+	m.HTTPRequestStarted()
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpInFlight))
+
+	m.HTTPRequestFinished(http.MethodPost, "/api/v1/chat/ask", http.StatusOK, 120*time.Millisecond)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.httpInFlight))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("POST", "/api/v1/chat/ask", "200")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestPrometheus_LLMRequests(t *testing.T) {
+	t.Run("success records the tokens", func(t *testing.T) {
+		m := NewPrometheus()
+
+		m.LLMRequestStarted("openai")
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmInFlight.WithLabelValues("openai")))
+		m.LLMRequestFinished("openai", "gpt-4o-mini", time.Second, domain.Usage{InputTokens: 10, OutputTokens: 4}, nil)
+
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.llmInFlight.WithLabelValues("openai")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("openai", "gpt-4o-mini", "success")))
+		assert.Equal(t, 10.0, testutil.ToFloat64(m.llmTokens.WithLabelValues("openai", "gpt-4o-mini", "input")))
+		assert.Equal(t, 4.0, testutil.ToFloat64(m.llmTokens.WithLabelValues("openai", "gpt-4o-mini", "output")))
+	})
+
+	t.Run("error records no tokens", func(t *testing.T) {
+		m := NewPrometheus()
+
+		m.LLMRequestStarted("groq")
+		m.LLMRequestFinished("groq", "default", time.Second, domain.Usage{}, errors.New("timeout"))
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.llmRequests.WithLabelValues("groq", "default", "error")))
+		assert.Equal(t, 0, testutil.CollectAndCount(m.llmTokens))
+	})
+}
+
+func TestPrometheus_Handler(t *testing.T) {
+	m := NewPrometheus()
+	m.Retry(domain.RetryStructuredOutput)
+	m.CacheLookup(domain.CacheReadiness, true)
+	m.CacheLookup(domain.CacheReadiness, false)
+	m.CacheLookup(domain.CacheReadiness, true)
+	recorder := httptest.NewRecorder()
+
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `prompthor_retries_total{reason="structured_output"} 1`)
+	assert.Contains(t, recorder.Body.String(), `prompthor_cache_lookups_total{cache="readiness",result="hit"} 2`)
+	assert.Contains(t, recorder.Body.String(), `prompthor_cache_lookups_total{cache="readiness",result="miss"} 1`)
+	assert.Contains(t, recorder.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"strings"
+)
+
+// CohereRequest is the body sent to the Cohere v2 chat API
+type CohereRequest struct {
+	Model          string                `json:"model"`
+	Messages       []CohereMessage       `json:"messages"`
+	Tools          []FunctionTool        `json:"tools,omitempty"`
+	ToolChoice     string                `json:"tool_choice,omitempty"`
+	ResponseFormat *CohereResponseFormat `json:"response_format,omitempty"`
+	SafetyMode     string                `json:"safety_mode,omitempty"`
+	Temperature    *float64              `json:"temperature,omitempty"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+}
+
+// CohereResponseFormat forces a JSON answer, Cohere has no json_schema type but takes the schema in json_object
+type CohereResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+}
+
+// CohereMessage is a single message of a Cohere conversation.
+// Content is the text of the message, or its content parts when it has images.
+type CohereMessage struct {
+	Role       string             `json:"role"`
+	Content    interface{}        `json:"content,omitempty"`
+	ToolCalls  []FunctionToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string             `json:"tool_call_id,omitempty"`
+}
+
+// CohereResponse is the response from the Cohere v2 chat API
+type CohereResponse struct {
+	ID           string                `json:"id"`
+	FinishReason string                `json:"finish_reason"`
+	Message      CohereResponseMessage `json:"message"`
+	Usage        CohereUsage           `json:"usage"`
+}
+
+// CohereResponseMessage is the assistant message of the Cohere response
+type CohereResponseMessage struct {
+	Role      string             `json:"role"`
+	Content   []CohereContent    `json:"content"`
+	ToolPlan  string             `json:"tool_plan,omitempty"`
+	ToolCalls []FunctionToolCall `json:"tool_calls,omitempty"`
+}
+
+// CohereContent is a content part of the Cohere assistant message
+type CohereContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CohereUsage is the usage reported by Cohere, both billed and actually consumed tokens
+type CohereUsage struct {
+	BilledUnits CohereTokens `json:"billed_units"`
+	Tokens      CohereTokens `json:"tokens"`
+}
+
+// CohereTokens is a count of input and output tokens
+type CohereTokens struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// CohereResponseError is the error body returned by the Cohere API
+type CohereResponseError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// CohereRepository implements LLMRepository using Cohere API
+type CohereRepository struct {
+	apiKey       config.Secret
+	model        string
+	safetyMode   string
+	visionModels map[string]bool
+	httpClient   HTTPClient
+	baseURL      string
+}
+
+// NewCohereRepository creates a new instance of the Cohere repository
+func NewCohereRepository(config config.Config, httpClient HTTPClient) (domain.LLMRepository, error) {
+	return &CohereRepository{
+		apiKey:       config.CohereAPIKey,
+		model:        config.CohereModel,
+		safetyMode:   strings.ToUpper(config.CohereSafetyMode),
+		visionModels: modelSet(config.VisionModels),
+		httpClient:   httpClient,
+		baseURL:      config.CohereUrl,
+	}, nil
+}
+
+// Send sends a message to Cohere and returns the response
+func (r *CohereRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	model := resolveModel(prompt, r.model)
+	if err := checkVision(prompt, model, r.visionModels); err != nil {
+		return nil, err
+	}
+	tools, toolChoice, err := cohereTools(prompt.Tools, prompt.ToolChoice)
+	if err != nil {
+		return nil, err
+	}
+	resp, respBody, err := postJSON(ctx, r.httpClient, r.baseURL, r.apiKey, CohereRequest{
+		Model:          model,
+		Messages:       cohereMessages(prompt.Conversation()),
+		Tools:          tools,
+		ToolChoice:     toolChoice,
+		ResponseFormat: cohereResponseFormat(prompt.ResponseFormat),
+		SafetyMode:     r.safetyMode,
+		Temperature:    prompt.Temperature,
+		MaxTokens:      prompt.MaxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Msgf("Cohere API response status: %s", resp.Status)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseCohereError(resp.StatusCode, respBody)
+	}
+
+	var result CohereResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Cohere response: %w", err)
+	}
+	finishReason, err := cohereFinishReason(result.FinishReason)
+	if err != nil {
+		return nil, err
+	}
+	var content strings.Builder
+	for _, part := range result.Message.Content {
+		if part.Type == "text" {
+			content.WriteString(part.Text)
+		}
+	}
+	tokens := result.Usage.Tokens
+	if tokens.InputTokens == 0 && tokens.OutputTokens == 0 {
+		tokens = result.Usage.BilledUnits
+	}
+	return &domain.LLMResponse{
+		Content:      content.String(),
+		ToolCalls:    domainToolCalls(result.Message.ToolCalls),
+		Model:        model,
+		FinishReason: finishReason,
+		Usage: domain.Usage{
+			InputTokens:  tokens.InputTokens,
+			OutputTokens: tokens.OutputTokens,
+			TotalTokens:  tokens.InputTokens + tokens.OutputTokens,
+		},
+	}, nil
+}
+
+// cohereMessages translates the conversation into Cohere messages
+func cohereMessages(conversation []domain.Message) []CohereMessage {
+	messages := make([]CohereMessage, 0, len(conversation))
+	for _, message := range conversation {
+		cohereMessage := CohereMessage{
+			Role:       message.Role,
+			ToolCalls:  functionToolCalls(message.ToolCalls),
+			ToolCallID: message.ToolCallID,
+		}
+		if parts := chatContentParts(message); parts != nil {
+			cohereMessage.Content = parts
+		} else if message.Content != "" {
+			cohereMessage.Content = message.Content
+		}
+		messages = append(messages, cohereMessage)
+	}
+	return messages
+}
+
+// cohereTools translates the tools and the tool choice. Cohere can't force a specific tool,
+// so forcing one only offers that tool and requires a tool call. Forcing a tool not in tools is an InputError.
+func cohereTools(tools []domain.Tool, choice string) ([]FunctionTool, string, error) {
+	switch choice {
+	case "", domain.ToolChoiceAuto:
+		return functionTools(tools), "", nil
+	case domain.ToolChoiceNone, domain.ToolChoiceRequired:
+		return functionTools(tools), strings.ToUpper(choice), nil
+	}
+	for _, tool := range tools {
+		if tool.Name == choice {
+			return functionTools([]domain.Tool{tool}), "REQUIRED", nil
+		}
+	}
+	return nil, "", &domain.InputError{Message: fmt.Sprintf("tool_choice %s is not one of the tools", choice)}
+}
+
+// cohereResponseFormat translates the response format, the text format is Cohere's default
+func cohereResponseFormat(format *domain.ResponseFormat) *CohereResponseFormat {
+	if !format.IsJSON() {
+		return nil
+	}
+	return &CohereResponseFormat{Type: domain.ResponseFormatJSONObject, JSONSchema: format.Schema}
+}
+
+// parseCohereError converts a Cohere error body into a ProviderError
+func parseCohereError(statusCode int, body []byte) error {
+	providerErr := &domain.ProviderError{
+		Provider:   "Cohere",
+		StatusCode: statusCode,
+		Message:    http.StatusText(statusCode),
+	}
+	var result CohereResponseError
+	if err := json.Unmarshal(body, &result); err == nil && result.Message != "" {
+		providerErr.Message = result.Message
+	}
+	return providerErr
+}
+
+// cohereFinishReason normalizes the Cohere finish reason, failing when the generation errored
+func cohereFinishReason(reason string) (string, error) {
+	switch reason {
+	case "MAX_TOKENS":
+		return domain.FinishReasonLength, nil
+	case "TOOL_CALL":
+		return domain.FinishReasonToolCalls, nil
+	case "ERROR_TOXIC":
+		return domain.FinishReasonContentFilter, nil
+	case "ERROR", "ERROR_LIMIT", "TIMEOUT":
+		return "", fmt.Errorf("cohere generation stopped with finish reason %q", reason)
+	default:
+		return domain.FinishReasonStop, nil
+	}
+}
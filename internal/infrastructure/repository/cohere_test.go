@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCohereRepository(server string, client HTTPClient) *CohereRepository {
+	return &CohereRepository{
+		apiKey:       "test_api_key",
+		model:        "command-r-plus-08-2024",
+		safetyMode:   "STRICT",
+		visionModels: map[string]bool{"command-a-vision-07-2025": true},
+		httpClient:   client,
+		baseURL:      server,
+	}
+}
+
+func TestNewCohereRepository(t *testing.T) {
+	cfg := config.Config{
+		CohereAPIKey:     "test_api_key",
+		CohereModel:      "test_model",
+		CohereUrl:        "http://localhost",
+		CohereSafetyMode: "contextual",
+	}
+	client := &MockHTTPClient{}
+	repo, err := NewCohereRepository(cfg, client)
+
+	assert.NoError(t, err)
+
+	cohereRepo, ok := repo.(*CohereRepository)
+	assert.True(t, ok)
+	assert.Equal(t, config.Secret("test_api_key"), cohereRepo.apiKey)
+	assert.Equal(t, "test_model", cohereRepo.model)
+	assert.Equal(t, "http://localhost", cohereRepo.baseURL)
+	assert.Equal(t, "CONTEXTUAL", cohereRepo.safetyMode)
+	assert.Equal(t, client, cohereRepo.httpClient)
+}
+
+func TestCohereRepository_Send(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "What is the capital of France?"}
+
+	t.Run("successful response", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "cohere_chat.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "Bearer test_api_key", r.Header.Get("Authorization"))
+
+			var request CohereRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "command-r-plus-08-2024", request.Model)
+			assert.Equal(t, "STRICT", request.SafetyMode)
+			assert.Equal(t, []CohereMessage{{Role: "user", Content: "What is the capital of France?"}}, request.Messages)
+		})
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "command-r-plus-08-2024", response.Model)
+		assert.Equal(t, domain.FinishReasonStop, response.FinishReason)
+		assert.Equal(t, domain.Usage{InputTokens: 205, OutputTokens: 8, TotalTokens: 213}, response.Usage)
+	})
+
+	t.Run("max tokens response concatenates parts and uses billed units", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "cohere_chat_max_tokens.json", nil)
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is", response.Content)
+		assert.Equal(t, domain.FinishReasonLength, response.FinishReason)
+		assert.Equal(t, domain.Usage{InputTokens: 9, OutputTokens: 5, TotalTokens: 14}, response.Usage)
+	})
+
+	t.Run("generation error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "cohere_chat_error.json", nil)
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, `cohere generation stopped with finish reason "ERROR"`)
+	})
+
+	t.Run("unauthorized error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusUnauthorized, "cohere_error_unauthorized.json", nil)
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "Cohere", providerErr.Provider)
+		assert.Equal(t, http.StatusUnauthorized, providerErr.StatusCode)
+		assert.Equal(t, "invalid api token", providerErr.Message)
+	})
+
+	t.Run("invalid json response", func(t *testing.T) {
+		client := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				return newMockResponse(http.StatusOK, "invalid json"), nil
+			},
+		}
+		repo := newTestCohereRepository("http://localhost", client)
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		assert.Contains(t, err.Error(), "failed to parse Cohere response")
+	})
+}
+
+func TestCohereFinishReason(t *testing.T) {
+	tests := []struct {
+		reason   string
+		expected string
+		wantErr  bool
+	}{
+		{reason: "COMPLETE", expected: domain.FinishReasonStop},
+		{reason: "STOP_SEQUENCE", expected: domain.FinishReasonStop},
+		{reason: "MAX_TOKENS", expected: domain.FinishReasonLength},
+		{reason: "TOOL_CALL", expected: domain.FinishReasonToolCalls},
+		{reason: "ERROR_TOXIC", expected: domain.FinishReasonContentFilter},
+		{reason: "ERROR", wantErr: true},
+		{reason: "TIMEOUT", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			reason, err := cohereFinishReason(tt.reason)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, reason)
+		})
+	}
+}
+
+func TestCohereRepository_Send_Tools(t *testing.T) {
+	t.Run("tool calls", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "cohere_chat_tool_calls.json", func(r *http.Request, body []byte) {
+			var request CohereRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "REQUIRED", request.ToolChoice)
+			assert.Equal(t, functionTools([]domain.Tool{weatherTool}), request.Tools)
+		})
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), domain.PromptRequest{
+			Prompt:     "What is the weather in Paris?",
+			Tools:      []domain.Tool{weatherTool, {Name: "current_time"}},
+			ToolChoice: "get_weather",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonToolCalls, response.FinishReason)
+		assert.Equal(t, []domain.ToolCall{{ID: "get_weather_1byjy32y4hvq", Name: "get_weather", Arguments: `{"city":"Paris"}`}}, response.ToolCalls)
+	})
+
+	t.Run("tool results follow up", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "cohere_chat.json", func(r *http.Request, body []byte) {
+			var request CohereRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, []CohereMessage{
+				{Role: "user", Content: "What is the weather in Paris?"},
+				{Role: "assistant", ToolCalls: []FunctionToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+				{Role: "tool", ToolCallID: "call_1", Content: `{"temperature":18}`},
+			}, request.Messages)
+			assert.Empty(t, request.ToolChoice)
+		})
+		repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		_, err := repo.Send(context.Background(), toolFollowUp)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestCohereTools(t *testing.T) {
+	tools := []domain.Tool{weatherTool, {Name: "current_time"}}
+
+	translated, choice, err := cohereTools(tools, "")
+	assert.NoError(t, err)
+	assert.Len(t, translated, 2)
+	assert.Empty(t, choice)
+
+	translated, choice, err = cohereTools(tools, domain.ToolChoiceNone)
+	assert.NoError(t, err)
+	assert.Len(t, translated, 2)
+	assert.Equal(t, "NONE", choice)
+
+	translated, choice, err = cohereTools(tools, "current_time")
+	assert.NoError(t, err)
+	assert.Equal(t, functionTools([]domain.Tool{{Name: "current_time"}}), translated)
+	assert.Equal(t, "REQUIRED", choice)
+
+	_, _, err = cohereTools(tools, "unknown_tool")
+	var inputErr *domain.InputError
+	assert.ErrorAs(t, err, &inputErr)
+	assert.EqualError(t, err, "tool_choice unknown_tool is not one of the tools")
+}
+
+func TestCohereRepository_Send_ResponseFormat(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "cohere_chat.json", func(r *http.Request, body []byte) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.JSONEq(t, `{"type":"json_object",
+			"json_schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}`, string(request["response_format"]))
+	})
+	repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", ResponseFormat: cityFormat})
+
+	assert.NoError(t, err)
+	assert.Nil(t, cohereResponseFormat(&domain.ResponseFormat{Type: domain.ResponseFormatText}))
+}
+
+func TestCohereRepository_Send_Parameters(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "cohere_chat.json", func(r *http.Request, body []byte) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.JSONEq(t, `0`, string(request["temperature"]))
+		assert.JSONEq(t, `256`, string(request["max_tokens"]))
+	})
+	repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+	temperature := 0.0
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", Temperature: &temperature, MaxTokens: 256})
+
+	assert.NoError(t, err)
+}
+
+func TestCohereRepository_Send_Images(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "cohere_chat.json", func(r *http.Request, body []byte) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.JSONEq(t, `[{"role":"user","content":[
+			{"type":"text","text":"What is wrong in this screenshot?"},
+			{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}},
+			{"type":"image_url","image_url":{"url":"https://example.com/error.png"}}]}]`, string(request["messages"]))
+	})
+	repo := newTestCohereRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+	_, err := repo.Send(context.Background(), screenshotRequest("command-a-vision-07-2025"))
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+)
+
+// CompatibleEmbeddingRequest is the body sent to an OpenAI compatible embeddings API
+type CompatibleEmbeddingRequest struct {
+	Input          []string `json:"input"`
+	Model          string   `json:"model"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+	User           string   `json:"user,omitempty"`
+}
+
+// CompatibleResponseError is the error body returned by the OpenAI compatible APIs
+type CompatibleResponseError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+	} `json:"error"`
+}
+
+// CompatibleEmbeddingRepository implements EmbeddingRepository for the APIs shaped as the OpenAI embeddings API,
+// eg: Mistral, Together, vLLM or LocalAI
+type CompatibleEmbeddingRepository struct {
+	apiKey     config.Secret
+	model      string
+	httpClient HTTPClient
+	baseURL    string
+}
+
+// NewCompatibleEmbeddingRepository creates a new instance of the OpenAI compatible embeddings repository
+func NewCompatibleEmbeddingRepository(config config.Config, httpClient HTTPClient) (domain.EmbeddingRepository, error) {
+	if config.EmbeddingsUrl == "" {
+		return nil, fmt.Errorf("EMBEDDINGS_URL is required by the openai_compatible embeddings provider")
+	}
+	return &CompatibleEmbeddingRepository{
+		apiKey:     config.EmbeddingsAPIKey,
+		model:      config.EmbeddingsModel,
+		httpClient: httpClient,
+		baseURL:    config.EmbeddingsUrl,
+	}, nil
+}
+
+// Embed returns the embeddings of the inputs
+func (r *CompatibleEmbeddingRepository) Embed(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	model := request.Model
+	if model == "" {
+		model = r.model
+	}
+	resp, respBody, err := postJSON(ctx, r.httpClient, r.baseURL, r.apiKey, CompatibleEmbeddingRequest{
+		Input:          request.Input,
+		Model:          model,
+		Dimensions:     request.Dimensions,
+		EncodingFormat: domain.EncodingFormatFloat,
+		User:           request.User,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Msgf("embeddings API response status: %s", resp.Status)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseCompatibleError(resp.StatusCode, respBody)
+	}
+	var result domain.EmbeddingResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	if result.Model == "" {
+		result.Model = model
+	}
+	if result.Usage.TotalTokens == 0 {
+		result.Usage.TotalTokens = result.Usage.PromptTokens
+	}
+	return &result, nil
+}
+
+// parseCompatibleError converts an OpenAI compatible error body into a ProviderError
+func parseCompatibleError(statusCode int, body []byte) error {
+	providerErr := &domain.ProviderError{
+		Provider:   "Embeddings",
+		StatusCode: statusCode,
+		Message:    http.StatusText(statusCode),
+	}
+	var result CompatibleResponseError
+	if err := json.Unmarshal(body, &result); err == nil && result.Error.Message != "" {
+		providerErr.Type = result.Error.Type
+		providerErr.Code = result.Error.Code
+		providerErr.Message = result.Error.Message
+	}
+	return providerErr
+}
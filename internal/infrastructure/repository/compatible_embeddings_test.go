@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCompatibleEmbeddingRepository_RequiresURL(t *testing.T) {
+	repo, err := NewCompatibleEmbeddingRepository(config.Config{}, &MockHTTPClient{})
+
+	assert.Nil(t, repo)
+	assert.EqualError(t, err, "EMBEDDINGS_URL is required by the openai_compatible embeddings provider")
+}
+
+func TestCompatibleEmbeddingRepository_Embed(t *testing.T) {
+	request := domain.EmbeddingRequest{Input: domain.EmbeddingInput{"first", "second"}, Model: "mistral-embed"}
+
+	t.Run("success", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_embeddings.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "Bearer test_api_key", r.Header.Get("Authorization"))
+
+			var payload CompatibleEmbeddingRequest
+			assert.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, CompatibleEmbeddingRequest{Input: []string{"first", "second"}, Model: "mistral-embed", EncodingFormat: "float"}, payload)
+		})
+		repo, _ := NewCompatibleEmbeddingRepository(config.Config{
+			EmbeddingsUrl: server.URL, EmbeddingsAPIKey: "test_api_key", EmbeddingsModel: "text-embedding-3-small",
+		}, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Embed(context.Background(), request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "mistral-embed", response.Model)
+		assert.Equal(t, []domain.Embedding{
+			{Object: "embedding", Index: 1, Embedding: []float32{-0.0165, 0.0701, 0.0319}},
+			{Object: "embedding", Index: 0, Embedding: []float32{-0.0248, 0.0533, 0.0412}},
+		}, response.Data)
+		assert.Equal(t, domain.EmbeddingUsage{PromptTokens: 15, TotalTokens: 15}, response.Usage)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusBadRequest, "compatible_embeddings_error.json", nil)
+		repo, _ := NewCompatibleEmbeddingRepository(config.Config{EmbeddingsUrl: server.URL}, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Embed(context.Background(), request)
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "invalid_request_error", providerErr.Type)
+		assert.Equal(t, "model_not_found", providerErr.Code)
+		assert.Equal(t, "Invalid model: text-embedding-4", providerErr.Message)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		mockClient := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				return newMockResponse(http.StatusOK, "invalid json"), nil
+			},
+		}
+		repo, _ := NewCompatibleEmbeddingRepository(config.Config{EmbeddingsUrl: "http://localhost"}, mockClient)
+
+		_, err := repo.Embed(context.Background(), request)
+
+		assert.Contains(t, err.Error(), "failed to parse embeddings response")
+	})
+}
@@ -3,129 +3,116 @@ package repository
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	anysherhttp "github.com/narumayase/anysher/http"
 	"github.com/rs/zerolog/log"
-	"io/ioutil"
 	"net/http"
 	"prompthor/config"
 	"prompthor/internal/domain"
+	"strconv"
+	"time"
 )
 
-// HTTPClient is an interface for an HTTP client.
-type HTTPClient interface {
-	Post(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error)
-}
-
+// GroqResponseError is the error body returned by the Groq API
 type GroqResponseError struct {
 	Error GroqError `json:"error"`
 }
 
+// GroqError is the detail of a Groq error
 type GroqError struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
 	Code    string `json:"code"`
 }
 
-// GroqResponse is the response from the Groq API
-type GroqResponse struct {
-	ID     string  `json:"id"`
-	Output []Entry `json:"output"`
-}
-
-// Entry is a single entry in the Groq response
-type Entry struct {
-	Type    string    `json:"type"`
-	ID      string    `json:"id"`
-	Status  string    `json:"status"`
-	Content []Content `json:"content,omitempty"`
-	Summary []string  `json:"summary,omitempty"`
+// GroqRateLimit is the rate limit information Groq sends in the response headers
+type GroqRateLimit struct {
+	RemainingRequests string
+	RemainingTokens   string
+	ResetRequests     string
+	ResetTokens       string
+	RetryAfter        time.Duration
 }
 
-// Content is the content of a Groq response entry
-type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
-// GroqRepository implements LLMRepository using Groq API
+// GroqRepository implements LLMRepository using Groq API.
+// Models listed in chatCompletionsModels are sent to the chat completions API, the rest to the Responses API.
 type GroqRepository struct {
-	apiKey     string
-	model      string
-	httpClient HTTPClient
-	baseURL    string
+	apiKey                config.Secret
+	model                 string
+	httpClient            HTTPClient
+	baseURL               string
+	chatCompletionsURL    string
+	chatCompletionsModels map[string]bool
+	visionModels          map[string]bool
 }
 
 // NewGroqRepository creates a new instance of the Groq repository
 func NewGroqRepository(config config.Config, httpClient HTTPClient) (domain.LLMRepository, error) {
 	return &GroqRepository{
-		apiKey:     config.GroqAPIKey,
-		model:      config.ChatModel,
-		httpClient: httpClient,
-		baseURL:    config.GroqUrl,
+		apiKey:                config.GroqAPIKey,
+		model:                 config.ChatModel,
+		httpClient:            httpClient,
+		baseURL:               config.GroqUrl,
+		chatCompletionsURL:    config.GroqChatCompletionsUrl,
+		chatCompletionsModels: modelSet(config.GroqChatCompletionsModels),
+		visionModels:          modelSet(config.VisionModels),
 	}, nil
 }
 
 // Send sends a message to Groq and returns the response
-func (r *GroqRepository) Send(ctx context.Context, prompt domain.PromptRequest) (string, error) {
-	payload := map[string]interface{}{
-		"model": r.model,
-		"input": prompt.Prompt,
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("failed to marshal payload")
-		return "", err
+func (r *GroqRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	model := resolveModel(prompt, r.model)
+	if err := checkVision(prompt, model, r.visionModels); err != nil {
+		return nil, err
 	}
-	// send to anyway
-	resp, err := r.httpClient.Post(ctx, anysherhttp.Payload{
-		URL:   r.baseURL,
-		Token: r.apiKey,
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-			"X-Request-Id": ctx.Value("X-Request-Id").(string),
-		},
-		Content: body,
-	})
-	if err != nil {
-		return "", err
+	if r.chatCompletionsModels[model] {
+		return r.sendChatCompletion(ctx, model, prompt)
 	}
-	defer resp.Body.Close()
+	return r.sendResponse(ctx, model, prompt)
+}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+// post sends the payload to one of the Groq endpoints and returns the body of a successful response.
+// Error bodies and rate limit headers are handled the same way for every endpoint.
+func (r *GroqRepository) post(ctx context.Context, url string, payload interface{}) ([]byte, error) {
+	resp, respBody, err := postJSON(ctx, r.httpClient, url, r.apiKey, payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	rateLimit := parseGroqHeaders(resp.Header)
 	log.Ctx(ctx).Info().Msgf("Groq API response status: %s", resp.Status)
+	log.Ctx(ctx).Debug().Msgf("Groq rate limit: %+v", rateLimit)
 
 	if resp.StatusCode != http.StatusOK {
-		var result GroqResponseError
-		if err := json.Unmarshal(respBody, &result); err != nil {
-			return "", err
-		}
-		err := fmt.Errorf(result.Error.Message)
-		log.Ctx(ctx).Debug().Msgf("Groq API response: %s", string(respBody))
-		return "", err
+		return nil, parseGroqError(resp.StatusCode, respBody, rateLimit)
 	}
+	return respBody, nil
+}
 
-	// Parse JSON to struct
-	var result GroqResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", err
+// parseGroqError converts a Groq error body into a ProviderError
+func parseGroqError(statusCode int, body []byte, rateLimit GroqRateLimit) error {
+	providerErr := &domain.ProviderError{
+		Provider:   "Groq",
+		StatusCode: statusCode,
+		Message:    http.StatusText(statusCode),
+		RetryAfter: rateLimit.RetryAfter,
 	}
-	var outputPrompt string
-	for _, entry := range result.Output {
-		// TODO ver de hacer más bonito esto
-		if entry.Type == "message" {
-			for _, content := range entry.Content {
-				if content.Type == "output_text" {
-					outputPrompt = content.Text
-					log.Ctx(ctx).Debug().Msgf("output prompt: %s", outputPrompt)
-				}
-			}
-		}
+	var result GroqResponseError
+	if err := json.Unmarshal(body, &result); err == nil && result.Error.Message != "" {
+		providerErr.Type = result.Error.Type
+		providerErr.Code = result.Error.Code
+		providerErr.Message = result.Error.Message
 	}
-	log.Ctx(ctx).Debug().Msgf("Groq API response: %s", string(respBody))
+	return providerErr
+}
 
-	return outputPrompt, nil
+// parseGroqHeaders reads the rate limit headers of a Groq response
+func parseGroqHeaders(header http.Header) GroqRateLimit {
+	rateLimit := GroqRateLimit{
+		RemainingRequests: header.Get("x-ratelimit-remaining-requests"),
+		RemainingTokens:   header.Get("x-ratelimit-remaining-tokens"),
+		ResetRequests:     header.Get("x-ratelimit-reset-requests"),
+		ResetTokens:       header.Get("x-ratelimit-reset-tokens"),
+	}
+	if retryAfter, err := strconv.Atoi(header.Get("retry-after")); err == nil {
+		rateLimit.RetryAfter = time.Duration(retryAfter) * time.Second
+	}
+	return rateLimit
 }
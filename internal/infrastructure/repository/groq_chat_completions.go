@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prompthor/internal/domain"
+)
+
+// GroqChatCompletionRequest is the body sent to the Groq chat completions API
+type GroqChatCompletionRequest struct {
+	Model           string              `json:"model"`
+	Messages        []GroqChatMessage   `json:"messages"`
+	Tools           []FunctionTool      `json:"tools,omitempty"`
+	ToolChoice      interface{}         `json:"tool_choice,omitempty"`
+	ReasoningEffort string              `json:"reasoning_effort,omitempty"`
+	ResponseFormat  *ChatResponseFormat `json:"response_format,omitempty"`
+	Temperature     *float64            `json:"temperature,omitempty"`
+	MaxTokens       int                 `json:"max_completion_tokens,omitempty"`
+}
+
+// GroqChatMessage is a single message of a Groq chat completions conversation.
+// Reasoning is only filled in the responses of reasoning models, Parts replaces Content for messages with images.
+type GroqChatMessage struct {
+	Role       string             `json:"role"`
+	Content    string             `json:"content"`
+	Parts      []ChatContentPart  `json:"-"`
+	Reasoning  string             `json:"reasoning,omitempty"`
+	ToolCalls  []FunctionToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string             `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON sends the content parts as the content when the message has images
+func (m GroqChatMessage) MarshalJSON() ([]byte, error) {
+	type message GroqChatMessage
+	if len(m.Parts) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []ChatContentPart `json:"content"`
+	}{message(m), m.Parts})
+}
+
+// GroqChatCompletionResponse is the response from the Groq chat completions API
+type GroqChatCompletionResponse struct {
+	ID      string           `json:"id"`
+	Model   string           `json:"model"`
+	Choices []GroqChatChoice `json:"choices"`
+	Usage   GroqChatUsage    `json:"usage"`
+}
+
+// GroqChatChoice is a single completion choice in the Groq chat completions response
+type GroqChatChoice struct {
+	Index        int             `json:"index"`
+	Message      GroqChatMessage `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// GroqChatUsage is the token usage reported by the Groq chat completions API
+type GroqChatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// sendChatCompletion sends the prompt to the Groq chat completions API
+func (r *GroqRepository) sendChatCompletion(ctx context.Context, model string, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	respBody, err := r.post(ctx, r.chatCompletionsURL, GroqChatCompletionRequest{
+		Model:           model,
+		Messages:        groqChatMessages(prompt.Conversation()),
+		Tools:           functionTools(prompt.Tools),
+		ToolChoice:      functionToolChoice(prompt.ToolChoice),
+		ReasoningEffort: prompt.ReasoningEffort,
+		ResponseFormat:  chatResponseFormat(prompt.ResponseFormat),
+		Temperature:     prompt.Temperature,
+		MaxTokens:       prompt.MaxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result GroqChatCompletionResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Groq chat completion response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("no response from Groq API")
+	}
+	choice := result.Choices[0]
+	return &domain.LLMResponse{
+		Content:      choice.Message.Content,
+		Reasoning:    choice.Message.Reasoning,
+		ToolCalls:    domainToolCalls(choice.Message.ToolCalls),
+		Model:        result.Model,
+		FinishReason: groqChatFinishReason(choice.FinishReason),
+		Usage: domain.Usage{
+			InputTokens:  result.Usage.PromptTokens,
+			OutputTokens: result.Usage.CompletionTokens,
+			TotalTokens:  result.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// groqChatMessages translates the conversation into Groq chat completions messages
+func groqChatMessages(conversation []domain.Message) []GroqChatMessage {
+	messages := make([]GroqChatMessage, 0, len(conversation))
+	for _, message := range conversation {
+		messages = append(messages, GroqChatMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			Parts:      chatContentParts(message),
+			ToolCalls:  functionToolCalls(message.ToolCalls),
+			ToolCallID: message.ToolCallID,
+		})
+	}
+	return messages
+}
+
+// groqChatFinishReason normalizes the finish reason of a Groq chat completion
+func groqChatFinishReason(reason string) string {
+	switch reason {
+	case "length":
+		return domain.FinishReasonLength
+	case "tool_calls", "function_call":
+		return domain.FinishReasonToolCalls
+	default:
+		return domain.FinishReasonStop
+	}
+}
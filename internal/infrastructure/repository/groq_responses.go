@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"prompthor/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+// GroqResponse is the response from the Groq Responses API
+type GroqResponse struct {
+	ID                string                 `json:"id"`
+	Status            string                 `json:"status"`
+	Model             string                 `json:"model"`
+	Output            []Entry                `json:"output"`
+	IncompleteDetails *GroqIncompleteDetails `json:"incomplete_details,omitempty"`
+	Error             *GroqError             `json:"error,omitempty"`
+	Usage             GroqResponseUsage      `json:"usage"`
+}
+
+// GroqIncompleteDetails explains why a response was left incomplete
+type GroqIncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+// GroqResponseUsage is the token usage reported by the Groq Responses API
+type GroqResponseUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// Entry is a single entry in the Groq response.
+// CallID, Name and Arguments are only filled in function_call entries.
+type Entry struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Content   []Content `json:"content,omitempty"`
+	Summary   []Summary `json:"summary,omitempty"`
+	CallID    string    `json:"call_id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Arguments string    `json:"arguments,omitempty"`
+}
+
+// GroqInputItem is an item of the Responses API input: a message, a function call made by the model
+// or the output of that function call
+// Content is the text of a message, or its input parts when it has images.
+type GroqInputItem struct {
+	Type      string      `json:"type"`
+	Role      string      `json:"role,omitempty"`
+	Content   interface{} `json:"content,omitempty"`
+	CallID    string      `json:"call_id,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Arguments string      `json:"arguments,omitempty"`
+	Output    *string     `json:"output,omitempty"`
+}
+
+// GroqInputPart is a text or image part of a Responses API input message
+type GroqInputPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// GroqFunctionTool is a function tool of the Responses API
+type GroqFunctionTool struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// GroqTextFormat is the format of the text generated by the Responses API, the JSON schema fields are inlined
+type GroqTextFormat struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// Content is the content of a Groq response entry
+type Content struct {
+	Type        string           `json:"type"`
+	Text        string           `json:"text"`
+	Refusal     string           `json:"refusal,omitempty"`
+	Annotations []GroqAnnotation `json:"annotations,omitempty"`
+}
+
+// GroqAnnotation is a citation attached to a range of an output_text part
+type GroqAnnotation struct {
+	Type       string `json:"type"`
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+}
+
+// Summary is a reasoning summary part of a Groq response entry
+type Summary struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// UnmarshalJSON accepts the summary either as plain text or as a summary_text object
+func (s *Summary) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		s.Type = "summary_text"
+		s.Text = text
+		return nil
+	}
+	type summary Summary
+	return json.Unmarshal(data, (*summary)(s))
+}
+
+// sendResponse sends the prompt to the Groq Responses API
+func (r *GroqRepository) sendResponse(ctx context.Context, model string, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	payload := map[string]interface{}{
+		"model": model,
+		"input": prompt.Prompt,
+	}
+	if len(prompt.Messages) > 0 || len(prompt.Images) > 0 {
+		payload["input"] = groqInput(prompt.Conversation())
+	}
+	if len(prompt.Tools) > 0 {
+		payload["tools"] = groqTools(prompt.Tools)
+	}
+	if toolChoice := groqToolChoice(prompt.ToolChoice); toolChoice != nil {
+		payload["tool_choice"] = toolChoice
+	}
+	if prompt.ReasoningEffort != "" {
+		payload["reasoning"] = map[string]string{"effort": prompt.ReasoningEffort}
+	}
+	if prompt.Temperature != nil {
+		payload["temperature"] = *prompt.Temperature
+	}
+	if prompt.MaxTokens > 0 {
+		payload["max_output_tokens"] = prompt.MaxTokens
+	}
+	if prompt.ResponseFormat != nil {
+		payload["text"] = map[string]interface{}{"format": groqTextFormat(prompt.ResponseFormat)}
+	}
+	respBody, err := r.post(ctx, r.baseURL, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeGroqResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+	if response.Model == "" {
+		response.Model = model
+	}
+	return response, nil
+}
+
+// decodeGroqResponse decodes a Responses API body into a llm response.
+// The output_text parts of every message are concatenated in order, refusals and reasoning are kept apart
+// from the answer and incomplete responses are reported through the finish reason.
+func decodeGroqResponse(body []byte) (*domain.LLMResponse, error) {
+	var result GroqResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Groq response: %w", err)
+	}
+	if result.Status == "failed" {
+		providerErr := &domain.ProviderError{
+			Provider:   "Groq",
+			StatusCode: http.StatusOK,
+			Message:    "response generation failed",
+		}
+		if result.Error != nil {
+			providerErr.Type = result.Error.Type
+			providerErr.Code = result.Error.Code
+			providerErr.Message = result.Error.Message
+		}
+		return nil, providerErr
+	}
+
+	response := &domain.LLMResponse{
+		Model:        result.Model,
+		FinishReason: domain.FinishReasonStop,
+		Usage: domain.Usage{
+			InputTokens:  result.Usage.InputTokens,
+			OutputTokens: result.Usage.OutputTokens,
+			TotalTokens:  result.Usage.TotalTokens,
+		},
+	}
+	var content strings.Builder
+	var refusals, reasoning []string
+	for _, entry := range result.Output {
+		switch entry.Type {
+		case "message":
+			// the messages are separated by a blank line, written before the first text of a message following another
+			separated := content.Len() == 0
+			for _, part := range entry.Content {
+				switch part.Type {
+				case "output_text":
+					if !separated {
+						content.WriteString("\n\n")
+						separated = true
+					}
+					// annotation indexes are relative to their part, move them to the assembled content
+					offset := utf8.RuneCountInString(content.String())
+					for _, annotation := range part.Annotations {
+						response.Annotations = append(response.Annotations, domain.Annotation{
+							Type:       annotation.Type,
+							URL:        annotation.URL,
+							Title:      annotation.Title,
+							StartIndex: annotation.StartIndex + offset,
+							EndIndex:   annotation.EndIndex + offset,
+						})
+					}
+					content.WriteString(part.Text)
+				case "refusal":
+					refusals = append(refusals, part.Refusal)
+				}
+			}
+		case "reasoning":
+			reasoning = append(reasoning, reasoningText(entry)...)
+		case "function_call":
+			response.ToolCalls = append(response.ToolCalls, domain.ToolCall{
+				ID:        entry.CallID,
+				Name:      entry.Name,
+				Arguments: entry.Arguments,
+			})
+		}
+	}
+	response.Content = content.String()
+	response.Reasoning = strings.Join(reasoning, "\n\n")
+	response.Refusal = strings.Join(refusals, "\n")
+
+	switch {
+	case result.Status == "incomplete":
+		response.FinishReason = groqIncompleteReason(result.IncompleteDetails)
+	case len(response.ToolCalls) > 0:
+		response.FinishReason = domain.FinishReasonToolCalls
+	case response.Refusal != "" && response.Content == "":
+		response.FinishReason = domain.FinishReasonContentFilter
+	}
+	return response, nil
+}
+
+// groqInput translates the conversation into Responses API input items
+func groqInput(conversation []domain.Message) []GroqInputItem {
+	items := make([]GroqInputItem, 0, len(conversation))
+	for _, message := range conversation {
+		switch {
+		case message.Role == domain.RoleTool:
+			output := message.Content
+			items = append(items, GroqInputItem{
+				Type:   "function_call_output",
+				CallID: message.ToolCallID,
+				Output: &output,
+			})
+		case len(message.ToolCalls) > 0:
+			if message.Content != "" {
+				items = append(items, GroqInputItem{Type: "message", Role: message.Role, Content: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				items = append(items, GroqInputItem{
+					Type:      "function_call",
+					CallID:    call.ID,
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				})
+			}
+		default:
+			items = append(items, GroqInputItem{Type: "message", Role: message.Role, Content: groqMessageContent(message)})
+		}
+	}
+	return items
+}
+
+// groqMessageContent returns the text of the message, or its input parts when it has images
+func groqMessageContent(message domain.Message) interface{} {
+	if len(message.Images) == 0 {
+		return message.Content
+	}
+	parts := make([]GroqInputPart, 0, len(message.Images)+1)
+	if message.Content != "" {
+		parts = append(parts, GroqInputPart{Type: "input_text", Text: message.Content})
+	}
+	for _, image := range message.Images {
+		parts = append(parts, GroqInputPart{Type: "input_image", ImageURL: image.Source(), Detail: "auto"})
+	}
+	return parts
+}
+
+// groqTools translates the neutral tools into Responses API function tools
+func groqTools(tools []domain.Tool) []GroqFunctionTool {
+	functions := make([]GroqFunctionTool, 0, len(tools))
+	for _, tool := range tools {
+		functions = append(functions, GroqFunctionTool{
+			Type:        "function",
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  toolParameters(tool),
+		})
+	}
+	return functions
+}
+
+// groqToolChoice translates the neutral tool choice, a tool name forces that function
+func groqToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case domain.ToolChoiceAuto, domain.ToolChoiceNone, domain.ToolChoiceRequired:
+		return choice
+	default:
+		return map[string]string{"type": "function", "name": choice}
+	}
+}
+
+// groqTextFormat translates the response format into the Responses API text format
+func groqTextFormat(format *domain.ResponseFormat) GroqTextFormat {
+	textFormat := GroqTextFormat{Type: format.Type}
+	if format.Type == domain.ResponseFormatJSONSchema {
+		textFormat.Name = format.SchemaName()
+		textFormat.Schema = format.Schema
+		textFormat.Strict = format.Strict
+	}
+	return textFormat
+}
+
+// groqIncompleteReason normalizes the reason of an incomplete response
+func groqIncompleteReason(details *GroqIncompleteDetails) string {
+	if details != nil && details.Reason == "content_filter" {
+		return domain.FinishReasonContentFilter
+	}
+	return domain.FinishReasonLength
+}
+
+// reasoningText returns the summaries of a reasoning entry, or its raw reasoning text when it has no summaries
+func reasoningText(entry Entry) []string {
+	var texts []string
+	for _, summary := range entry.Summary {
+		if summary.Text != "" {
+			texts = append(texts, summary.Text)
+		}
+	}
+	if len(texts) > 0 {
+		return texts
+	}
+	for _, content := range entry.Content {
+		if content.Type == "reasoning_text" && content.Text != "" {
+			texts = append(texts, content.Text)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"prompthor/internal/domain"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeGroqResponse(t *testing.T) {
+	t.Run("concatenates every message and part in order", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_multipart.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Paris is the capital of France. Its population is about 2.1 million.\n\nAnything else?", response.Content)
+		assert.Equal(t, "openai/gpt-oss-120b", response.Model)
+		assert.Equal(t, domain.FinishReasonStop, response.FinishReason)
+		assert.Equal(t, domain.Usage{InputTokens: 75, OutputTokens: 40, TotalTokens: 115}, response.Usage)
+	})
+
+	t.Run("separates only the messages adding text", func(t *testing.T) {
+		response, err := decodeGroqResponse([]byte(`{"status":"completed","output":[
+			{"type":"message","content":[{"type":"output_text","text":"Paris."}]},
+			{"type":"message","content":[{"type":"refusal","refusal":"I can't share that."}]},
+			{"type":"message","content":[]},
+			{"type":"message","content":[{"type":"output_text","text":"Anything else?"}]},
+			{"type":"message","content":[{"type":"refusal","refusal":"Nor that."}]}]}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Paris.\n\nAnything else?", response.Content)
+	})
+
+	t.Run("annotations are relative to the assembled content", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_multipart.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Annotation{
+			{Type: "url_citation", URL: "https://en.wikipedia.org/wiki/Paris", Title: "Paris - Wikipedia", StartIndex: 0, EndIndex: 5},
+			{Type: "url_citation", URL: "https://www.insee.fr", Title: "Insee", StartIndex: 32, EndIndex: 68},
+		}, response.Annotations)
+		assert.Equal(t, "Its population is about 2.1 million.", string([]rune(response.Content)[32:68]))
+	})
+
+	t.Run("refusal", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_refusal.json"))
+
+		assert.NoError(t, err)
+		assert.Empty(t, response.Content)
+		assert.Equal(t, "I'm sorry, but I can't help with that.", response.Refusal)
+		assert.Equal(t, domain.FinishReasonContentFilter, response.FinishReason)
+	})
+
+	t.Run("incomplete response", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_incomplete.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The history of Paris begins", response.Content)
+		assert.Equal(t, domain.FinishReasonLength, response.FinishReason)
+	})
+
+	t.Run("failed response", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_failed.json"))
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "server_error", providerErr.Code)
+		assert.Equal(t, "The model failed to generate a response.", providerErr.Message)
+	})
+
+	t.Run("reasoning is kept apart from the answer", func(t *testing.T) {
+		response, err := decodeGroqResponse(readFixture(t, "groq_response_reasoning.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "The user asks for the capital of France. That is Paris.", response.Reasoning)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		response, err := decodeGroqResponse([]byte("invalid json"))
+
+		assert.Nil(t, response)
+		assert.Contains(t, err.Error(), "failed to parse Groq response")
+	})
+}
+
+func TestGroqIncompleteReason(t *testing.T) {
+	assert.Equal(t, domain.FinishReasonLength, groqIncompleteReason(&GroqIncompleteDetails{Reason: "max_output_tokens"}))
+	assert.Equal(t, domain.FinishReasonContentFilter, groqIncompleteReason(&GroqIncompleteDetails{Reason: "content_filter"}))
+	assert.Equal(t, domain.FinishReasonLength, groqIncompleteReason(nil))
+}
+
+func TestGroqRepository_Send_ResponsesModelFallback(t *testing.T) {
+	mockHTTPClient := &MockHTTPClient{
+		PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+			return newMockResponse(http.StatusOK, `{"status":"completed","output":[]}`), nil
+		},
+	}
+	repo := &GroqRepository{
+		apiKey:     "test_api_key",
+		model:      "openai/gpt-oss-20b",
+		httpClient: mockHTTPClient,
+		baseURL:    "http://localhost",
+	}
+
+	response, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "openai/gpt-oss-20b", response.Model)
+}
+
+func TestGroqTextFormat(t *testing.T) {
+	format, err := json.Marshal(groqTextFormat(cityFormat))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"json_schema","name":"response","strict":true,
+		"schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}`, string(format))
+
+	format, err = json.Marshal(groqTextFormat(&domain.ResponseFormat{Type: domain.ResponseFormatJSONObject}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"json_object"}`, string(format))
+}
@@ -9,9 +9,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"prompthor/config"
 	"prompthor/internal/domain"
 	"testing"
+	"time"
 )
 
 // MockHTTPClient is a mock implementation of the HTTPClient for testing purposes.
@@ -38,14 +41,14 @@ func TestNewGroqRepository(t *testing.T) {
 
 	groqRepo, ok := repo.(*GroqRepository)
 	assert.True(t, ok)
-	assert.Equal(t, "test_api_key", groqRepo.apiKey)
+	assert.Equal(t, config.Secret("test_api_key"), groqRepo.apiKey)
 	assert.Equal(t, "test_model", groqRepo.model)
 	assert.Equal(t, "http://localhost", groqRepo.baseURL)
 	assert.Equal(t, client, groqRepo.httpClient)
 }
 
 func TestGroqRepository_Send(t *testing.T) {
-	ctx := context.WithValue(context.Background(), "X-Request-Id", "test-request-id")
+	ctx := domain.WithRequestIDs(context.Background(), domain.RequestIDs{RequestID: "test-request-id"})
 	prompt := domain.PromptRequest{Prompt: "Hello"}
 
 	t.Run("successful response", func(t *testing.T) {
@@ -65,6 +68,7 @@ func TestGroqRepository_Send(t *testing.T) {
 		mockBody, _ := json.Marshal(mockResponse)
 		mockHTTPClient := &MockHTTPClient{
 			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				assert.Equal(t, "test-request-id", payload.Headers["X-Request-Id"])
 				return &http.Response{
 					StatusCode: http.StatusOK,
 					Body:       ioutil.NopCloser(bytes.NewReader(mockBody)),
@@ -83,7 +87,25 @@ func TestGroqRepository_Send(t *testing.T) {
 		response, err := repo.Send(ctx, prompt)
 
 		assert.NoError(t, err)
-		assert.Equal(t, "World", response)
+		assert.Equal(t, "World", response.Content)
+	})
+
+	t.Run("without request id", func(t *testing.T) {
+		mockHTTPClient := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				assert.NotContains(t, payload.Headers, "X-Request-Id")
+				return newMockResponse(http.StatusOK, `{"output":[{"type":"message","content":[{"type":"output_text","text":"World"}]}]}`), nil
+			},
+		}
+		repo := &GroqRepository{
+			httpClient: mockHTTPClient,
+			baseURL:    "http://localhost",
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "World", response.Content)
 	})
 
 	t.Run("http client error", func(t *testing.T) {
@@ -103,7 +125,7 @@ func TestGroqRepository_Send(t *testing.T) {
 		response, err := repo.Send(ctx, prompt)
 
 		assert.Error(t, err)
-		assert.Equal(t, "", response)
+		assert.Nil(t, response)
 		assert.Equal(t, "http client error", err.Error())
 	})
 
@@ -128,6 +150,494 @@ func TestGroqRepository_Send(t *testing.T) {
 		response, err := repo.Send(ctx, prompt)
 
 		assert.Error(t, err)
-		assert.Equal(t, "", response)
+		assert.Nil(t, response)
+	})
+}
+
+func TestNewGroqRepository_ChatCompletionsModels(t *testing.T) {
+	cfg := config.Config{
+		GroqAPIKey:                "test_api_key",
+		ChatModel:                 "llama-3.3-70b-versatile",
+		GroqChatCompletionsUrl:    "http://localhost/chat/completions",
+		GroqChatCompletionsModels: []string{"llama-3.3-70b-versatile", "meta-llama/llama-4-scout-17b-16e-instruct"},
+	}
+	repo, err := NewGroqRepository(cfg, &MockHTTPClient{})
+
+	assert.NoError(t, err)
+
+	groqRepo := repo.(*GroqRepository)
+	assert.Equal(t, "http://localhost/chat/completions", groqRepo.chatCompletionsURL)
+	assert.True(t, groqRepo.chatCompletionsModels["llama-3.3-70b-versatile"])
+	assert.True(t, groqRepo.chatCompletionsModels["meta-llama/llama-4-scout-17b-16e-instruct"])
+	assert.False(t, groqRepo.chatCompletionsModels["openai/gpt-oss-20b"])
+}
+
+func TestGroqRepository_Send_ChatCompletions(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "What is the capital of France?"}
+
+	t.Run("successful response", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "/chat/completions", r.URL.Path)
+
+			var request GroqChatCompletionRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "llama-3.3-70b-versatile", request.Model)
+			assert.Equal(t, []GroqChatMessage{{Role: "user", Content: "What is the capital of France?"}}, request.Messages)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			baseURL:               server.URL + "/responses",
+			chatCompletionsURL:    server.URL + "/chat/completions",
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "llama-3.3-70b-versatile", response.Model)
+		assert.Equal(t, domain.FinishReasonStop, response.FinishReason)
+		assert.Equal(t, domain.Usage{InputTokens: 18, OutputTokens: 8, TotalTokens: 26}, response.Usage)
+	})
+
+	t.Run("models not listed use the responses api", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "/responses", r.URL.Path)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "openai/gpt-oss-20b",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			baseURL:               server.URL + "/responses",
+			chatCompletionsURL:    server.URL + "/chat/completions",
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		_, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty choices", func(t *testing.T) {
+		mockHTTPClient := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				return newMockResponse(http.StatusOK, `{"choices":[]}`), nil
+			},
+		}
+		repo := &GroqRepository{
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            mockHTTPClient,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "no response from Groq API")
+	})
+}
+
+func TestGroqRepository_Send_ErrorResponse(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "Hello"}
+
+	for _, chatCompletions := range []bool{false, true} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			content, _ := os.ReadFile("testdata/groq_error_rate_limit.json")
+			w.Header().Set("retry-after", "2")
+			w.Header().Set("x-ratelimit-remaining-tokens", "100")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write(content)
+		}))
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			baseURL:               server.URL,
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": chatCompletions},
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+		server.Close()
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "Groq", providerErr.Provider)
+		assert.Equal(t, http.StatusTooManyRequests, providerErr.StatusCode)
+		assert.Equal(t, "rate_limit_exceeded", providerErr.Code)
+		assert.Equal(t, "tokens", providerErr.Type)
+		assert.Equal(t, 2*time.Second, providerErr.RetryAfter)
+		assert.Contains(t, providerErr.Message, "Rate limit reached")
+	}
+}
+
+func TestParseGroqHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ratelimit-remaining-requests", "14370")
+	header.Set("x-ratelimit-remaining-tokens", "5997")
+	header.Set("x-ratelimit-reset-requests", "2m59.56s")
+	header.Set("x-ratelimit-reset-tokens", "7.66s")
+	header.Set("retry-after", "7")
+
+	rateLimit := parseGroqHeaders(header)
+
+	assert.Equal(t, GroqRateLimit{
+		RemainingRequests: "14370",
+		RemainingTokens:   "5997",
+		ResetRequests:     "2m59.56s",
+		ResetTokens:       "7.66s",
+		RetryAfter:        7 * time.Second,
+	}, rateLimit)
+}
+
+func TestParseGroqError_InvalidBody(t *testing.T) {
+	err := parseGroqError(http.StatusBadGateway, []byte("<html>bad gateway</html>"), GroqRateLimit{})
+
+	assert.EqualError(t, err, "Groq API error (status 502): Bad Gateway")
+}
+
+func TestGroqRepository_Send_Reasoning(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "What is the capital of France?", ReasoningEffort: "high"}
+
+	t.Run("responses api", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_reasoning.json", func(r *http.Request, body []byte) {
+			var request map[string]interface{}
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, map[string]interface{}{"effort": "high"}, request["reasoning"])
+		})
+		repo := &GroqRepository{
+			apiKey:     "test_api_key",
+			model:      "openai/gpt-oss-20b",
+			httpClient: anysherhttp.NewClient(server.Client()),
+			baseURL:    server.URL,
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "The user asks for the capital of France. That is Paris.", response.Reasoning)
+	})
+
+	t.Run("chat completions api", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion_reasoning.json", func(r *http.Request, body []byte) {
+			var request GroqChatCompletionRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "high", request.ReasoningEffort)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "openai/gpt-oss-20b",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"openai/gpt-oss-20b": true},
+		}
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "The user asks for the capital of France. That is Paris.", response.Reasoning)
+	})
+}
+
+func TestReasoningText(t *testing.T) {
+	t.Run("summaries take precedence over the raw reasoning", func(t *testing.T) {
+		var entry Entry
+		err := json.Unmarshal([]byte(`{
+			"type": "reasoning",
+			"content": [{"type": "reasoning_text", "text": "raw reasoning"}],
+			"summary": [{"type": "summary_text", "text": "first"}, "second"]
+		}`), &entry)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, reasoningText(entry))
+	})
+
+	t.Run("raw reasoning without summaries", func(t *testing.T) {
+		entry := Entry{
+			Type:    "reasoning",
+			Content: []Content{{Type: "reasoning_text", Text: "raw reasoning"}},
+		}
+
+		assert.Equal(t, []string{"raw reasoning"}, reasoningText(entry))
+	})
+}
+
+func TestGroqRepository_Send_ModelOverride(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+
+		var request GroqChatCompletionRequest
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.Equal(t, "llama-3.3-70b-versatile", request.Model)
+	})
+	repo := &GroqRepository{
+		apiKey:                "test_api_key",
+		model:                 "openai/gpt-oss-20b",
+		httpClient:            anysherhttp.NewClient(server.Client()),
+		baseURL:               server.URL + "/responses",
+		chatCompletionsURL:    server.URL + "/chat/completions",
+		chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+	}
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello", Model: "llama-3.3-70b-versatile"})
+
+	assert.NoError(t, err)
+}
+
+func TestGroqRepository_Send_Tools(t *testing.T) {
+	t.Run("responses api function calls", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_function_call.json", func(r *http.Request, body []byte) {
+			var request map[string]interface{}
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "What is the weather in Paris?", request["input"])
+			assert.Equal(t, map[string]interface{}{"type": "function", "name": "get_weather"}, request["tool_choice"])
+			assert.Equal(t, []interface{}{map[string]interface{}{
+				"type":        "function",
+				"name":        "get_weather",
+				"description": "Gets the current weather of a city",
+				"parameters": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"city"},
+				},
+			}}, request["tools"])
+		})
+		repo := &GroqRepository{
+			apiKey:     "test_api_key",
+			model:      "openai/gpt-oss-20b",
+			httpClient: anysherhttp.NewClient(server.Client()),
+			baseURL:    server.URL,
+		}
+
+		response, err := repo.Send(context.Background(), domain.PromptRequest{
+			Prompt:     "What is the weather in Paris?",
+			Tools:      []domain.Tool{weatherTool},
+			ToolChoice: "get_weather",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonToolCalls, response.FinishReason)
+		assert.Equal(t, []domain.ToolCall{{ID: "call_7w6qd3h9", Name: "get_weather", Arguments: `{"city":"Paris"}`}}, response.ToolCalls)
+	})
+
+	t.Run("responses api tool results follow up", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_reasoning.json", func(r *http.Request, body []byte) {
+			var request struct {
+				Input []GroqInputItem `json:"input"`
+			}
+			assert.NoError(t, json.Unmarshal(body, &request))
+			output := `{"temperature":18}`
+			assert.Equal(t, []GroqInputItem{
+				{Type: "message", Role: "user", Content: "What is the weather in Paris?"},
+				{Type: "function_call", CallID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				{Type: "function_call_output", CallID: "call_1", Output: &output},
+			}, request.Input)
+		})
+		repo := &GroqRepository{
+			apiKey:     "test_api_key",
+			model:      "openai/gpt-oss-20b",
+			httpClient: anysherhttp.NewClient(server.Client()),
+			baseURL:    server.URL,
+		}
+
+		_, err := repo.Send(context.Background(), toolFollowUp)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("chat completions tool calls", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion_tool_calls.json", func(r *http.Request, body []byte) {
+			var request GroqChatCompletionRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, functionTools([]domain.Tool{weatherTool}), request.Tools)
+			assert.Equal(t, "auto", request.ToolChoice)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		response, err := repo.Send(context.Background(), domain.PromptRequest{
+			Prompt:     "What is the weather in Paris?",
+			Tools:      []domain.Tool{weatherTool},
+			ToolChoice: domain.ToolChoiceAuto,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonToolCalls, response.FinishReason)
+		assert.Equal(t, []domain.ToolCall{{ID: "call_d5wg", Name: "get_weather", Arguments: `{"city":"Paris"}`}}, response.ToolCalls)
+	})
+
+	t.Run("chat completions tool results follow up", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			var request GroqChatCompletionRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, []GroqChatMessage{
+				{Role: "user", Content: "What is the weather in Paris?"},
+				{Role: "assistant", ToolCalls: []FunctionToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+				{Role: "tool", ToolCallID: "call_1", Content: `{"temperature":18}`},
+			}, request.Messages)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		_, err := repo.Send(context.Background(), toolFollowUp)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestGroqRepository_Send_ResponseFormat(t *testing.T) {
+	t.Run("responses api text format", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_reasoning.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `{"format":{"type":"json_schema","name":"response","strict":true,
+				"schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}}`, string(request["text"]))
+		})
+		repo := &GroqRepository{
+			apiKey:     "test_api_key",
+			model:      "openai/gpt-oss-20b",
+			httpClient: anysherhttp.NewClient(server.Client()),
+			baseURL:    server.URL,
+		}
+
+		_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", ResponseFormat: cityFormat})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("chat completions response format", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			var request GroqChatCompletionRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, chatResponseFormat(cityFormat), request.ResponseFormat)
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", ResponseFormat: cityFormat})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestGroqRepository_Send_Parameters(t *testing.T) {
+	temperature := 0.7
+
+	t.Run("responses api", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_reasoning.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `0.7`, string(request["temperature"]))
+			assert.JSONEq(t, `512`, string(request["max_output_tokens"]))
+		})
+		repo := &GroqRepository{
+			apiKey:     "test_api_key",
+			model:      "openai/gpt-oss-20b",
+			httpClient: anysherhttp.NewClient(server.Client()),
+			baseURL:    server.URL,
+		}
+
+		_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", Temperature: &temperature, MaxTokens: 512})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("chat completions", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `0.7`, string(request["temperature"]))
+			assert.JSONEq(t, `512`, string(request["max_completion_tokens"]))
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 "llama-3.3-70b-versatile",
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{"llama-3.3-70b-versatile": true},
+		}
+
+		_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", Temperature: &temperature, MaxTokens: 512})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestGroqRepository_Send_Images(t *testing.T) {
+	const visionModel = "meta-llama/llama-4-scout-17b-16e-instruct"
+
+	t.Run("responses api input images", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_response_reasoning.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `[{"type":"message","role":"user","content":[
+				{"type":"input_text","text":"What is wrong in this screenshot?"},
+				{"type":"input_image","image_url":"data:image/png;base64,iVBORw0KGgo=","detail":"auto"},
+				{"type":"input_image","image_url":"https://example.com/error.png","detail":"auto"}]}]`, string(request["input"]))
+		})
+		repo := &GroqRepository{
+			apiKey:       "test_api_key",
+			model:        visionModel,
+			httpClient:   anysherhttp.NewClient(server.Client()),
+			baseURL:      server.URL,
+			visionModels: map[string]bool{visionModel: true},
+		}
+
+		_, err := repo.Send(context.Background(), screenshotRequest(""))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("chat completions image parts", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "groq_chat_completion.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `[{"role":"user","content":[
+				{"type":"text","text":"What is wrong in this screenshot?"},
+				{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}},
+				{"type":"image_url","image_url":{"url":"https://example.com/error.png"}}]}]`, string(request["messages"]))
+		})
+		repo := &GroqRepository{
+			apiKey:                "test_api_key",
+			model:                 visionModel,
+			httpClient:            anysherhttp.NewClient(server.Client()),
+			chatCompletionsURL:    server.URL,
+			chatCompletionsModels: map[string]bool{visionModel: true},
+			visionModels:          map[string]bool{visionModel: true},
+		}
+
+		_, err := repo.Send(context.Background(), screenshotRequest(""))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects images for text-only models", func(t *testing.T) {
+		// PostFunc is nil, the request must be rejected before calling the API
+		mockClient := &MockHTTPClient{}
+		repo := &GroqRepository{apiKey: "test_api_key", model: "llama-3.1-8b-instant", httpClient: mockClient}
+
+		_, err := repo.Send(context.Background(), screenshotRequest(""))
+
+		assert.EqualError(t, err, "model llama-3.1-8b-instant does not accept images, use one of the VISION_MODELS")
 	})
 }
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	anysherhttp "github.com/narumayase/anysher/http"
+	"io"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+)
+
+// HTTPClient is an interface for an HTTP client.
+type HTTPClient interface {
+	Post(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error)
+}
+
+// requestHeaders builds the headers sent to the llm providers, forwarding the request and correlation ids when present
+func requestHeaders(ctx context.Context) map[string]string {
+	headers := domain.RequestIDsFrom(ctx).Headers()
+	headers["Content-Type"] = "application/json"
+	return headers
+}
+
+// resolveModel returns the model requested by the client, falling back to the configured one
+func resolveModel(prompt domain.PromptRequest, defaultModel string) string {
+	if prompt.Model != "" {
+		return prompt.Model
+	}
+	return defaultModel
+}
+
+// postJSON marshals the payload, sends it to the url authenticated with the current value of the token and returns the
+// response with its body already read
+func postJSON(ctx context.Context, httpClient HTTPClient, url string, token config.Secret, payload interface{}) (*http.Response, []byte, error) {
+	value, err := token.Value()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read API key: %w", err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	resp, err := httpClient.Post(ctx, anysherhttp.Payload{
+		URL:     url,
+		Token:   value,
+		Headers: requestHeaders(ctx),
+		Content: body,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp, respBody, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureServer starts an httptest server that answers every request with the recorded fixture.
+// The inspect function, when given, receives each incoming request and its body.
+func newFixtureServer(t *testing.T, status int, fixture string, inspect func(r *http.Request, body []byte)) *httptest.Server {
+	t.Helper()
+	content := readFixture(t, fixture)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if inspect != nil {
+			inspect(r, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readFixture reads a recorded provider response from the testdata directory
+func readFixture(t *testing.T, fixture string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", fixture))
+	require.NoError(t, err)
+	return content
+}
+
+// newMockResponse builds an http response with the given status and body for the mock clients
+func newMockResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	t.Run("without request id", func(t *testing.T) {
+		headers := requestHeaders(context.Background())
+
+		assert.Equal(t, map[string]string{"Content-Type": "application/json"}, headers)
+	})
+
+	t.Run("with request and correlation ids", func(t *testing.T) {
+		ctx := domain.WithRequestIDs(context.Background(), domain.RequestIDs{
+			RequestID:     "test-request-id",
+			CorrelationID: "test-correlation-id",
+			RoutingKey:    "telegram:12345",
+		})
+		headers := requestHeaders(ctx)
+
+		assert.Equal(t, map[string]string{
+			"Content-Type":     "application/json",
+			"X-Request-Id":     "test-request-id",
+			"X-Correlation-Id": "test-correlation-id",
+		}, headers)
+	})
+
+	t.Run("with untyped context values", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "X-Request-Id", 42)
+
+		assert.Equal(t, map[string]string{"Content-Type": "application/json"}, requestHeaders(ctx))
+	})
+}
+
+func TestResolveModel(t *testing.T) {
+	assert.Equal(t, "default-model", resolveModel(domain.PromptRequest{Prompt: "Hello"}, "default-model"))
+	assert.Equal(t, "requested-model", resolveModel(domain.PromptRequest{Prompt: "Hello", Model: "requested-model"}, "default-model"))
+}
+
+func TestPostJSON(t *testing.T) {
+	t.Run("sends the payload and reads the body", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.JSONEq(t, `{"key":"value"}`, string(body))
+		})
+
+		resp, body, err := postJSON(context.Background(), anysherhttp.NewClient(server.Client()), server.URL, "test-token", map[string]string{"key": "value"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), "chat.completion")
+	})
+
+	t.Run("unreadable API key", func(t *testing.T) {
+		apiKey := config.FileSecret(filepath.Join(t.TempDir(), "missing"))
+
+		resp, body, err := postJSON(context.Background(), &MockHTTPClient{}, "http://localhost", apiKey, map[string]string{})
+
+		assert.ErrorContains(t, err, "failed to read API key: failed to read secret file")
+		assert.Nil(t, resp)
+		assert.Nil(t, body)
+	})
+
+	t.Run("unmarshalable payload", func(t *testing.T) {
+		resp, body, err := postJSON(context.Background(), &MockHTTPClient{}, "http://localhost", "test-token", func() {})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Nil(t, body)
+		assert.Contains(t, err.Error(), "failed to marshal payload")
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		client := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				return nil, errors.New("http client error")
+			},
+		}
+		resp, body, err := postJSON(context.Background(), client, "http://localhost", "test-token", map[string]string{})
+
+		assert.EqualError(t, err, "http client error")
+		assert.Nil(t, resp)
+		assert.Nil(t, body)
+	})
+}
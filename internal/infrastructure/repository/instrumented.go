@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"prompthor/internal/domain"
+	"strings"
+	"time"
+)
+
+// defaultModel labels the calls without a model, answered by the default model of the provider
+const defaultModel = "default"
+
+// otherModel labels the calls to the models not configured, so the clients can't create a series per model they send
+const otherModel = "other"
+
+// InstrumentedRepository records the latency, errors and tokens of the calls of any llm repository
+type InstrumentedRepository struct {
+	next     domain.LLMRepository
+	provider string
+	models   map[string]bool
+	metrics  domain.Metrics
+}
+
+// NewInstrumentedRepository wraps the repository of the provider, recording the metrics of its calls labeled with
+// their model when it is one of the configured models, or with that model for one of its versions, eg:
+// gpt-4o-mini-2024-07-18 is labeled gpt-4o-mini
+func NewInstrumentedRepository(next domain.LLMRepository, provider string, models []string, metrics domain.Metrics) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		next:     next,
+		provider: provider,
+		models:   modelSet(models),
+		metrics:  metrics,
+	}
+}
+
+// Send sends the prompt to the wrapped repository and records the call
+func (r *InstrumentedRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	start := time.Now()
+	r.metrics.LLMRequestStarted(r.provider)
+	response, err := r.next.Send(ctx, prompt)
+
+	model := prompt.Model
+	var usage domain.Usage
+	if response != nil {
+		usage = response.Usage
+		if response.Model != "" {
+			model = response.Model
+		}
+	}
+	r.metrics.LLMRequestFinished(r.provider, r.modelLabel(model), time.Since(start), usage, err)
+	return response, err
+}
+
+// modelLabel returns the label of the model: the configured model it is, or the longest one it is a version of, and
+// other when it isn't configured
+func (r *InstrumentedRepository) modelLabel(model string) string {
+	if model == "" {
+		return defaultModel
+	}
+	if r.models[model] {
+		return model
+	}
+	label := otherModel
+	for known := range r.models {
+		if strings.HasPrefix(model, known+"-") && (label == otherModel || len(known) > len(label)) {
+			label = known
+		}
+	}
+	return label
+}
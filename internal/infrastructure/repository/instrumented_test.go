@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockLLMRepository is a mock implementation of LLMRepository
+type MockLLMRepository struct {
+	mock.Mock
+}
+
+func (m *MockLLMRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	args := m.Called(ctx, prompt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LLMResponse), args.Error(1)
+}
+
+// MockMetrics is a mock implementation of Metrics
+type MockMetrics struct {
+	mock.Mock
+}
+
+func (m *MockMetrics) HTTPRequestStarted() {
+	m.Called()
+}
+
+func (m *MockMetrics) HTTPRequestFinished(method, route string, status int, duration time.Duration) {
+	m.Called(method, route, status, duration)
+}
+
+func (m *MockMetrics) LLMRequestStarted(provider string) {
+	m.Called(provider)
+}
+
+func (m *MockMetrics) LLMRequestFinished(provider, model string, duration time.Duration, usage domain.Usage, err error) {
+	m.Called(provider, model, duration, usage, err)
+}
+
+func (m *MockMetrics) Retry(reason string) {
+	m.Called(reason)
+}
+
+func (m *MockMetrics) CacheLookup(cache string, hit bool) {
+	m.Called(cache, hit)
+}
+
+func TestInstrumentedRepository_Send(t *testing.T) {
+	t.Run("records the model and usage of the response", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Hello"}
+		response := &domain.LLMResponse{
+			Content: "Hi",
+			Model:   "gpt-4o-mini-2024-07-18",
+			Usage:   domain.Usage{InputTokens: 5, OutputTokens: 2, TotalTokens: 7},
+		}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(response, nil)
+		metrics := &MockMetrics{}
+		metrics.On("LLMRequestStarted", "openai").Return()
+		metrics.On("LLMRequestFinished", "openai", "gpt-4o-mini", mock.AnythingOfType("time.Duration"), response.Usage, nil).Return()
+
+		result, err := NewInstrumentedRepository(next, "openai", []string{"gpt-4o", "gpt-4o-mini"}, metrics).Send(context.Background(), prompt)
+
+		require.NoError(t, err)
+		assert.Same(t, response, result)
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("records the error with the requested model", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Hello", Model: "mistral-small-latest"}
+		sendErr := errors.New("connection refused")
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(nil, sendErr)
+		metrics := &MockMetrics{}
+		metrics.On("LLMRequestStarted", "mistral").Return()
+		metrics.On("LLMRequestFinished", "mistral", "mistral-small-latest", mock.AnythingOfType("time.Duration"), domain.Usage{}, sendErr).Return()
+
+		result, err := NewInstrumentedRepository(next, "mistral", []string{"mistral-small-latest"}, metrics).Send(context.Background(), prompt)
+
+		assert.Nil(t, result)
+		assert.Equal(t, sendErr, err)
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("labels the failed calls to a version with the configured model", func(t *testing.T) {
+		sendErr := &domain.ProviderError{Provider: "OpenAI", StatusCode: 404, Message: "model not found"}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, mock.Anything).Return(nil, sendErr)
+		metrics := &MockMetrics{}
+		metrics.On("LLMRequestStarted", "openai").Return()
+		metrics.On("LLMRequestFinished", "openai", "gpt-4o-mini", mock.AnythingOfType("time.Duration"), domain.Usage{}, sendErr).Return()
+		repo := NewInstrumentedRepository(next, "openai", []string{"gpt-4o-mini"}, metrics)
+
+		for _, model := range []string{"gpt-4o-mini-a1b2c3", "gpt-4o-mini-d4e5f6"} {
+			_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello", Model: model})
+			assert.Equal(t, sendErr, err)
+		}
+
+		metrics.AssertExpectations(t)
+		metrics.AssertNumberOfCalls(t, "LLMRequestFinished", 2)
+	})
+
+	t.Run("labels the calls without a model as default", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Hello"}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(&domain.LLMResponse{Content: "Hi"}, nil)
+		metrics := &MockMetrics{}
+		metrics.On("LLMRequestStarted", "cohere").Return()
+		metrics.On("LLMRequestFinished", "cohere", "default", mock.AnythingOfType("time.Duration"), domain.Usage{}, nil).Return()
+
+		_, err := NewInstrumentedRepository(next, "cohere", []string{"command-r-plus-08-2024"}, metrics).Send(context.Background(), prompt)
+
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("labels the models not configured as other", func(t *testing.T) {
+		prompt := domain.PromptRequest{Prompt: "Hello", Model: "made-up-model-1"}
+		sendErr := &domain.ProviderError{Provider: "Groq", StatusCode: 404, Message: "model not found"}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(nil, sendErr)
+		next.On("Send", mock.Anything, domain.PromptRequest{Prompt: "Hello"}).
+			Return(&domain.LLMResponse{Content: "Hi", Model: "unexpected-model"}, nil)
+		metrics := &MockMetrics{}
+		metrics.On("LLMRequestStarted", "groq").Return()
+		metrics.On("LLMRequestFinished", "groq", "other", mock.AnythingOfType("time.Duration"), domain.Usage{}, sendErr).Return()
+		metrics.On("LLMRequestFinished", "groq", "other", mock.AnythingOfType("time.Duration"), domain.Usage{}, nil).Return()
+		repo := NewInstrumentedRepository(next, "groq", []string{"openai/gpt-oss-20b"}, metrics)
+
+		_, err := repo.Send(context.Background(), prompt)
+		assert.Equal(t, sendErr, err)
+		_, err = repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello"})
+
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+	})
+}
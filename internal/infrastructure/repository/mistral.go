@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+)
+
+// MistralRequest is the body sent to the Mistral chat completions API
+type MistralRequest struct {
+	Model          string              `json:"model"`
+	Messages       []MistralMessage    `json:"messages"`
+	Tools          []FunctionTool      `json:"tools,omitempty"`
+	ToolChoice     interface{}         `json:"tool_choice,omitempty"`
+	ResponseFormat *ChatResponseFormat `json:"response_format,omitempty"`
+	SafePrompt     bool                `json:"safe_prompt,omitempty"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+	MaxTokens      int                 `json:"max_tokens,omitempty"`
+}
+
+// MistralMessage is a single message of a Mistral conversation, Parts replaces Content for messages with images
+type MistralMessage struct {
+	Role       string             `json:"role"`
+	Content    string             `json:"content"`
+	Parts      []ChatContentPart  `json:"-"`
+	ToolCalls  []FunctionToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string             `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON sends the content parts as the content when the message has images
+func (m MistralMessage) MarshalJSON() ([]byte, error) {
+	type message MistralMessage
+	if len(m.Parts) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []ChatContentPart `json:"content"`
+	}{message(m), m.Parts})
+}
+
+// MistralResponse is the response from the Mistral chat completions API
+type MistralResponse struct {
+	ID      string          `json:"id"`
+	Model   string          `json:"model"`
+	Choices []MistralChoice `json:"choices"`
+	Usage   MistralUsage    `json:"usage"`
+}
+
+// MistralChoice is a single completion choice in the Mistral response
+type MistralChoice struct {
+	Index        int            `json:"index"`
+	Message      MistralMessage `json:"message"`
+	FinishReason string         `json:"finish_reason"`
+}
+
+// MistralUsage is the token usage reported by Mistral
+type MistralUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// MistralResponseError is the error body returned by the Mistral API.
+// Message is a plain string for most errors but an object with the validation details on 422 responses.
+type MistralResponseError struct {
+	Object  string          `json:"object"`
+	Message json.RawMessage `json:"message"`
+	Type    string          `json:"type"`
+	Code    json.RawMessage `json:"code"`
+}
+
+// MistralRepository implements LLMRepository using Mistral API
+type MistralRepository struct {
+	apiKey       config.Secret
+	model        string
+	safePrompt   bool
+	visionModels map[string]bool
+	httpClient   HTTPClient
+	baseURL      string
+}
+
+// NewMistralRepository creates a new instance of the Mistral repository
+func NewMistralRepository(config config.Config, httpClient HTTPClient) (domain.LLMRepository, error) {
+	return &MistralRepository{
+		apiKey:       config.MistralAPIKey,
+		model:        config.MistralModel,
+		safePrompt:   config.MistralSafePrompt,
+		visionModels: modelSet(config.VisionModels),
+		httpClient:   httpClient,
+		baseURL:      config.MistralUrl,
+	}, nil
+}
+
+// Send sends a message to Mistral and returns the response
+func (r *MistralRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	model := resolveModel(prompt, r.model)
+	if err := checkVision(prompt, model, r.visionModels); err != nil {
+		return nil, err
+	}
+	resp, respBody, err := postJSON(ctx, r.httpClient, r.baseURL, r.apiKey, MistralRequest{
+		Model:          model,
+		Messages:       mistralMessages(prompt.Conversation()),
+		Tools:          functionTools(prompt.Tools),
+		ToolChoice:     functionToolChoice(prompt.ToolChoice),
+		ResponseFormat: chatResponseFormat(prompt.ResponseFormat),
+		SafePrompt:     r.safePrompt,
+		Temperature:    prompt.Temperature,
+		MaxTokens:      prompt.MaxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Msgf("Mistral API response status: %s", resp.Status)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseMistralError(resp.StatusCode, respBody)
+	}
+
+	var result MistralResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Mistral response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("no response from Mistral API")
+	}
+	choice := result.Choices[0]
+	finishReason, err := mistralFinishReason(choice.FinishReason)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LLMResponse{
+		Content:      choice.Message.Content,
+		ToolCalls:    domainToolCalls(choice.Message.ToolCalls),
+		Model:        result.Model,
+		FinishReason: finishReason,
+		Usage: domain.Usage{
+			InputTokens:  result.Usage.PromptTokens,
+			OutputTokens: result.Usage.CompletionTokens,
+			TotalTokens:  result.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// mistralMessages translates the conversation into Mistral messages
+func mistralMessages(conversation []domain.Message) []MistralMessage {
+	messages := make([]MistralMessage, 0, len(conversation))
+	for _, message := range conversation {
+		messages = append(messages, MistralMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			Parts:      chatContentParts(message),
+			ToolCalls:  functionToolCalls(message.ToolCalls),
+			ToolCallID: message.ToolCallID,
+		})
+	}
+	return messages
+}
+
+// parseMistralError converts a Mistral error body into a ProviderError
+func parseMistralError(statusCode int, body []byte) error {
+	providerErr := &domain.ProviderError{
+		Provider:   "Mistral",
+		StatusCode: statusCode,
+		Message:    http.StatusText(statusCode),
+	}
+	var result MistralResponseError
+	if err := json.Unmarshal(body, &result); err != nil {
+		return providerErr
+	}
+	providerErr.Type = result.Type
+	providerErr.Code = rawString(result.Code)
+	if message := rawString(result.Message); message != "" {
+		providerErr.Message = message
+	}
+	return providerErr
+}
+
+// mistralFinishReason normalizes the Mistral finish reason, failing when the generation errored
+func mistralFinishReason(reason string) (string, error) {
+	switch reason {
+	case "length", "model_length":
+		return domain.FinishReasonLength, nil
+	case "tool_calls":
+		return domain.FinishReasonToolCalls, nil
+	case "error":
+		return "", fmt.Errorf("mistral generation stopped with finish reason %q", reason)
+	default:
+		return domain.FinishReasonStop, nil
+	}
+}
+
+// rawString returns a raw JSON value as text, unquoting it when it is a JSON string
+func rawString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	return string(raw)
+}
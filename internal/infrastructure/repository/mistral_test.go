@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMistralRepository(server string, client HTTPClient) *MistralRepository {
+	return &MistralRepository{
+		apiKey:       "test_api_key",
+		model:        "mistral-small-latest",
+		safePrompt:   true,
+		visionModels: map[string]bool{"pixtral-12b-2409": true},
+		httpClient:   client,
+		baseURL:      server,
+	}
+}
+
+func TestNewMistralRepository(t *testing.T) {
+	cfg := config.Config{
+		MistralAPIKey:     "test_api_key",
+		MistralModel:      "test_model",
+		MistralUrl:        "http://localhost",
+		MistralSafePrompt: true,
+	}
+	client := &MockHTTPClient{}
+	repo, err := NewMistralRepository(cfg, client)
+
+	assert.NoError(t, err)
+
+	mistralRepo, ok := repo.(*MistralRepository)
+	assert.True(t, ok)
+	assert.Equal(t, config.Secret("test_api_key"), mistralRepo.apiKey)
+	assert.Equal(t, "test_model", mistralRepo.model)
+	assert.Equal(t, "http://localhost", mistralRepo.baseURL)
+	assert.True(t, mistralRepo.safePrompt)
+	assert.Equal(t, client, mistralRepo.httpClient)
+}
+
+func TestMistralRepository_Send(t *testing.T) {
+	prompt := domain.PromptRequest{Prompt: "What is the capital of France?"}
+
+	t.Run("successful response", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "Bearer test_api_key", r.Header.Get("Authorization"))
+
+			var request MistralRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "mistral-small-latest", request.Model)
+			assert.True(t, request.SafePrompt)
+			assert.Equal(t, []MistralMessage{{Role: "user", Content: "What is the capital of France?"}}, request.Messages)
+		})
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The capital of France is Paris.", response.Content)
+		assert.Equal(t, "mistral-small-latest", response.Model)
+		assert.Equal(t, domain.FinishReasonStop, response.FinishReason)
+		assert.Equal(t, domain.Usage{InputTokens: 10, OutputTokens: 8, TotalTokens: 18}, response.Usage)
+	})
+
+	t.Run("truncated response", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion_length.json", nil)
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonLength, response.FinishReason)
+	})
+
+	t.Run("unauthorized error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusUnauthorized, "mistral_error_unauthorized.json", nil)
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "Mistral", providerErr.Provider)
+		assert.Equal(t, http.StatusUnauthorized, providerErr.StatusCode)
+		assert.Equal(t, "Unauthorized", providerErr.Message)
+	})
+
+	t.Run("invalid model error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusBadRequest, "mistral_error_invalid_model.json", nil)
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		_, err := repo.Send(context.Background(), prompt)
+
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "invalid_model", providerErr.Type)
+		assert.Equal(t, "1500", providerErr.Code)
+		assert.Equal(t, "Invalid model: mistral-unknown", providerErr.Message)
+		assert.Equal(t, "Mistral API error (status 400): Invalid model: mistral-unknown", err.Error())
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusUnprocessableEntity, "mistral_error_validation.json", nil)
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		_, err := repo.Send(context.Background(), prompt)
+
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "invalid_request_message_error", providerErr.Type)
+		assert.Contains(t, providerErr.Message, "Field required")
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		client := &MockHTTPClient{
+			PostFunc: func(ctx context.Context, payload anysherhttp.Payload) (*http.Response, error) {
+				return nil, errors.New("http client error")
+			},
+		}
+		repo := newTestMistralRepository("http://localhost", client)
+
+		response, err := repo.Send(context.Background(), prompt)
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "http client error")
+	})
+}
+
+func TestMistralFinishReason(t *testing.T) {
+	tests := []struct {
+		reason   string
+		expected string
+		wantErr  bool
+	}{
+		{reason: "stop", expected: domain.FinishReasonStop},
+		{reason: "length", expected: domain.FinishReasonLength},
+		{reason: "model_length", expected: domain.FinishReasonLength},
+		{reason: "tool_calls", expected: domain.FinishReasonToolCalls},
+		{reason: "error", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			reason, err := mistralFinishReason(tt.reason)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, reason)
+		})
+	}
+}
+
+func TestMistralRepository_Send_Tools(t *testing.T) {
+	t.Run("tool calls", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion_tool_calls.json", func(r *http.Request, body []byte) {
+			var request map[string]interface{}
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, "required", request["tool_choice"])
+			assert.Len(t, request["tools"], 1)
+		})
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Send(context.Background(), domain.PromptRequest{
+			Prompt:     "What is the weather in Paris?",
+			Tools:      []domain.Tool{weatherTool},
+			ToolChoice: domain.ToolChoiceRequired,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonToolCalls, response.FinishReason)
+		assert.Equal(t, []domain.ToolCall{{ID: "D681PevKs", Name: "get_weather", Arguments: `{"city": "Paris"}`}}, response.ToolCalls)
+	})
+
+	t.Run("tool results follow up", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+			var request MistralRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.Equal(t, []MistralMessage{
+				{Role: "user", Content: "What is the weather in Paris?"},
+				{Role: "assistant", ToolCalls: []FunctionToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+				{Role: "tool", ToolCallID: "call_1", Content: `{"temperature":18}`},
+			}, request.Messages)
+		})
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		_, err := repo.Send(context.Background(), toolFollowUp)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestMistralRepository_Send_ResponseFormat(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+		var request MistralRequest
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.Equal(t, &ChatResponseFormat{Type: "json_object"}, request.ResponseFormat)
+	})
+	repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{
+		Prompt:         "Capital of France?",
+		ResponseFormat: &domain.ResponseFormat{Type: domain.ResponseFormatJSONObject},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestMistralRepository_Send_Parameters(t *testing.T) {
+	server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(body, &request))
+		assert.JSONEq(t, `0.2`, string(request["temperature"]))
+		assert.JSONEq(t, `256`, string(request["max_tokens"]))
+	})
+	repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+	temperature := 0.2
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Capital of France?", Temperature: &temperature, MaxTokens: 256})
+
+	assert.NoError(t, err)
+}
+
+func TestMistralRepository_Send_Images(t *testing.T) {
+	t.Run("sends images as content parts", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "mistral_chat_completion.json", func(r *http.Request, body []byte) {
+			var request map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(body, &request))
+			assert.JSONEq(t, `[{"role":"user","content":[
+				{"type":"text","text":"What is wrong in this screenshot?"},
+				{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}},
+				{"type":"image_url","image_url":{"url":"https://example.com/error.png"}}]}]`, string(request["messages"]))
+		})
+		repo := newTestMistralRepository(server.URL, anysherhttp.NewClient(server.Client()))
+
+		_, err := repo.Send(context.Background(), screenshotRequest("pixtral-12b-2409"))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects images for text-only models", func(t *testing.T) {
+		// PostFunc is nil, the request must be rejected before calling the API
+		mockClient := &MockHTTPClient{}
+		repo := newTestMistralRepository("https://api.mistral.ai/v1/chat/completions", mockClient)
+
+		_, err := repo.Send(context.Background(), screenshotRequest(""))
+
+		var inputErr *domain.InputError
+		assert.ErrorAs(t, err, &inputErr)
+	})
+}
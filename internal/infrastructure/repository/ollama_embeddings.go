@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"strings"
+)
+
+// OllamaEmbedRequest is the body sent to the Ollama embed API
+type OllamaEmbedRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+// OllamaEmbedResponse is the response from the Ollama embed API
+type OllamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+// OllamaResponseError is the error body returned by the Ollama API
+type OllamaResponseError struct {
+	Error string `json:"error"`
+}
+
+// OllamaEmbeddingRepository implements EmbeddingRepository using the Ollama embed API
+type OllamaEmbeddingRepository struct {
+	model      string
+	httpClient HTTPClient
+	baseURL    string
+}
+
+// NewOllamaEmbeddingRepository creates a new instance of the Ollama embeddings repository
+func NewOllamaEmbeddingRepository(config config.Config, httpClient HTTPClient) (domain.EmbeddingRepository, error) {
+	return &OllamaEmbeddingRepository{
+		model:      config.EmbeddingsModel,
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(config.OllamaUrl, "/") + "/api/embed",
+	}, nil
+}
+
+// Embed returns the embeddings of the inputs
+func (r *OllamaEmbeddingRepository) Embed(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	model := request.Model
+	if model == "" {
+		model = r.model
+	}
+	resp, respBody, err := postJSON(ctx, r.httpClient, r.baseURL, "", OllamaEmbedRequest{
+		Model:      model,
+		Input:      request.Input,
+		Dimensions: request.Dimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Msgf("Ollama API response status: %s", resp.Status)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseOllamaError(resp.StatusCode, respBody)
+	}
+	var result OllamaEmbedResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+	data := make([]domain.Embedding, 0, len(result.Embeddings))
+	for i, vector := range result.Embeddings {
+		data = append(data, domain.Embedding{Object: "embedding", Index: i, Embedding: vector})
+	}
+	return &domain.EmbeddingResponse{
+		Object: "list",
+		Data:   data,
+		Model:  result.Model,
+		Usage: domain.EmbeddingUsage{
+			PromptTokens: result.PromptEvalCount,
+			TotalTokens:  result.PromptEvalCount,
+		},
+	}, nil
+}
+
+// parseOllamaError converts an Ollama error body into a ProviderError
+func parseOllamaError(statusCode int, body []byte) error {
+	providerErr := &domain.ProviderError{
+		Provider:   "Ollama",
+		StatusCode: statusCode,
+		Message:    http.StatusText(statusCode),
+	}
+	var result OllamaResponseError
+	if err := json.Unmarshal(body, &result); err == nil && result.Error != "" {
+		providerErr.Message = result.Error
+	}
+	return providerErr
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"testing"
+
+	anysherhttp "github.com/narumayase/anysher/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOllamaEmbeddingRepository_Embed(t *testing.T) {
+	request := domain.EmbeddingRequest{Input: domain.EmbeddingInput{"first", "second"}, Dimensions: 3}
+
+	t.Run("success", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusOK, "ollama_embed.json", func(r *http.Request, body []byte) {
+			assert.Equal(t, "/api/embed", r.URL.Path)
+
+			var payload OllamaEmbedRequest
+			assert.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, OllamaEmbedRequest{Model: "nomic-embed-text", Input: []string{"first", "second"}, Dimensions: 3}, payload)
+		})
+		repo, _ := NewOllamaEmbeddingRepository(config.Config{EmbeddingsModel: "nomic-embed-text", OllamaUrl: server.URL + "/"},
+			anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Embed(context.Background(), request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.EmbeddingResponse{
+			Object: "list",
+			Data: []domain.Embedding{
+				{Object: "embedding", Index: 0, Embedding: []float32{0.010071029, -0.0017594862, 0.05007221}},
+				{Object: "embedding", Index: 1, Embedding: []float32{-0.0098027075, 0.06042469, 0.025257962}},
+			},
+			Model: "nomic-embed-text",
+			Usage: domain.EmbeddingUsage{PromptTokens: 8, TotalTokens: 8},
+		}, response)
+	})
+
+	t.Run("model not found", func(t *testing.T) {
+		server := newFixtureServer(t, http.StatusNotFound, "ollama_error_model_not_found.json", nil)
+		repo, _ := NewOllamaEmbeddingRepository(config.Config{EmbeddingsModel: "nomic-embed-text", OllamaUrl: server.URL},
+			anysherhttp.NewClient(server.Client()))
+
+		response, err := repo.Embed(context.Background(), request)
+
+		assert.Nil(t, response)
+		var providerErr *domain.ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "Ollama", providerErr.Provider)
+		assert.Equal(t, http.StatusNotFound, providerErr.StatusCode)
+		assert.Equal(t, `model "nomic-embed-text" not found, try pulling it first`, providerErr.Message)
+	})
+}
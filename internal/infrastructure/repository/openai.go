@@ -4,42 +4,187 @@ import (
 	"context"
 	"fmt"
 	"github.com/sashabaranov/go-openai"
+	"prompthor/config"
 	"prompthor/internal/domain"
 	"prompthor/internal/infrastructure/client"
 )
 
 // OpenAIRepository implements LLMRepository using OpenAI API
 type OpenAIRepository struct {
-	client client.OpenAIClient
+	client       client.OpenAIClient
+	model        string
+	visionModels map[string]bool
 }
 
 // NewOpenAIRepository creates a new instance of the OpenAI repository
-func NewOpenAIRepository(client client.OpenAIClient) (domain.LLMRepository, error) {
+func NewOpenAIRepository(config config.Config, client client.OpenAIClient) (domain.LLMRepository, error) {
 	return &OpenAIRepository{
-		client: client,
+		client:       client,
+		model:        config.OpenAIModel,
+		visionModels: modelSet(config.VisionModels),
 	}, nil
 }
 
 // Send sends a message to ChatGPT and returns the response
-func (r *OpenAIRepository) Send(ctx context.Context, prompt domain.PromptRequest) (string, error) {
+func (r *OpenAIRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	model := resolveModel(prompt, r.model)
+	if err := checkVision(prompt, model, r.visionModels); err != nil {
+		return nil, err
+	}
+	if prompt.Temperature != nil && *prompt.Temperature == 0 {
+		ctx = client.WithZeroTemperature(ctx)
+	}
 	resp, err := r.client.CreateChatCompletion(
 		ctx,
 		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt.Prompt,
-				},
-			},
+			Model:               model,
+			Messages:            openAIMessages(prompt.Conversation()),
+			Tools:               openAITools(prompt.Tools),
+			ToolChoice:          openAIToolChoice(prompt.ToolChoice),
+			ReasoningEffort:     prompt.ReasoningEffort,
+			ResponseFormat:      openAIResponseFormat(prompt.ResponseFormat),
+			Temperature:         openAITemperature(prompt.Temperature),
+			MaxCompletionTokens: prompt.MaxTokens,
 		},
 	)
-	response := ""
 	if err != nil {
-		return response, fmt.Errorf("error calling OpenAI API: %w", err)
+		return nil, fmt.Errorf("error calling OpenAI API: %w", err)
 	}
 	if len(resp.Choices) == 0 {
-		return response, fmt.Errorf("no response from OpenAI API")
+		return nil, fmt.Errorf("no response from OpenAI API")
+	}
+	var toolCalls []domain.ToolCall
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		toolCalls = append(toolCalls, domain.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return &domain.LLMResponse{
+		Content:      resp.Choices[0].Message.Content,
+		ToolCalls:    toolCalls,
+		Model:        resp.Model,
+		FinishReason: openAIFinishReason(resp.Choices[0].FinishReason),
+		Usage: domain.Usage{
+			InputTokens:  resp.Usage.PromptTokens,
+			OutputTokens: resp.Usage.CompletionTokens,
+			TotalTokens:  resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// openAIMessages translates the conversation into OpenAI chat messages
+func openAIMessages(conversation []domain.Message) []openai.ChatCompletionMessage {
+	messages := make([]openai.ChatCompletionMessage, 0, len(conversation))
+	for _, message := range conversation {
+		chatMessage := openai.ChatCompletionMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			ToolCallID: message.ToolCallID,
+		}
+		if len(message.Images) > 0 {
+			chatMessage.Content = ""
+			chatMessage.MultiContent = openAIContentParts(message)
+		}
+		for _, call := range message.ToolCalls {
+			chatMessage.ToolCalls = append(chatMessage.ToolCalls, openai.ToolCall{
+				ID:   call.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				},
+			})
+		}
+		messages = append(messages, chatMessage)
+	}
+	return messages
+}
+
+// openAIContentParts returns the text and images of a message as OpenAI content parts
+func openAIContentParts(message domain.Message) []openai.ChatMessagePart {
+	parts := make([]openai.ChatMessagePart, 0, len(message.Images)+1)
+	if message.Content != "" {
+		parts = append(parts, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: message.Content})
+	}
+	for _, image := range message.Images {
+		parts = append(parts, openai.ChatMessagePart{
+			Type:     openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{URL: image.Source(), Detail: openai.ImageURLDetailAuto},
+		})
+	}
+	return parts
+}
+
+// openAITools translates the neutral tools into OpenAI function tools
+func openAITools(tools []domain.Tool) []openai.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	openAITools := make([]openai.Tool, 0, len(tools))
+	for _, tool := range tools {
+		openAITools = append(openAITools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  toolParameters(tool),
+			},
+		})
+	}
+	return openAITools
+}
+
+// openAIToolChoice translates the neutral tool choice, a tool name forces that function
+func openAIToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case domain.ToolChoiceAuto, domain.ToolChoiceNone, domain.ToolChoiceRequired:
+		return choice
+	default:
+		return openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: choice},
+		}
+	}
+}
+
+// openAIResponseFormat translates the response format into the OpenAI response format
+func openAIResponseFormat(format *domain.ResponseFormat) *openai.ChatCompletionResponseFormat {
+	if format == nil {
+		return nil
+	}
+	responseFormat := &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatType(format.Type)}
+	if format.Type == domain.ResponseFormatJSONSchema {
+		responseFormat.JSONSchema = &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   format.SchemaName(),
+			Schema: format.Schema,
+			Strict: format.Strict,
+		}
+	}
+	return responseFormat
+}
+
+// openAITemperature converts the requested temperature, a zero temperature is sent by the client with WithZeroTemperature
+func openAITemperature(temperature *float64) float32 {
+	if temperature == nil {
+		return 0
+	}
+	return float32(*temperature)
+}
+
+// openAIFinishReason normalizes the OpenAI finish reason, the deprecated function call is a tool call
+func openAIFinishReason(reason openai.FinishReason) string {
+	switch reason {
+	case openai.FinishReasonLength:
+		return domain.FinishReasonLength
+	case openai.FinishReasonToolCalls, openai.FinishReasonFunctionCall:
+		return domain.FinishReasonToolCalls
+	case openai.FinishReasonContentFilter:
+		return domain.FinishReasonContentFilter
+	default:
+		return domain.FinishReasonStop
 	}
-	return resp.Choices[0].Message.Content, nil
 }
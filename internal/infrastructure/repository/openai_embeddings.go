@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/sashabaranov/go-openai"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"prompthor/internal/infrastructure/client"
+)
+
+// OpenAIEmbeddingRepository implements EmbeddingRepository using the OpenAI embeddings API
+type OpenAIEmbeddingRepository struct {
+	client client.OpenAIClient
+	model  string
+}
+
+// NewOpenAIEmbeddingRepository creates a new instance of the OpenAI embeddings repository
+func NewOpenAIEmbeddingRepository(config config.Config, client client.OpenAIClient) (domain.EmbeddingRepository, error) {
+	return &OpenAIEmbeddingRepository{
+		client: client,
+		model:  config.EmbeddingsModel,
+	}, nil
+}
+
+// Embed returns the embeddings of the inputs
+func (r *OpenAIEmbeddingRepository) Embed(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	model := request.Model
+	if model == "" {
+		model = r.model
+	}
+	resp, err := r.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input:          []string(request.Input),
+		Model:          openai.EmbeddingModel(model),
+		Dimensions:     request.Dimensions,
+		User:           request.User,
+		EncodingFormat: openai.EmbeddingEncodingFormatFloat,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling OpenAI embeddings API: %w", err)
+	}
+	data := make([]domain.Embedding, 0, len(resp.Data))
+	for _, embedding := range resp.Data {
+		data = append(data, domain.Embedding{Object: "embedding", Index: embedding.Index, Embedding: embedding.Embedding})
+	}
+	return &domain.EmbeddingResponse{
+		Object: "list",
+		Data:   data,
+		Model:  string(resp.Model),
+		Usage: domain.EmbeddingUsage{
+			PromptTokens: resp.Usage.PromptTokens,
+			TotalTokens:  resp.Usage.TotalTokens,
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOpenAIEmbeddingRepository_Embed(t *testing.T) {
+	cfg := config.Config{EmbeddingsModel: "text-embedding-3-small"}
+
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+		mockClient.On("CreateEmbeddings", mock.Anything, openai.EmbeddingRequest{
+			Input:          []string{"first", "second"},
+			Model:          "text-embedding-3-small",
+			Dimensions:     256,
+			User:           "user-1",
+			EncodingFormat: openai.EmbeddingEncodingFormatFloat,
+		}).Return(openai.EmbeddingResponse{
+			Object: "list",
+			Data: []openai.Embedding{
+				{Object: "embedding", Index: 0, Embedding: []float32{0.1, 0.2}},
+				{Object: "embedding", Index: 1, Embedding: []float32{0.3, 0.4}},
+			},
+			Model: "text-embedding-3-small",
+			Usage: openai.Usage{PromptTokens: 4, TotalTokens: 4},
+		}, nil)
+		repo, _ := NewOpenAIEmbeddingRepository(cfg, mockClient)
+
+		response, err := repo.Embed(context.Background(), domain.EmbeddingRequest{
+			Input: domain.EmbeddingInput{"first", "second"}, Dimensions: 256, User: "user-1",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.EmbeddingResponse{
+			Object: "list",
+			Data: []domain.Embedding{
+				{Object: "embedding", Index: 0, Embedding: []float32{0.1, 0.2}},
+				{Object: "embedding", Index: 1, Embedding: []float32{0.3, 0.4}},
+			},
+			Model: "text-embedding-3-small",
+			Usage: domain.EmbeddingUsage{PromptTokens: 4, TotalTokens: 4},
+		}, response)
+	})
+
+	t.Run("requested model", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+		mockClient.On("CreateEmbeddings", mock.Anything, mock.MatchedBy(func(request openai.EmbeddingRequest) bool {
+			return request.Model == "text-embedding-3-large"
+		})).Return(openai.EmbeddingResponse{Model: "text-embedding-3-large"}, nil)
+		repo, _ := NewOpenAIEmbeddingRepository(cfg, mockClient)
+
+		response, err := repo.Embed(context.Background(), domain.EmbeddingRequest{
+			Input: domain.EmbeddingInput{"first"}, Model: "text-embedding-3-large",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "text-embedding-3-large", response.Model)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+		mockClient.On("CreateEmbeddings", mock.Anything, mock.Anything).Return(openai.EmbeddingResponse{}, errors.New("rate limited"))
+		repo, _ := NewOpenAIEmbeddingRepository(cfg, mockClient)
+
+		response, err := repo.Embed(context.Background(), domain.EmbeddingRequest{Input: domain.EmbeddingInput{"first"}})
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "error calling OpenAI embeddings API: rate limited")
+	})
+}
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"prompthor/config"
 	"prompthor/internal/domain"
 	"prompthor/internal/infrastructure/client"
 	"strings"
@@ -14,6 +18,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var testOpenAIConfig = config.Config{OpenAIModel: "gpt-4o-mini"}
+
 // MockOpenAIClient is a mock implementation of OpenAIClient for testing
 type MockOpenAIClient struct {
 	mock.Mock
@@ -24,6 +30,11 @@ func (m *MockOpenAIClient) CreateChatCompletion(ctx context.Context, request ope
 	return args.Get(0).(openai.ChatCompletionResponse), args.Error(1)
 }
 
+func (m *MockOpenAIClient) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(openai.EmbeddingResponse), args.Error(1)
+}
+
 // Helper function to create mock OpenAI responses
 func CreateMockOpenAIResponse(content string) openai.ChatCompletionResponse {
 	return openai.ChatCompletionResponse{
@@ -69,7 +80,7 @@ func TestNewOpenAIRepository_Success(t *testing.T) {
 	// Create mock client
 	mockClient := &MockOpenAIClient{}
 
-	repo, err := NewOpenAIRepository(mockClient)
+	repo, err := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, repo)
@@ -78,7 +89,7 @@ func TestNewOpenAIRepository_Success(t *testing.T) {
 
 func TestNewOpenAIRepository_WithNilClient(t *testing.T) {
 	// Test with nil client
-	repo, err := NewOpenAIRepository(nil)
+	repo, err := NewOpenAIRepository(testOpenAIConfig, nil)
 
 	assert.NoError(t, err) // Constructor doesn't validate nil client
 	assert.NotNil(t, repo)
@@ -89,10 +100,10 @@ func TestNewOpenAIClientCreation(t *testing.T) {
 	os.Setenv("OPENAI_API_KEY", "test-api-key")
 	defer os.Unsetenv("OPENAI_API_KEY")
 
-	client := client.NewOpenAIClient("test-api-key")
+	client := client.NewOpenAIClient(config.Config{OpenAIKey: "test-api-key"})
 	assert.NotNil(t, client)
 
-	repo, err := NewOpenAIRepository(client)
+	repo, err := NewOpenAIRepository(testOpenAIConfig, client)
 	assert.NoError(t, err)
 	assert.NotNil(t, repo)
 }
@@ -101,12 +112,96 @@ func TestOpenAIRepository_Structure(t *testing.T) {
 	// Create mock client
 	mockClient := &MockOpenAIClient{}
 
-	repo, err := NewOpenAIRepository(mockClient)
+	repo, err := NewOpenAIRepository(testOpenAIConfig, mockClient)
 	assert.NoError(t, err)
 
 	openAIRepo, ok := repo.(*OpenAIRepository)
 	assert.True(t, ok)
 	assert.NotNil(t, openAIRepo.client)
+	assert.Equal(t, "gpt-4o-mini", openAIRepo.model)
+}
+
+func TestOpenAIRepository_SendMessage_Model(t *testing.T) {
+	tests := []struct {
+		name          string
+		prompt        domain.PromptRequest
+		expectedModel string
+	}{
+		{
+			name:          "configured default model",
+			prompt:        domain.PromptRequest{Prompt: "Hello"},
+			expectedModel: "gpt-4o-mini",
+		},
+		{
+			name:          "per request model override",
+			prompt:        domain.PromptRequest{Prompt: "Hello", Model: "gpt-4o"},
+			expectedModel: "gpt-4o",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockOpenAIClient{}
+			mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(request openai.ChatCompletionRequest) bool {
+				return request.Model == tt.expectedModel
+			})).Return(CreateMockOpenAIResponse("Hi"), nil)
+
+			repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
+			response, err := repo.Send(context.Background(), tt.prompt)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Hi", response.Content)
+			assert.Equal(t, domain.FinishReasonStop, response.FinishReason)
+			assert.Equal(t, domain.Usage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30}, response.Usage)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOpenAIRepository_SendMessage_Parameters(t *testing.T) {
+	temperature := 0.2
+	mockClient := &MockOpenAIClient{}
+	mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(request openai.ChatCompletionRequest) bool {
+		return request.Temperature == float32(0.2) && request.MaxCompletionTokens == 256
+	})).Return(CreateMockOpenAIResponse("Hi"), nil)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello", Temperature: &temperature, MaxTokens: 256})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestOpenAIRepository_SendMessage_ZeroTemperature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.JSONEq(t, `0`, string(request["temperature"]))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-test","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, client.NewOpenAIClient(config.Config{OpenAIKey: "test-key", OpenAIBaseUrl: server.URL}))
+	temperature := 0.0
+
+	_, err := repo.Send(context.Background(), domain.PromptRequest{Prompt: "Hello", Temperature: &temperature})
+
+	assert.NoError(t, err)
+}
+
+func TestOpenAITemperature(t *testing.T) {
+	zero, hot := 0.0, 1.5
+
+	assert.Zero(t, openAITemperature(nil))
+	assert.Zero(t, openAITemperature(&zero))
+	assert.Equal(t, float32(1.5), openAITemperature(&hot))
+}
+
+func TestOpenAIFinishReason(t *testing.T) {
+	assert.Equal(t, domain.FinishReasonStop, openAIFinishReason(openai.FinishReasonStop))
+	assert.Equal(t, domain.FinishReasonStop, openAIFinishReason(""))
+	assert.Equal(t, domain.FinishReasonLength, openAIFinishReason(openai.FinishReasonLength))
+	assert.Equal(t, domain.FinishReasonToolCalls, openAIFinishReason(openai.FinishReasonFunctionCall))
+	assert.Equal(t, domain.FinishReasonContentFilter, openAIFinishReason(openai.FinishReasonContentFilter))
 }
 
 func TestOpenAIRepository_SendMessage_Success(t *testing.T) {
@@ -118,13 +213,13 @@ func TestOpenAIRepository_SendMessage_Success(t *testing.T) {
 	mockClient.On("CreateChatCompletion", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("openai.ChatCompletionRequest")).Return(mockResponse, nil)
 
 	// Create repository with mock client
-	repo, _ := NewOpenAIRepository(mockClient)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	// Test the actual Send method with domain.PromptRequest
 	promptRequest := domain.PromptRequest{Prompt: "Hello world"}
 	response, err := repo.Send(context.Background(), promptRequest)
 	assert.NoError(t, err)
-	assert.Equal(t, "Hello! How can I assist you today?", response)
+	assert.Equal(t, "Hello! How can I assist you today?", response.Content)
 
 	mockClient.AssertExpectations(t)
 }
@@ -136,12 +231,12 @@ func TestOpenAIRepository_SendMessage_APIError(t *testing.T) {
 	mockClient.On("CreateChatCompletion", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("openai.ChatCompletionRequest")).Return(openai.ChatCompletionResponse{}, errors.New("API connection failed"))
 
 	// Create repository with mock client
-	repo, _ := NewOpenAIRepository(mockClient)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	promptRequest := domain.PromptRequest{Prompt: "Hello world"}
 	response, err := repo.Send(context.Background(), promptRequest)
 	assert.Error(t, err)
-	assert.Empty(t, response)
+	assert.Nil(t, response)
 	assert.Contains(t, err.Error(), "error calling OpenAI API")
 
 	mockClient.AssertExpectations(t)
@@ -155,12 +250,12 @@ func TestOpenAIRepository_SendMessage_EmptyResponse(t *testing.T) {
 	mockClient.On("CreateChatCompletion", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("openai.ChatCompletionRequest")).Return(mockResponse, nil)
 
 	// Create repository with mock client
-	repo, _ := NewOpenAIRepository(mockClient)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	promptRequest := domain.PromptRequest{Prompt: "Hello world"}
 	response, err := repo.Send(context.Background(), promptRequest)
 	assert.Error(t, err)
-	assert.Empty(t, response)
+	assert.Nil(t, response)
 	assert.Contains(t, err.Error(), "no response from OpenAI API")
 
 	mockClient.AssertExpectations(t)
@@ -175,13 +270,13 @@ func TestOpenAIRepository_SendMessage_EmptyPrompt(t *testing.T) {
 	mockClient.On("CreateChatCompletion", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("openai.ChatCompletionRequest")).Return(mockResponse, nil)
 
 	// Create repository with mock client
-	repo, _ := NewOpenAIRepository(mockClient)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	// Test with empty prompt
 	promptRequest := domain.PromptRequest{Prompt: ""}
 	response, err := repo.Send(context.Background(), promptRequest)
 	assert.NoError(t, err)
-	assert.Equal(t, "Please provide a prompt.", response)
+	assert.Equal(t, "Please provide a prompt.", response.Content)
 
 	mockClient.AssertExpectations(t)
 }
@@ -195,12 +290,102 @@ func TestOpenAIRepository_SendMessage_LongPrompt(t *testing.T) {
 	mockClient.On("CreateChatCompletion", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("openai.ChatCompletionRequest")).Return(mockResponse, nil)
 
 	// Create repository with mock client
-	repo, _ := NewOpenAIRepository(mockClient)
+	repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
 
 	promptRequest := domain.PromptRequest{Prompt: longPrompt}
 	response, err := repo.Send(context.Background(), promptRequest)
 	assert.NoError(t, err)
-	assert.Equal(t, "Response to long prompt", response)
+	assert.Equal(t, "Response to long prompt", response.Content)
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestOpenAIRepository_SendMessage_Tools(t *testing.T) {
+	t.Run("translates tools and returns tool calls", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+		mockResponse := CreateMockOpenAIResponse("")
+		mockResponse.Choices[0].FinishReason = openai.FinishReasonToolCalls
+		mockResponse.Choices[0].Message.ToolCalls = []openai.ToolCall{{
+			ID:       "call_abc",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}
+		mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(request openai.ChatCompletionRequest) bool {
+			return len(request.Tools) == 1 &&
+				request.Tools[0].Function.Name == "get_weather" &&
+				request.ToolChoice == openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: "get_weather"}}
+		})).Return(mockResponse, nil)
+
+		repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
+		response, err := repo.Send(context.Background(), domain.PromptRequest{
+			Prompt:     "What is the weather in Paris?",
+			Tools:      []domain.Tool{weatherTool},
+			ToolChoice: "get_weather",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FinishReasonToolCalls, response.FinishReason)
+		assert.Equal(t, []domain.ToolCall{{ID: "call_abc", Name: "get_weather", Arguments: `{"city":"Paris"}`}}, response.ToolCalls)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("sends tool results back", func(t *testing.T) {
+		messages := openAIMessages(toolFollowUp.Conversation())
+
+		assert.Equal(t, []openai.ChatCompletionMessage{
+			{Role: "user", Content: "What is the weather in Paris?"},
+			{Role: "assistant", ToolCalls: []openai.ToolCall{{
+				ID:       "call_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}}},
+			{Role: "tool", ToolCallID: "call_1", Content: `{"temperature":18}`},
+		}, messages)
+	})
+}
+
+func TestOpenAIToolChoice(t *testing.T) {
+	assert.Nil(t, openAIToolChoice(""))
+	assert.Equal(t, "required", openAIToolChoice(domain.ToolChoiceRequired))
+	assert.Equal(t, openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: "get_weather"}}, openAIToolChoice("get_weather"))
+}
+
+func TestOpenAIResponseFormat(t *testing.T) {
+	assert.Nil(t, openAIResponseFormat(nil))
+
+	format, err := json.Marshal(openAIResponseFormat(cityFormat))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"json_schema","json_schema":{"name":"response","strict":true,
+		"schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}}`, string(format))
+}
+
+func TestOpenAIRepository_SendMessage_Images(t *testing.T) {
+	t.Run("sends images as content parts", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+		mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(request openai.ChatCompletionRequest) bool {
+			message := request.Messages[0]
+			return message.Content == "" && len(message.MultiContent) == 3 &&
+				message.MultiContent[0].Text == "What is wrong in this screenshot?" &&
+				message.MultiContent[1].ImageURL.URL == "data:image/png;base64,iVBORw0KGgo=" &&
+				message.MultiContent[2].ImageURL.URL == "https://example.com/error.png"
+		})).Return(CreateMockOpenAIResponse("A stack trace."), nil)
+
+		repo, _ := NewOpenAIRepository(config.Config{OpenAIModel: "gpt-4o-mini", VisionModels: []string{"gpt-4o-mini"}}, mockClient)
+		response, err := repo.Send(context.Background(), screenshotRequest(""))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "A stack trace.", response.Content)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("rejects images for text-only models", func(t *testing.T) {
+		mockClient := &MockOpenAIClient{}
+
+		repo, _ := NewOpenAIRepository(testOpenAIConfig, mockClient)
+		_, err := repo.Send(context.Background(), screenshotRequest("gpt-3.5-turbo"))
+
+		var inputErr *domain.InputError
+		assert.ErrorAs(t, err, &inputErr)
+		mockClient.AssertNotCalled(t, "CreateChatCompletion", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"encoding/json"
+	"prompthor/internal/domain"
+)
+
+// ChatResponseFormat is the response format shared by the OpenAI compatible chat completions APIs (Groq, Mistral)
+type ChatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *ChatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ChatJSONSchema is the JSON schema the answer of the model must match
+type ChatJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// chatResponseFormat translates the response format into the chat completions format
+func chatResponseFormat(format *domain.ResponseFormat) *ChatResponseFormat {
+	if format == nil {
+		return nil
+	}
+	responseFormat := &ChatResponseFormat{Type: format.Type}
+	if format.Type == domain.ResponseFormatJSONSchema {
+		responseFormat.JSONSchema = &ChatJSONSchema{
+			Name:   format.SchemaName(),
+			Schema: format.Schema,
+			Strict: format.Strict,
+		}
+	}
+	return responseFormat
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"encoding/json"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var cityFormat = &domain.ResponseFormat{
+	Type:   domain.ResponseFormatJSONSchema,
+	Schema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	Strict: true,
+}
+
+func TestChatResponseFormat(t *testing.T) {
+	assert.Nil(t, chatResponseFormat(nil))
+	assert.Equal(t, &ChatResponseFormat{Type: "json_object"}, chatResponseFormat(&domain.ResponseFormat{Type: domain.ResponseFormatJSONObject}))
+
+	format, err := json.Marshal(chatResponseFormat(cityFormat))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"json_schema","json_schema":{"name":"response","strict":true,
+		"schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}}`, string(format))
+}
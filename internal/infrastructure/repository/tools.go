@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/json"
+	"prompthor/internal/domain"
+)
+
+// emptyParameters is the JSON schema sent for tools declared without parameters
+var emptyParameters = json.RawMessage(`{"type":"object","properties":{}}`)
+
+// FunctionTool is the tool definition shared by the OpenAI compatible APIs (Groq chat completions, Mistral, Cohere)
+type FunctionTool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition is the function of a FunctionTool
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// FunctionToolCall is a tool call in the format shared by the OpenAI compatible APIs
+type FunctionToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function name and JSON encoded arguments of a FunctionToolCall
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// FunctionToolChoice forces the model to call a specific function
+type FunctionToolChoice struct {
+	Type     string             `json:"type"`
+	Function FunctionChoiceName `json:"function"`
+}
+
+// FunctionChoiceName is the name of the function forced by a FunctionToolChoice
+type FunctionChoiceName struct {
+	Name string `json:"name"`
+}
+
+// toolParameters returns the JSON schema of the tool arguments, defaulting to an empty object
+func toolParameters(tool domain.Tool) json.RawMessage {
+	if len(tool.Parameters) == 0 {
+		return emptyParameters
+	}
+	return tool.Parameters
+}
+
+// functionTools translates the neutral tools into function tools
+func functionTools(tools []domain.Tool) []FunctionTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	functions := make([]FunctionTool, 0, len(tools))
+	for _, tool := range tools {
+		functions = append(functions, FunctionTool{
+			Type: "function",
+			Function: FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  toolParameters(tool),
+			},
+		})
+	}
+	return functions
+}
+
+// functionToolChoice translates the neutral tool choice, a tool name forces that function
+func functionToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case domain.ToolChoiceAuto, domain.ToolChoiceNone, domain.ToolChoiceRequired:
+		return choice
+	default:
+		return FunctionToolChoice{
+			Type:     "function",
+			Function: FunctionChoiceName{Name: choice},
+		}
+	}
+}
+
+// functionToolCalls translates the neutral tool calls into function tool calls
+func functionToolCalls(calls []domain.ToolCall) []FunctionToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	functionCalls := make([]FunctionToolCall, 0, len(calls))
+	for _, call := range calls {
+		functionCalls = append(functionCalls, FunctionToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: call.Name, Arguments: call.Arguments},
+		})
+	}
+	return functionCalls
+}
+
+// domainToolCalls translates the function tool calls returned by a provider into neutral tool calls
+func domainToolCalls(functionCalls []FunctionToolCall) []domain.ToolCall {
+	if len(functionCalls) == 0 {
+		return nil
+	}
+	calls := make([]domain.ToolCall, 0, len(functionCalls))
+	for _, call := range functionCalls {
+		calls = append(calls, domain.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return calls
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"encoding/json"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var weatherTool = domain.Tool{
+	Name:        "get_weather",
+	Description: "Gets the current weather of a city",
+	Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+}
+
+// toolFollowUp is a request sending back the result of a tool call requested by the model
+var toolFollowUp = domain.PromptRequest{
+	Messages: []domain.Message{
+		{Role: domain.RoleUser, Content: "What is the weather in Paris?"},
+		{Role: domain.RoleAssistant, ToolCalls: []domain.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		{Role: domain.RoleTool, ToolCallID: "call_1", Content: `{"temperature":18}`},
+	},
+	Tools: []domain.Tool{weatherTool},
+}
+
+func TestFunctionTools(t *testing.T) {
+	t.Run("translates the tools", func(t *testing.T) {
+		tools := functionTools([]domain.Tool{weatherTool, {Name: "current_time"}})
+
+		assert.Equal(t, []FunctionTool{
+			{Type: "function", Function: FunctionDefinition{Name: "get_weather", Description: "Gets the current weather of a city", Parameters: weatherTool.Parameters}},
+			{Type: "function", Function: FunctionDefinition{Name: "current_time", Parameters: emptyParameters}},
+		}, tools)
+	})
+
+	t.Run("no tools", func(t *testing.T) {
+		assert.Nil(t, functionTools(nil))
+	})
+}
+
+func TestFunctionToolChoice(t *testing.T) {
+	assert.Nil(t, functionToolChoice(""))
+	assert.Equal(t, "auto", functionToolChoice(domain.ToolChoiceAuto))
+	assert.Equal(t, "none", functionToolChoice(domain.ToolChoiceNone))
+	assert.Equal(t, "required", functionToolChoice(domain.ToolChoiceRequired))
+
+	choice, err := json.Marshal(functionToolChoice("get_weather"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"function","function":{"name":"get_weather"}}`, string(choice))
+}
+
+func TestFunctionToolCalls(t *testing.T) {
+	calls := []domain.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}
+
+	functionCalls := functionToolCalls(calls)
+
+	assert.Equal(t, []FunctionToolCall{
+		{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+	}, functionCalls)
+	assert.Equal(t, calls, domainToolCalls(functionCalls))
+	assert.Nil(t, functionToolCalls(nil))
+	assert.Nil(t, domainToolCalls(nil))
+}
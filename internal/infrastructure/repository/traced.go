@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"prompthor/internal/domain"
+	"strconv"
+)
+
+// tracerName is the instrumentation scope of the spans of the llm calls
+const tracerName = "prompthor/internal/infrastructure/repository"
+
+// genAIProviders are the names of the providers in the GenAI semantic conventions
+var genAIProviders = map[string]attribute.KeyValue{
+	"openai":  semconv.GenAIProviderNameOpenAI,
+	"mistral": semconv.GenAIProviderNameMistralAI,
+	"cohere":  semconv.GenAIProviderNameCohere,
+	"groq":    semconv.GenAIProviderNameGroq,
+}
+
+// TracedRepository traces the calls of any llm repository following the GenAI semantic conventions
+type TracedRepository struct {
+	next     domain.LLMRepository
+	provider attribute.KeyValue
+	model    string
+}
+
+// NewTracedRepository wraps the repository of the provider, creating a client span for each of its calls.
+// model is the default model of the provider, requested by the calls without a model.
+func NewTracedRepository(next domain.LLMRepository, provider, model string) *TracedRepository {
+	name, ok := genAIProviders[provider]
+	if !ok {
+		name = semconv.GenAIProviderNameKey.String(provider)
+	}
+	return &TracedRepository{
+		next:     next,
+		provider: name,
+		model:    model,
+	}
+}
+
+// Send sends the prompt to the wrapped repository inside a span with the requested model and parameters,
+// the model that answered, its finish reason and the tokens used
+func (r *TracedRepository) Send(ctx context.Context, prompt domain.PromptRequest) (*domain.LLMResponse, error) {
+	name := semconv.GenAIOperationNameChat.Value.AsString()
+	attributes := []attribute.KeyValue{semconv.GenAIOperationNameChat, r.provider}
+	if model := resolveModel(prompt, r.model); model != "" {
+		name += " " + model
+		attributes = append(attributes, semconv.GenAIRequestModel(model))
+	}
+	if prompt.Temperature != nil {
+		attributes = append(attributes, semconv.GenAIRequestTemperature(*prompt.Temperature))
+	}
+	if prompt.MaxTokens > 0 {
+		attributes = append(attributes, semconv.GenAIRequestMaxTokens(prompt.MaxTokens))
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+	defer span.End()
+
+	response, err := r.next.Send(ctx, prompt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorTypeKey.String(errorType(err)))
+		return response, err
+	}
+	if response.Model != "" {
+		span.SetAttributes(semconv.GenAIResponseModel(response.Model))
+	}
+	if response.FinishReason != "" {
+		span.SetAttributes(semconv.GenAIResponseFinishReasons(response.FinishReason))
+	}
+	span.SetAttributes(
+		semconv.GenAIUsageInputTokens(response.Usage.InputTokens),
+		semconv.GenAIUsageOutputTokens(response.Usage.OutputTokens))
+	return response, nil
+}
+
+// errorType describes the error of a failed call, the status code for the errors returned by the provider
+func errorType(err error) string {
+	var providerErr *domain.ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode > 0 {
+		return strconv.Itoa(providerErr.StatusCode)
+	}
+	return semconv.ErrorTypeOther.Value.AsString()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSpanRecorder installs a global tracer provider recording the ended spans, restored when the test ends
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	original := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(original)
+	})
+	return recorder
+}
+
+// spanAttributes returns the attributes of the span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestTracedRepository_Send(t *testing.T) {
+	t.Run("records the request and the answer", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		temperature := 0.2
+		prompt := domain.PromptRequest{Prompt: "Hello", Model: "llama-3.3-70b-versatile", Temperature: &temperature, MaxTokens: 256}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(&domain.LLMResponse{
+			Content:      "Hi",
+			Model:        "llama-3.3-70b-versatile",
+			FinishReason: "stop",
+			Usage:        domain.Usage{InputTokens: 12, OutputTokens: 3, TotalTokens: 15},
+		}, nil)
+
+		_, err := NewTracedRepository(next, "groq", "openai/gpt-oss-20b").Send(context.Background(), prompt)
+
+		require.NoError(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "chat llama-3.3-70b-versatile", spans[0].Name())
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+		attributes := spanAttributes(spans[0])
+		assert.Equal(t, "chat", attributes["gen_ai.operation.name"].AsString())
+		assert.Equal(t, "groq", attributes["gen_ai.provider.name"].AsString())
+		assert.Equal(t, "llama-3.3-70b-versatile", attributes["gen_ai.request.model"].AsString())
+		assert.Equal(t, 0.2, attributes["gen_ai.request.temperature"].AsFloat64())
+		assert.Equal(t, int64(256), attributes["gen_ai.request.max_tokens"].AsInt64())
+		assert.Equal(t, "llama-3.3-70b-versatile", attributes["gen_ai.response.model"].AsString())
+		assert.Equal(t, []string{"stop"}, attributes["gen_ai.response.finish_reasons"].AsStringSlice())
+		assert.Equal(t, int64(12), attributes["gen_ai.usage.input_tokens"].AsInt64())
+		assert.Equal(t, int64(3), attributes["gen_ai.usage.output_tokens"].AsInt64())
+	})
+
+	t.Run("records the provider error", func(t *testing.T) {
+		recorder := newSpanRecorder(t)
+		prompt := domain.PromptRequest{Prompt: "Hello"}
+		next := &MockLLMRepository{}
+		next.On("Send", mock.Anything, prompt).Return(nil, &domain.ProviderError{
+			Provider:   "mistral",
+			StatusCode: http.StatusTooManyRequests,
+			Message:    "rate limit exceeded",
+		})
+
+		_, err := NewTracedRepository(next, "mistral", "mistral-small-latest").Send(context.Background(), prompt)
+
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "chat mistral-small-latest", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		attributes := spanAttributes(spans[0])
+		assert.Equal(t, "mistral_ai", attributes["gen_ai.provider.name"].AsString())
+		assert.Equal(t, "mistral-small-latest", attributes["gen_ai.request.model"].AsString())
+		assert.Equal(t, "429", attributes["error.type"].AsString())
+	})
+
+	t.Run("the provider receives the span context", func(t *testing.T) {
+		newSpanRecorder(t)
+		next := &MockLLMRepository{}
+		next.On("Send", mock.MatchedBy(func(ctx context.Context) bool {
+			return trace.SpanContextFromContext(ctx).IsValid()
+		}), mock.Anything).Return(&domain.LLMResponse{Content: "Hi"}, nil)
+
+		_, err := NewTracedRepository(next, "cohere", "").Send(context.Background(), domain.PromptRequest{Prompt: "Hello"})
+
+		require.NoError(t, err)
+		next.AssertExpectations(t)
+	})
+}
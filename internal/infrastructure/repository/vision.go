@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"fmt"
+	"prompthor/internal/domain"
+)
+
+// ChatContentPart is a part of a multimodal message of the OpenAI compatible chat APIs (Groq, Mistral, Cohere)
+type ChatContentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *ChatImageURL `json:"image_url,omitempty"`
+}
+
+// ChatImageURL is the URL, or data URL, of an image part
+type ChatImageURL struct {
+	URL string `json:"url"`
+}
+
+// chatContentParts returns the text and images of a message as content parts, nil when it has no images
+func chatContentParts(message domain.Message) []ChatContentPart {
+	if len(message.Images) == 0 {
+		return nil
+	}
+	parts := make([]ChatContentPart, 0, len(message.Images)+1)
+	if message.Content != "" {
+		parts = append(parts, ChatContentPart{Type: "text", Text: message.Content})
+	}
+	for _, image := range message.Images {
+		parts = append(parts, ChatContentPart{Type: "image_url", ImageURL: &ChatImageURL{URL: image.Source()}})
+	}
+	return parts
+}
+
+// modelSet returns the models as a set
+func modelSet(models []string) map[string]bool {
+	set := make(map[string]bool, len(models))
+	for _, model := range models {
+		set[model] = true
+	}
+	return set
+}
+
+// checkVision fails when the conversation has images and the model can't read them
+func checkVision(prompt domain.PromptRequest, model string, visionModels map[string]bool) error {
+	if prompt.HasImages() && !visionModels[model] {
+		return &domain.InputError{Message: fmt.Sprintf("model %s does not accept images, use one of the VISION_MODELS", model)}
+	}
+	return nil
+}
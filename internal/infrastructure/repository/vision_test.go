@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var screenshot = domain.Image{Data: "iVBORw0KGgo=", MimeType: "image/png"}
+
+// screenshotRequest asks about an uploaded screenshot with the given model
+func screenshotRequest(model string) domain.PromptRequest {
+	return domain.PromptRequest{
+		Prompt: "What is wrong in this screenshot?",
+		Images: []domain.Image{screenshot, {URL: "https://example.com/error.png"}},
+		Model:  model,
+	}
+}
+
+func TestChatContentParts(t *testing.T) {
+	assert.Nil(t, chatContentParts(domain.Message{Role: domain.RoleUser, Content: "Hello"}))
+
+	parts := chatContentParts(screenshotRequest("").Conversation()[0])
+
+	assert.Equal(t, []ChatContentPart{
+		{Type: "text", Text: "What is wrong in this screenshot?"},
+		{Type: "image_url", ImageURL: &ChatImageURL{URL: "data:image/png;base64,iVBORw0KGgo="}},
+		{Type: "image_url", ImageURL: &ChatImageURL{URL: "https://example.com/error.png"}},
+	}, parts)
+}
+
+func TestCheckVision(t *testing.T) {
+	visionModels := modelSet([]string{"gpt-4o-mini"})
+
+	assert.NoError(t, checkVision(domain.PromptRequest{Prompt: "Hello"}, "gpt-3.5-turbo", visionModels))
+	assert.NoError(t, checkVision(screenshotRequest(""), "gpt-4o-mini", visionModels))
+
+	err := checkVision(screenshotRequest(""), "gpt-3.5-turbo", visionModels)
+	var inputErr *domain.InputError
+	assert.ErrorAs(t, err, &inputErr)
+	assert.EqualError(t, err, "model gpt-3.5-turbo does not accept images, use one of the VISION_MODELS")
+}
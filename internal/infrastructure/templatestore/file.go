@@ -0,0 +1,278 @@
+package templatestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// currentFile is the file of a template directory holding its current version
+const currentFile = "current"
+
+// versionFile are the file names of the template versions, eg: v3.json
+var versionFile = regexp.MustCompile(`^v([1-9][0-9]*)\.json$`)
+
+// FileStore keeps the templates in memory.
+// When it has a directory the templates are loaded from it on start and the new versions saved to it,
+// every template in its own directory with a v<version>.json file per version and a current file with its current version.
+type FileStore struct {
+	mu        sync.RWMutex
+	dir       string
+	templates map[string][]domain.Template
+	current   map[string]int
+}
+
+// NewFileStore creates a template store, loading the templates saved in dir when it is not empty
+func NewFileStore(dir string) (*FileStore, error) {
+	store := &FileStore{
+		dir:       dir,
+		templates: make(map[string][]domain.Template),
+		current:   make(map[string]int),
+	}
+	if dir == "" {
+		return store, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open templates directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := store.load(entry.Name()); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// load reads the versions and the current version of a template from its directory
+func (s *FileStore) load(name string) error {
+	dir := filepath.Join(s.dir, name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open template %s: %w", name, err)
+	}
+	var versions []domain.Template
+	for _, entry := range entries {
+		match := versionFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		template, err := readVersion(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		template.Name = name
+		template.Version = version
+		versions = append(versions, *template)
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	s.templates[name] = versions
+	s.current[name] = versions[len(versions)-1].Version
+
+	data, err := os.ReadFile(filepath.Join(dir, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read current version of template %s: %w", name, err)
+	}
+	current, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || find(versions, current) == nil {
+		return fmt.Errorf("template %s has an unknown current version %q", name, strings.TrimSpace(string(data)))
+	}
+	s.current[name] = current
+	return nil
+}
+
+// readVersion reads a template version file
+func readVersion(path string) (*domain.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+	var template domain.Template
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	if template.Body == "" {
+		return nil, fmt.Errorf("template %s has no body", path)
+	}
+	if template.Role == "" {
+		template.Role = domain.RoleUser
+	}
+	if template.Variables == nil {
+		template.Variables = []string{}
+	}
+	if template.CreatedAt.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			template.CreatedAt = info.ModTime().UTC()
+		}
+	}
+	return &template, nil
+}
+
+// Create stores the template as the next version of its name and makes it the current version
+func (s *FileStore) Create(ctx context.Context, template domain.Template) (*domain.Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.templates[template.Name]
+	template.Version = 1
+	if len(versions) > 0 {
+		template.Version = versions[len(versions)-1].Version + 1
+	}
+	template.CreatedAt = time.Now().UTC()
+	if err := s.saveVersion(template); err != nil {
+		return nil, err
+	}
+	// the saved version exists even when the current alias can't be moved to it
+	s.templates[template.Name] = append(versions, template)
+	if err := s.saveCurrent(template.Name, template.Version); err != nil {
+		return nil, err
+	}
+	s.current[template.Name] = template.Version
+	return &template, nil
+}
+
+// Get returns a version of the template, its current version when version is 0
+func (s *FileStore) Get(ctx context.Context, name string, version int) (*domain.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if version == 0 {
+		version = s.current[name]
+	}
+	template := find(s.templates[name], version)
+	if template == nil {
+		return nil, domain.ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// Versions returns the versions of the template, the oldest first
+func (s *FileStore) Versions(ctx context.Context, name string) ([]domain.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, ok := s.templates[name]
+	if !ok {
+		return nil, domain.ErrTemplateNotFound
+	}
+	return append([]domain.Template(nil), versions...), nil
+}
+
+// List returns the stored templates sorted by name
+func (s *FileStore) List(ctx context.Context) ([]domain.TemplateInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]domain.TemplateInfo, 0, len(s.templates))
+	for name, versions := range s.templates {
+		templates = append(templates, domain.TemplateInfo{
+			Name:    name,
+			Current: s.current[name],
+			Latest:  versions[len(versions)-1].Version,
+		})
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// SetCurrent points the current alias of the template to one of its versions
+func (s *FileStore) SetCurrent(ctx context.Context, name string, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if find(s.templates[name], version) == nil {
+		return domain.ErrTemplateNotFound
+	}
+	if err := s.saveCurrent(name, version); err != nil {
+		return err
+	}
+	s.current[name] = version
+	return nil
+}
+
+// saveVersion writes a new version file, failing when the version already exists so versions are never modified
+func (s *FileStore) saveVersion(template domain.Template) error {
+	if s.dir == "" {
+		return nil
+	}
+	dir := filepath.Join(s.dir, template.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to save template %s: %w", template.Name, err)
+	}
+	data, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save template %s: %w", template.Name, err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("v%d.json", template.Version))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to save template %s: %w", template.Name, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to save template %s: %w", template.Name, err)
+	}
+	return file.Close()
+}
+
+// saveCurrent writes the current version of the template, replacing the file atomically
+func (s *FileStore) saveCurrent(name string, version int) error {
+	if s.dir == "" {
+		return nil
+	}
+	path := filepath.Join(s.dir, name, currentFile)
+	file, err := os.CreateTemp(filepath.Dir(path), currentFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save current version of template %s: %w", name, err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(strconv.Itoa(version) + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save current version of template %s: %w", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save current version of template %s: %w", name, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to save current version of template %s: %w", name, err)
+	}
+	return nil
+}
+
+// find returns the version of the template, nil when it does not exist
+func find(versions []domain.Template, version int) *domain.Template {
+	for _, template := range versions {
+		if template.Version == version {
+			return &template
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package templatestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileStore_LoadsDirectory(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join("testdata", "templates"))
+	require.NoError(t, err)
+
+	t.Run("lists the templates", func(t *testing.T) {
+		templates, err := store.List(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.TemplateInfo{
+			{Name: "summary", Current: 1, Latest: 1},
+			{Name: "support", Current: 1, Latest: 2},
+		}, templates)
+	})
+
+	t.Run("current alias", func(t *testing.T) {
+		template, err := store.Get(ctx, "support", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Template{
+			Name:      "support",
+			Version:   1,
+			Role:      domain.RoleSystem,
+			Body:      "You are the support bot of {{.company}}.",
+			Variables: []string{"company"},
+			CreatedAt: time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC),
+		}, template)
+	})
+
+	t.Run("role defaults to user", func(t *testing.T) {
+		template, err := store.Get(ctx, "summary", 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleUser, template.Role)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := store.Get(ctx, "support", 3)
+
+		assert.ErrorIs(t, err, domain.ErrTemplateNotFound)
+	})
+}
+
+func TestNewFileStore_Errors(t *testing.T) {
+	t.Run("missing directory is empty", func(t *testing.T) {
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "missing"))
+
+		assert.NoError(t, err)
+		templates, _ := store.List(context.Background())
+		assert.Empty(t, templates)
+	})
+
+	t.Run("template without body", func(t *testing.T) {
+		_, err := NewFileStore(filepath.Join("testdata", "invalid"))
+
+		assert.ErrorContains(t, err, "has no body")
+	})
+
+	t.Run("unknown current version", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "greeting"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "greeting", "v1.json"), []byte(`{"body": "Hi"}`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "greeting", "current"), []byte("4\n"), 0o644))
+
+		_, err := NewFileStore(dir)
+
+		assert.EqualError(t, err, `template greeting has an unknown current version "4"`)
+	})
+}
+
+func TestFileStore_CreateAndRollback(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	first, err := store.Create(ctx, domain.Template{Name: "greeting", Role: domain.RoleUser, Body: "Hi {{.name}}", Variables: []string{"name"}})
+	require.NoError(t, err)
+	second, err := store.Create(ctx, domain.Template{Name: "greeting", Role: domain.RoleUser, Body: "Hello {{.name}}", Variables: []string{"name"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, 2, second.Version)
+
+	current, err := store.Get(ctx, "greeting", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello {{.name}}", current.Body)
+
+	require.NoError(t, store.SetCurrent(ctx, "greeting", 1))
+	assert.ErrorIs(t, store.SetCurrent(ctx, "greeting", 5), domain.ErrTemplateNotFound)
+
+	t.Run("saved versions and alias are loaded", func(t *testing.T) {
+		reloaded, err := NewFileStore(dir)
+		require.NoError(t, err)
+
+		current, err := reloaded.Get(ctx, "greeting", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hi {{.name}}", current.Body)
+		versions, err := reloaded.Versions(ctx, "greeting")
+		assert.NoError(t, err)
+		assert.Len(t, versions, 2)
+	})
+
+	t.Run("versions are never overwritten", func(t *testing.T) {
+		assert.Error(t, store.saveVersion(*first))
+	})
+}
+
+func TestFileStore_Versions_Unknown(t *testing.T) {
+	store, err := NewFileStore("")
+	require.NoError(t, err)
+
+	_, err = store.Versions(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, domain.ErrTemplateNotFound)
+}
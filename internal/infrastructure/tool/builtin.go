@@ -0,0 +1,24 @@
+package tool
+
+import (
+	"fmt"
+	"prompthor/config"
+	"prompthor/internal/domain"
+	"time"
+)
+
+// httpFetchTimeout is the timeout of a single HTTP fetch
+const httpFetchTimeout = 10 * time.Second
+
+// Builtin creates the built-in tool with the given name
+func Builtin(name string, config config.Config) (domain.ToolExecutor, error) {
+	switch name {
+	case HTTPFetchName:
+		return NewHTTPFetch(config.HTTPFetchAllowedHosts, httpFetchTimeout), nil
+	case CalculatorName:
+		return NewCalculator(), nil
+	case CurrentTimeName:
+		return NewCurrentTime(), nil
+	}
+	return nil, fmt.Errorf("unknown built-in tool %q", name)
+}
@@ -0,0 +1,21 @@
+package tool
+
+import (
+	"prompthor/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltin(t *testing.T) {
+	cfg := config.Config{HTTPFetchAllowedHosts: []string{"api.example.com"}}
+
+	for _, name := range []string{HTTPFetchName, CalculatorName, CurrentTimeName} {
+		tool, err := Builtin(name, cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, name, tool.Definition().Name)
+	}
+
+	_, err := Builtin("shell", cfg)
+	assert.EqualError(t, err, `unknown built-in tool "shell"`)
+}
@@ -0,0 +1,112 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"prompthor/internal/domain"
+	"strconv"
+)
+
+// CalculatorName is the name of the calculator tool
+const CalculatorName = "calculator"
+
+// CalculatorArguments are the arguments of the calculator tool
+type CalculatorArguments struct {
+	Expression string `json:"expression"`
+}
+
+// Calculator evaluates arithmetic expressions with + - * / % and parentheses
+type Calculator struct{}
+
+// NewCalculator creates the calculator tool
+func NewCalculator() *Calculator {
+	return &Calculator{}
+}
+
+// Definition implements domain.ToolExecutor
+func (c *Calculator) Definition() domain.Tool {
+	return domain.Tool{
+		Name:        CalculatorName,
+		Description: "Evaluates an arithmetic expression with + - * / % and parentheses, eg: (2 + 3) * 4.5",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"expression":{"type":"string","description":"The arithmetic expression"}},"required":["expression"]}`),
+	}
+}
+
+// Execute implements domain.ToolExecutor
+func (c *Calculator) Execute(ctx context.Context, arguments string) (string, error) {
+	var args CalculatorArguments
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid calculator arguments: %w", err)
+	}
+	expression, err := parser.ParseExpr(args.Expression)
+	if err != nil {
+		return "", fmt.Errorf("invalid expression %q: %w", args.Expression, err)
+	}
+	result, err := evaluate(expression)
+	if err != nil {
+		return "", err
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return "", fmt.Errorf("expression %q has no finite result", args.Expression)
+	}
+	return strconv.FormatFloat(result, 'g', -1, 64), nil
+}
+
+// evaluate computes the value of an arithmetic expression tree
+func evaluate(expression ast.Expr) (float64, error) {
+	switch node := expression.(type) {
+	case *ast.BasicLit:
+		if node.Kind != token.INT && node.Kind != token.FLOAT {
+			return 0, fmt.Errorf("unsupported literal %s", node.Value)
+		}
+		return strconv.ParseFloat(node.Value, 64)
+	case *ast.ParenExpr:
+		return evaluate(node.X)
+	case *ast.UnaryExpr:
+		value, err := evaluate(node.X)
+		if err != nil {
+			return 0, err
+		}
+		switch node.Op {
+		case token.ADD:
+			return value, nil
+		case token.SUB:
+			return -value, nil
+		}
+		return 0, fmt.Errorf("unsupported operator %s", node.Op)
+	case *ast.BinaryExpr:
+		left, err := evaluate(node.X)
+		if err != nil {
+			return 0, err
+		}
+		right, err := evaluate(node.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch node.Op {
+		case token.ADD:
+			return left + right, nil
+		case token.SUB:
+			return left - right, nil
+		case token.MUL:
+			return left * right, nil
+		case token.QUO:
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return left / right, nil
+		case token.REM:
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return math.Mod(left, right), nil
+		}
+		return 0, fmt.Errorf("unsupported operator %s", node.Op)
+	}
+	return 0, fmt.Errorf("unsupported expression")
+}
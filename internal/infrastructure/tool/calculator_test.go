@@ -0,0 +1,45 @@
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculator_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		err        string
+	}{
+		{name: "precedence", expression: "2 + 3 * 4", expected: "14"},
+		{name: "parentheses and decimals", expression: "(2 + 3) * 4.5", expected: "22.5"},
+		{name: "unary minus", expression: "-3 - -2", expected: "-1"},
+		{name: "remainder", expression: "10 % 4", expected: "2"},
+		{name: "division by zero", expression: "1 / 0", err: "division by zero"},
+		{name: "identifiers", expression: "x + 1", err: "unsupported expression"},
+		{name: "function calls", expression: "os.Exit(1)", err: "unsupported expression"},
+		{name: "invalid syntax", expression: "2 +", err: `invalid expression "2 +"`},
+	}
+	calculator := NewCalculator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Execute(context.Background(), `{"expression":"`+tt.expression+`"}`)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCalculator_Execute_InvalidArguments(t *testing.T) {
+	_, err := NewCalculator().Execute(context.Background(), "not json")
+
+	assert.ErrorContains(t, err, "invalid calculator arguments")
+}
@@ -0,0 +1,55 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prompthor/internal/domain"
+	"time"
+)
+
+// CurrentTimeName is the name of the current time tool
+const CurrentTimeName = "current_time"
+
+// CurrentTimeArguments are the arguments of the current time tool
+type CurrentTimeArguments struct {
+	Timezone string `json:"timezone"`
+}
+
+// CurrentTime returns the current date and time, models don't know it otherwise
+type CurrentTime struct {
+	now func() time.Time
+}
+
+// NewCurrentTime creates the current time tool
+func NewCurrentTime() *CurrentTime {
+	return &CurrentTime{now: time.Now}
+}
+
+// Definition implements domain.ToolExecutor
+func (c *CurrentTime) Definition() domain.Tool {
+	return domain.Tool{
+		Name:        CurrentTimeName,
+		Description: "Returns the current date and time in RFC 3339 format",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"timezone":{"type":"string","description":"IANA time zone, eg: Europe/Paris. Defaults to UTC"}}}`),
+	}
+}
+
+// Execute implements domain.ToolExecutor
+func (c *CurrentTime) Execute(ctx context.Context, arguments string) (string, error) {
+	var args CurrentTimeArguments
+	if arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid current_time arguments: %w", err)
+		}
+	}
+	location := time.UTC
+	if args.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(args.Timezone); err != nil {
+			return "", fmt.Errorf("unknown timezone %q", args.Timezone)
+		}
+	}
+	now := c.now().In(location)
+	return fmt.Sprintf("%s (%s)", now.Format(time.RFC3339), now.Weekday()), nil
+}
@@ -0,0 +1,42 @@
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentTime_Execute(t *testing.T) {
+	tool := &CurrentTime{now: func() time.Time {
+		return time.Date(2025, 3, 14, 12, 30, 0, 0, time.UTC)
+	}}
+
+	t.Run("defaults to UTC", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), "{}")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-14T12:30:00Z (Friday)", result)
+	})
+
+	t.Run("empty arguments", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-14T12:30:00Z (Friday)", result)
+	})
+
+	t.Run("timezone", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), `{"timezone":"America/Argentina/Buenos_Aires"}`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-14T09:30:00-03:00 (Friday)", result)
+	})
+
+	t.Run("unknown timezone", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), `{"timezone":"Mars/Olympus"}`)
+
+		assert.EqualError(t, err, `unknown timezone "Mars/Olympus"`)
+	})
+}
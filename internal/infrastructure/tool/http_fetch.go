@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"prompthor/internal/domain"
+	"strings"
+	"time"
+)
+
+// HTTPFetchName is the name of the HTTP fetch tool
+const HTTPFetchName = "http_fetch"
+
+// httpFetchMaxBytes is the maximum size of the body returned to the model
+const httpFetchMaxBytes = 64 * 1024
+
+// HTTPFetchArguments are the arguments of the HTTP fetch tool
+type HTTPFetchArguments struct {
+	URL string `json:"url"`
+}
+
+// HTTPFetch gets the content of a URL. Only the hosts of the allow-list can be fetched, redirects included.
+type HTTPFetch struct {
+	client       *http.Client
+	allowedHosts map[string]bool
+}
+
+// NewHTTPFetch creates the HTTP fetch tool for the allowed hosts
+func NewHTTPFetch(allowedHosts []string, timeout time.Duration) *HTTPFetch {
+	tool := &HTTPFetch{allowedHosts: make(map[string]bool, len(allowedHosts))}
+	for _, host := range allowedHosts {
+		tool.allowedHosts[strings.ToLower(host)] = true
+	}
+	tool.client = &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return tool.checkURL(req.URL)
+		},
+	}
+	return tool
+}
+
+// Definition implements domain.ToolExecutor
+func (h *HTTPFetch) Definition() domain.Tool {
+	return domain.Tool{
+		Name:        HTTPFetchName,
+		Description: "Gets the content of a web page or API with an HTTP GET request",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"url":{"type":"string","description":"The http or https URL to fetch"}},"required":["url"]}`),
+	}
+}
+
+// Execute implements domain.ToolExecutor
+func (h *HTTPFetch) Execute(ctx context.Context, arguments string) (string, error) {
+	var args HTTPFetchArguments
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid http_fetch arguments: %w", err)
+	}
+	target, err := url.Parse(args.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", args.URL, err)
+	}
+	if err := h.checkURL(target); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpFetchMaxBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	return fmt.Sprintf("status: %d\n\n%s", resp.StatusCode, body), nil
+}
+
+// checkURL fails for non http urls and hosts missing from the allow-list
+func (h *HTTPFetch) checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q", target.Scheme)
+	}
+	if !h.allowedHosts[strings.ToLower(target.Hostname())] {
+		return fmt.Errorf("host %q is not allowed", target.Hostname())
+	}
+	return nil
+}
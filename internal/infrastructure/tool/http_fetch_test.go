@@ -0,0 +1,60 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPFetch_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", httpFetchMaxBytes+10)))
+		default:
+			assert.Equal(t, http.MethodGet, r.Method)
+			w.Write([]byte(`{"temperature":18}`))
+		}
+	}))
+	defer server.Close()
+	tool := NewHTTPFetch([]string{"127.0.0.1"}, time.Second)
+
+	t.Run("fetches an allowed host", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), `{"url":"`+server.URL+`/weather"}`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "status: 200\n\n{\"temperature\":18}", result)
+	})
+
+	t.Run("truncates large bodies", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), `{"url":"`+server.URL+`/large"}`)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, len("status: 200\n\n")+httpFetchMaxBytes)
+	})
+
+	t.Run("rejects hosts outside the allow-list", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), `{"url":"http://localhost/"}`)
+
+		assert.EqualError(t, err, `host "localhost" is not allowed`)
+	})
+
+	t.Run("rejects redirects outside the allow-list", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), `{"url":"`+server.URL+`/redirect"}`)
+
+		assert.ErrorContains(t, err, `host "example.com" is not allowed`)
+	})
+
+	t.Run("rejects other schemes", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), `{"url":"file:///etc/passwd"}`)
+
+		assert.EqualError(t, err, `unsupported url scheme "file"`)
+	})
+}
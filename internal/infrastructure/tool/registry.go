@@ -0,0 +1,60 @@
+package tool
+
+import (
+	"fmt"
+	"prompthor/internal/domain"
+	"sync"
+)
+
+// Registry is an in-memory catalog of the tools executed by prompthor
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]domain.ToolExecutor
+	names []string
+}
+
+// NewRegistry creates a registry with the given tools
+func NewRegistry(tools ...domain.ToolExecutor) (*Registry, error) {
+	registry := &Registry{tools: make(map[string]domain.ToolExecutor, len(tools))}
+	for _, tool := range tools {
+		if err := registry.Register(tool); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register adds a tool to the registry, tool names must be unique
+func (r *Registry) Register(tool domain.ToolExecutor) error {
+	name := tool.Definition().Name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; exists {
+		return fmt.Errorf("tool %q is already registered", name)
+	}
+	r.tools[name] = tool
+	r.names = append(r.names, name)
+	return nil
+}
+
+// Tools returns the definitions of the registered tools, in registration order
+func (r *Registry) Tools() []domain.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]domain.Tool, 0, len(r.names))
+	for _, name := range r.names {
+		tools = append(tools, r.tools[name].Definition())
+	}
+	return tools
+}
+
+// Get returns the tool registered with the given name
+func (r *Registry) Get(name string) (domain.ToolExecutor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	return tool, ok
+}
@@ -0,0 +1,32 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("registers tools in order", func(t *testing.T) {
+		registry, err := NewRegistry(NewCalculator(), NewCurrentTime())
+		assert.NoError(t, err)
+
+		tools := registry.Tools()
+		assert.Len(t, tools, 2)
+		assert.Equal(t, CalculatorName, tools[0].Name)
+		assert.Equal(t, CurrentTimeName, tools[1].Name)
+
+		tool, ok := registry.Get(CurrentTimeName)
+		assert.True(t, ok)
+		assert.IsType(t, &CurrentTime{}, tool)
+
+		_, ok = registry.Get("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects duplicated names", func(t *testing.T) {
+		_, err := NewRegistry(NewCalculator(), NewCalculator())
+
+		assert.EqualError(t, err, `tool "calculator" is already registered`)
+	})
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// serviceName is the name of the service in the spans, OTEL_SERVICE_NAME overrides it
+const serviceName = "prompthor"
+
+// Setup installs the W3C trace context propagator and, unless exporter is none, a global tracer provider sending the
+// spans to the exporter: otlp, configured by the OTEL_EXPORTER_OTLP_* variables, or stdout for local development.
+// The returned function flushes the pending spans and stops the provider.
+func Setup(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var processor sdktrace.TracerProviderOption
+	switch exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		spanExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		processor = sdktrace.WithBatcher(spanExporter)
+	case "stdout":
+		spanExporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		// spans are printed as they end, so they show up next to the logs of the request
+		processor = sdktrace.WithSyncer(spanExporter)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, use none, otlp or stdout", exporter)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(processor, sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetup(t *testing.T) {
+	original := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(original)
+	})
+
+	t.Run("none only installs the propagator", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), "none")
+
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+		assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+		assert.Equal(t, original, otel.GetTracerProvider())
+	})
+
+	t.Run("stdout installs a tracer provider", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), "stdout")
+
+		require.NoError(t, err)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("otlp installs a tracer provider", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+
+		shutdown, err := Setup(context.Background(), "otlp")
+
+		require.NoError(t, err)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), "zipkin")
+
+		assert.EqualError(t, err, `unknown tracing exporter "zipkin", use none, otlp or stdout`)
+	})
+}
@@ -0,0 +1,196 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-process vector index searched by cosine similarity.
+// When it has a path the collections are saved to that file after every change and loaded on start.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	path        string
+	collections map[string][]domain.Chunk
+}
+
+// NewMemoryStore creates a vector store, loading the collections saved in path when it is not empty
+func NewMemoryStore(path string) (*MemoryStore, error) {
+	store := &MemoryStore{path: path, collections: make(map[string][]domain.Chunk)}
+	if path == "" {
+		return store, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector store %s: %w", path, err)
+	}
+	defer file.Close()
+	if err := gob.NewDecoder(file).Decode(&store.collections); err != nil {
+		return nil, fmt.Errorf("failed to load vector store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Add stores the chunks in the collection, replacing the chunks previously stored for the same files.
+// Chunks with another dimension than the ones kept in the collection are rejected, the collection couldn't be searched.
+func (s *MemoryStore) Add(ctx context.Context, collection string, chunks []domain.Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := make(map[string]bool)
+	for _, chunk := range chunks {
+		files[chunk.File] = true
+	}
+	kept := make([]domain.Chunk, 0, len(s.collections[collection])+len(chunks))
+	for _, chunk := range s.collections[collection] {
+		if !files[chunk.File] {
+			kept = append(kept, chunk)
+		}
+	}
+	dimensions := -1
+	if len(kept) > 0 {
+		dimensions = len(kept[0].Vector)
+	}
+	for _, chunk := range chunks {
+		if dimensions == -1 {
+			dimensions = len(chunk.Vector)
+		}
+		if len(chunk.Vector) != dimensions {
+			return &domain.InputError{Message: fmt.Sprintf(
+				"chunk of %s has %d dimensions, collection %s has %d: ingest it into a new collection after changing the embeddings",
+				chunk.File, len(chunk.Vector), collection, dimensions)}
+		}
+	}
+	for _, chunk := range chunks {
+		chunk.Vector = normalize(chunk.Vector)
+		kept = append(kept, chunk)
+	}
+	previous := s.collections[collection]
+	s.collections[collection] = kept
+	if err := s.save(); err != nil {
+		s.collections[collection] = previous
+		return err
+	}
+	return nil
+}
+
+// Search returns the k chunks of the collection most similar to the vector, the most similar first
+func (s *MemoryStore) Search(ctx context.Context, collection string, vector []float32, k int) ([]domain.ScoredChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chunks, ok := s.collections[collection]
+	if !ok {
+		return nil, domain.ErrCollectionNotFound
+	}
+	query := normalize(vector)
+	scored := make([]domain.ScoredChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if len(chunk.Vector) != len(query) {
+			return nil, fmt.Errorf("query has %d dimensions, collection %s has %d", len(query), collection, len(chunk.Vector))
+		}
+		scored = append(scored, domain.ScoredChunk{Chunk: chunk, Score: dot(query, chunk.Vector)})
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if k > 0 && len(scored) > k {
+		scored = scored[:k]
+	}
+	return scored, nil
+}
+
+// Collections returns the stored collections sorted by name
+func (s *MemoryStore) Collections(ctx context.Context) ([]domain.Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	collections := make([]domain.Collection, 0, len(s.collections))
+	for name, chunks := range s.collections {
+		files := make(map[string]bool)
+		for _, chunk := range chunks {
+			files[chunk.File] = true
+		}
+		collections = append(collections, domain.Collection{Name: name, Documents: len(files), Chunks: len(chunks)})
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+	return collections, nil
+}
+
+// Delete removes a collection
+func (s *MemoryStore) Delete(ctx context.Context, collection string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunks, ok := s.collections[collection]
+	if !ok {
+		return domain.ErrCollectionNotFound
+	}
+	delete(s.collections, collection)
+	if err := s.save(); err != nil {
+		s.collections[collection] = chunks
+		return err
+	}
+	return nil
+}
+
+// save writes the collections to the store file, replacing it atomically
+func (s *MemoryStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if err := gob.NewEncoder(file).Encode(s.collections); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	if err := os.Rename(file.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	return nil
+}
+
+// normalize returns the vector scaled to unit length, so the cosine similarity is the dot product
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	norm = math.Sqrt(norm)
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	for i, value := range vector {
+		normalized[i] = float32(float64(value) / norm)
+	}
+	return normalized
+}
+
+// dot returns the dot product of two vectors of the same length
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,131 @@
+package vectorstore
+
+import (
+	"context"
+	"path/filepath"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Search(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemoryStore("")
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{
+		{File: "a.md", Text: "east", Vector: []float32{1, 0}},
+		{File: "b.md", Text: "north", Vector: []float32{0, 2}},
+		{File: "c.md", Text: "north east", Vector: []float32{3, 3}},
+	}))
+
+	t.Run("closest chunks first", func(t *testing.T) {
+		found, err := store.Search(ctx, "docs", []float32{0, 5}, 2)
+
+		assert.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, "north", found[0].Text)
+		assert.InDelta(t, 1, found[0].Score, 1e-6)
+		assert.Equal(t, "north east", found[1].Text)
+		assert.InDelta(t, 0.7071, found[1].Score, 1e-4)
+	})
+
+	t.Run("unknown collection", func(t *testing.T) {
+		_, err := store.Search(ctx, "missing", []float32{0, 1}, 2)
+
+		assert.ErrorIs(t, err, domain.ErrCollectionNotFound)
+	})
+
+	t.Run("dimensions mismatch", func(t *testing.T) {
+		_, err := store.Search(ctx, "docs", []float32{0, 1, 0}, 2)
+
+		assert.EqualError(t, err, "query has 3 dimensions, collection docs has 2")
+	})
+}
+
+func TestMemoryStore_Add_ReplacesFiles(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemoryStore("")
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{
+		{File: "a.md", Text: "old", Vector: []float32{1, 0}},
+		{File: "b.md", Text: "kept", Vector: []float32{0, 1}},
+	}))
+
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{
+		{File: "a.md", Text: "new 1", Vector: []float32{1, 0}},
+		{File: "a.md", Text: "new 2", Vector: []float32{1, 1}},
+	}))
+
+	collections, err := store.Collections(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Collection{{Name: "docs", Documents: 2, Chunks: 3}}, collections)
+	found, err := store.Search(ctx, "docs", []float32{1, 0}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "new 1", found[0].Text)
+}
+
+func TestMemoryStore_Add_RejectsOtherDimensions(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemoryStore("")
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{
+		{File: "a.md", Text: "east", Vector: []float32{1, 0}},
+		{File: "b.md", Text: "north", Vector: []float32{0, 1}},
+	}))
+
+	err = store.Add(ctx, "docs", []domain.Chunk{{File: "c.md", Text: "up", Vector: []float32{0, 0, 1}}})
+
+	var inputErr *domain.InputError
+	require.ErrorAs(t, err, &inputErr)
+	assert.EqualError(t, err, "chunk of c.md has 3 dimensions, collection docs has 2: ingest it into a new collection after changing the embeddings")
+	found, err := store.Search(ctx, "docs", []float32{1, 0}, 3)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	err = store.Add(ctx, "other", []domain.Chunk{
+		{File: "a.md", Text: "east", Vector: []float32{1, 0}},
+		{File: "a.md", Text: "up", Vector: []float32{0, 0, 1}},
+	})
+	assert.ErrorAs(t, err, &inputErr)
+
+	require.NoError(t, store.Add(ctx, "single", []domain.Chunk{{File: "a.md", Text: "east", Vector: []float32{1, 0}}}))
+	assert.NoError(t, store.Add(ctx, "single", []domain.Chunk{{File: "a.md", Text: "up", Vector: []float32{0, 0, 1}}}),
+		"replacing every chunk of the collection changes its dimension")
+}
+
+func TestMemoryStore_Delete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemoryStore("")
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{{File: "a.md", Text: "a", Vector: []float32{1}}}))
+
+	assert.NoError(t, store.Delete(ctx, "docs"))
+	assert.ErrorIs(t, store.Delete(ctx, "docs"), domain.ErrCollectionNotFound)
+	collections, err := store.Collections(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, collections)
+}
+
+func TestMemoryStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vectors.gob")
+	store, err := NewMemoryStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, "docs", []domain.Chunk{{File: "a.md", Page: 2, Text: "a", Vector: []float32{3, 4}}}))
+
+	reloaded, err := NewMemoryStore(path)
+
+	require.NoError(t, err)
+	found, err := reloaded.Search(ctx, "docs", []float32{3, 4}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Chunk{File: "a.md", Page: 2, Text: "a", Vector: []float32{0.6, 0.8}}, found[0].Chunk)
+}
+
+func TestNewMemoryStore_InvalidFile(t *testing.T) {
+	store, err := NewMemoryStore("testdata/invalid.gob")
+
+	assert.Nil(t, store)
+	assert.Error(t, err)
+}
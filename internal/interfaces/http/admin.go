@@ -0,0 +1,34 @@
+package http
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/internal/domain"
+	"strings"
+)
+
+// adminMiddleware rejects the administration requests without the admin token of the active configuration as bearer
+// token, read on every request. The routes are not found while the configuration has no admin token, and every request
+// is rejected while the token can't be read.
+func adminMiddleware(configUseCase domain.ConfigUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := configUseCase.AdminToken(c.Request.Context())
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read the admin token")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Admin token unavailable"})
+			return
+		}
+		if token == "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
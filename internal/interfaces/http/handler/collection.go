@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// CollectionHandler handles HTTP requests managing the document collections
+type CollectionHandler struct {
+	usecase        domain.CollectionUseCase
+	maxUploadBytes int64
+}
+
+// NewCollectionHandler creates a new instance of the collection controller, maxUploadBytes limits the size of the uploads
+func NewCollectionHandler(collectionUseCase domain.CollectionUseCase, maxUploadBytes int64) *CollectionHandler {
+	return &CollectionHandler{
+		usecase:        collectionUseCase,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// HandleIngest processes the POST multipart request adding the files to a collection
+func (h *CollectionHandler) HandleIngest(c *gin.Context) {
+	limitUpload(c, h.maxUploadBytes)
+	files, err := uploadedFiles(c)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	collection, err := h.usecase.Ingest(c.Request.Context(), c.Param("name"), files)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, collection)
+}
+
+// HandleList processes the GET request listing the collections
+func (h *CollectionHandler) HandleList(c *gin.Context) {
+	collections, err := h.usecase.Collections(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"collections": collections})
+}
+
+// HandleDelete processes the DELETE request removing a collection
+func (h *CollectionHandler) HandleDelete(c *gin.Context) {
+	name := c.Param("name")
+	err := h.usecase.Delete(c.Request.Context(), name)
+	if errors.Is(err, domain.ErrCollectionNotFound) {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msgf("collection %s not found", name)
+		respondJSONError(c, http.StatusNotFound, gin.H{
+			"error": "Collection not found: " + name,
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCollectionUseCase is a mock implementation of CollectionUseCase
+type MockCollectionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCollectionUseCase) Retrieve(ctx context.Context, collection, query string, k int) ([]domain.ScoredChunk, error) {
+	args := m.Called(collection, query, k)
+	return args.Get(0).([]domain.ScoredChunk), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Ingest(ctx context.Context, collection string, files []domain.File) (*domain.Collection, error) {
+	args := m.Called(collection, files)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Collection), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Collections(ctx context.Context) ([]domain.Collection, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Collection), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Delete(ctx context.Context, collection string) error {
+	return m.Called(collection).Error(0)
+}
+
+func setupCollectionRouter(useCase domain.CollectionUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	collectionHandler := NewCollectionHandler(useCase, 1024)
+	router.GET("/collections", collectionHandler.HandleList)
+	router.POST("/collections/:name/documents", collectionHandler.HandleIngest)
+	router.DELETE("/collections/:name", collectionHandler.HandleDelete)
+	return router
+}
+
+// ingestRequest builds an ingestion request for the collection with the given files
+func ingestRequest(t *testing.T, collection string, files map[string]string) *http.Request {
+	req := multipartRequest(t, nil, files)
+	req.URL.Path = "/collections/" + collection + "/documents"
+	return req
+}
+
+func TestCollectionHandler_HandleIngest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUseCase := &MockCollectionUseCase{}
+		mockUseCase.On("Ingest", "handbook",
+			[]domain.File{{Name: "policies.md", ContentType: "application/octet-stream", Data: []byte("Vacation is 20 days.")}},
+		).Return(&domain.Collection{Name: "handbook", Documents: 1, Chunks: 1}, nil)
+		router := setupCollectionRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, ingestRequest(t, "handbook", map[string]string{"policies.md": "Vacation is 20 days."}))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"name":"handbook","documents":1,"chunks":1}`, w.Body.String())
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		mockUseCase := &MockCollectionUseCase{}
+		router := setupCollectionRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, ingestRequest(t, "handbook", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"Invalid request format: at least one file is required in the files field"}`, w.Body.String())
+	})
+
+	t.Run("upload too large", func(t *testing.T) {
+		router := setupCollectionRouter(&MockCollectionUseCase{})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, ingestRequest(t, "handbook", map[string]string{"policies.md": strings.Repeat("a", 2048)}))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("invalid collection", func(t *testing.T) {
+		mockUseCase := &MockCollectionUseCase{}
+		mockUseCase.On("Ingest", "hand.book", mock.Anything).
+			Return(nil, &domain.InputError{Message: `invalid collection name "hand.book", use up to 64 letters, digits, - or _`})
+		router := setupCollectionRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, ingestRequest(t, "hand.book", map[string]string{"policies.md": "text"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid collection name")
+	})
+}
+
+func TestCollectionHandler_HandleList(t *testing.T) {
+	mockUseCase := &MockCollectionUseCase{}
+	mockUseCase.On("Collections").Return([]domain.Collection{{Name: "handbook", Documents: 2, Chunks: 7}}, nil)
+	router := setupCollectionRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/collections", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"collections":[{"name":"handbook","documents":2,"chunks":7}]}`, w.Body.String())
+}
+
+func TestCollectionHandler_HandleDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "deleted", err: nil, expected: http.StatusNoContent},
+		{name: "not found", err: domain.ErrCollectionNotFound, expected: http.StatusNotFound},
+		{name: "store error", err: errors.New("disk full"), expected: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := &MockCollectionUseCase{}
+			mockUseCase.On("Delete", "handbook").Return(tt.err)
+			router := setupCollectionRouter(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/collections/handbook", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// ConfigHandler handles the administration requests about the configuration
+type ConfigHandler struct {
+	usecase domain.ConfigUseCase
+}
+
+// NewConfigHandler creates a new instance of the configuration controller
+func NewConfigHandler(configUseCase domain.ConfigUseCase) *ConfigHandler {
+	return &ConfigHandler{
+		usecase: configUseCase,
+	}
+}
+
+// HandleVersion processes the GET request returning the version of the active configuration
+func (h *ConfigHandler) HandleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, h.usecase.Version(c.Request.Context()))
+}
+
+// HandleReload processes the POST request reloading the configuration, with 422 and the version still active when
+// the new configuration is rejected
+func (h *ConfigHandler) HandleReload(c *gin.Context) {
+	ctx := c.Request.Context()
+	version, err := h.usecase.Reload(ctx)
+	if errors.Is(err, domain.ErrInvalidConfig) {
+		log.Ctx(ctx).Error().Err(err).Msg("configuration reload rejected")
+		respondJSONError(c, http.StatusUnprocessableEntity, gin.H{
+			"error":   err.Error(),
+			"version": version,
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, version)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockConfigUseCase is a mock implementation of ConfigUseCase
+type MockConfigUseCase struct {
+	mock.Mock
+}
+
+func (m *MockConfigUseCase) Version(ctx context.Context) domain.ConfigVersion {
+	return m.Called().Get(0).(domain.ConfigVersion)
+}
+
+func (m *MockConfigUseCase) Reload(ctx context.Context) (domain.ConfigVersion, error) {
+	args := m.Called()
+	return args.Get(0).(domain.ConfigVersion), args.Error(1)
+}
+
+func (m *MockConfigUseCase) AdminToken(ctx context.Context) (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func setupConfigRouter(useCase domain.ConfigUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	configHandler := NewConfigHandler(useCase)
+	router.GET("/admin/config", configHandler.HandleVersion)
+	router.POST("/admin/config/reload", configHandler.HandleReload)
+	return router
+}
+
+func TestConfigHandler_HandleVersion(t *testing.T) {
+	mockUseCase := &MockConfigUseCase{}
+	mockUseCase.On("Version").Return(domain.ConfigVersion{
+		Version:  3,
+		Checksum: "9f86d081884c",
+		Source:   "prompthor.yaml",
+		LoadedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	router := setupConfigRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/config", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"version":3,"checksum":"9f86d081884c","source":"prompthor.yaml","loaded_at":"2025-01-02T03:04:05Z"}`,
+		w.Body.String())
+}
+
+func TestConfigHandler_HandleReload(t *testing.T) {
+	loadedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		version  domain.ConfigVersion
+		err      error
+		expected int
+		body     string
+	}{
+		{
+			name:     "applied",
+			version:  domain.ConfigVersion{Version: 2, Checksum: "def", LoadedAt: loadedAt},
+			expected: http.StatusOK,
+			body:     `{"version":2,"checksum":"def","loaded_at":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name:     "rejected",
+			version:  domain.ConfigVersion{Version: 1, Checksum: "abc", LoadedAt: loadedAt},
+			err:      fmt.Errorf("%w: %w", domain.ErrInvalidConfig, errors.New("PORT: 0 is out of range")),
+			expected: http.StatusUnprocessableEntity,
+			body: `{"error":"invalid configuration: PORT: 0 is out of range",
+				"version":{"version":1,"checksum":"abc","loaded_at":"2025-01-02T03:04:05Z"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := &MockConfigUseCase{}
+			mockUseCase.On("Reload").Return(tt.version, tt.err)
+			router := setupConfigRouter(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/admin/config/reload", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// AskWithFilesRequest is the form of the ask with files request, the files are sent in the files field
+type AskWithFilesRequest struct {
+	Prompt          string `form:"prompt" binding:"required"`
+	Model           string `form:"model"`
+	ReasoningEffort string `form:"reasoning_effort" binding:"omitempty,oneof=low medium high"`
+}
+
+// DocumentHandler handles HTTP requests with uploaded files
+type DocumentHandler struct {
+	usecase        domain.DocumentUseCase
+	maxUploadBytes int64
+}
+
+// NewDocumentHandler creates a new instance of the document controller, maxUploadBytes limits the size of the request
+func NewDocumentHandler(documentUseCase domain.DocumentUseCase, maxUploadBytes int64) *DocumentHandler {
+	return &DocumentHandler{
+		usecase:        documentUseCase,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// HandleAskWithFiles processes the POST multipart request with a prompt and the files to answer it with
+func (h *DocumentHandler) HandleAskWithFiles(c *gin.Context) {
+	limitUpload(c, h.maxUploadBytes)
+	var request AskWithFilesRequest
+	if err := c.ShouldBind(&request); err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	files, err := uploadedFiles(c)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	response, err := h.usecase.AskWithFiles(c.Request.Context(), domain.PromptRequest{
+		Prompt:          request.Prompt,
+		Model:           request.Model,
+		ReasoningEffort: request.ReasoningEffort,
+	}, files)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// errNoFiles is returned when a multipart request has no files
+var errNoFiles = errors.New("at least one file is required in the files field")
+
+// limitUpload limits the size of the request body, no limit when maxUploadBytes is zero
+func limitUpload(c *gin.Context, maxUploadBytes int64) {
+	if maxUploadBytes > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	}
+}
+
+// uploadedFiles reads the files sent in the files field of the multipart request
+func uploadedFiles(c *gin.Context) ([]domain.File, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		return nil, errNoFiles
+	}
+	files := make([]domain.File, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file %s: %w", header.Filename, err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", header.Filename, err)
+		}
+		files = append(files, domain.File{
+			Name:        header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Data:        data,
+		})
+	}
+	return files, nil
+}
+
+// respondUploadError sends the error of an invalid multipart request, 413 when it is larger than the limit
+func respondUploadError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		log.Ctx(ctx).Error().Err(err).Msg("upload too large")
+		respondJSONError(c, http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Upload larger than %d bytes", maxBytesErr.Limit),
+		})
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+	respondJSONError(c, http.StatusBadRequest, gin.H{
+		"error": "Invalid request format: " + err.Error(),
+	})
+}
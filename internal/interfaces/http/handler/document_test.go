@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDocumentUseCase is a mock implementation of DocumentUseCase
+type MockDocumentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockDocumentUseCase) AskWithFiles(ctx context.Context, prompt domain.PromptRequest, files []domain.File) (*domain.ChatResponse, error) {
+	args := m.Called(prompt, files)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChatResponse), args.Error(1)
+}
+
+// multipartRequest builds an ask with files request with the given form fields and files
+func multipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	req, _ := http.NewRequest("POST", "/ask-with-files", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func setupDocumentRouter(useCase domain.DocumentUseCase, maxUploadBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/ask-with-files", NewDocumentHandler(useCase, maxUploadBytes).HandleAskWithFiles)
+	return router
+}
+
+func TestDocumentHandler_HandleAskWithFiles(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		mockUseCase.On("AskWithFiles",
+			domain.PromptRequest{Prompt: "When is the launch?", Model: "gpt-4o"},
+			[]domain.File{{Name: "notes.md", ContentType: "application/octet-stream", Data: []byte("Launch on friday.")}},
+		).Return(&domain.ChatResponse{
+			Response: "On friday [1].",
+			Sources:  []domain.Source{{ID: 1, File: "notes.md"}},
+		}, nil)
+		router := setupDocumentRouter(mockUseCase, 1024)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t,
+			map[string]string{"prompt": "When is the launch?", "model": "gpt-4o"},
+			map[string]string{"notes.md": "Launch on friday."}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"response":"On friday [1].","sources":[{"id":1,"file":"notes.md"}]}`, w.Body.String())
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("missing prompt", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		router := setupDocumentRouter(mockUseCase, 1024)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t, nil, map[string]string{"notes.md": "Launch on friday."}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid request format")
+		mockUseCase.AssertNotCalled(t, "AskWithFiles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		router := setupDocumentRouter(mockUseCase, 1024)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t, map[string]string{"prompt": "Summarize"}, nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid request format: at least one file is required in the files field", response["error"])
+	})
+
+	t.Run("upload too large", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		router := setupDocumentRouter(mockUseCase, 64)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t,
+			map[string]string{"prompt": "Summarize"},
+			map[string]string{"notes.md": strings.Repeat("a", 1024)}))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "Upload larger than 64 bytes")
+	})
+
+	t.Run("unsupported file", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		mockUseCase.On("AskWithFiles", mock.Anything, mock.Anything).
+			Return(nil, &domain.InputError{Message: "file photo.png is not a PDF, text, markdown or CSV file"})
+		router := setupDocumentRouter(mockUseCase, 1024)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t,
+			map[string]string{"prompt": "Summarize"},
+			map[string]string{"photo.png": "png"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"Invalid request: file photo.png is not a PDF, text, markdown or CSV file"}`, w.Body.String())
+	})
+
+	t.Run("use case error", func(t *testing.T) {
+		mockUseCase := &MockDocumentUseCase{}
+		mockUseCase.On("AskWithFiles", mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
+		router := setupDocumentRouter(mockUseCase, 1024)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, multipartRequest(t,
+			map[string]string{"prompt": "Summarize"},
+			map[string]string{"notes.md": "Launch on friday."}))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Error processing chat: API error")
+	})
+}
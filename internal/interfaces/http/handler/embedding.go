@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"math"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// base64EmbeddingResponse is the embeddings response with the vectors encoded as base64 little endian float32 arrays
+type base64EmbeddingResponse struct {
+	Object string                `json:"object"`
+	Data   []base64Embedding     `json:"data"`
+	Model  string                `json:"model"`
+	Usage  domain.EmbeddingUsage `json:"usage"`
+}
+
+// base64Embedding is an embedding encoded as base64
+type base64Embedding struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding string `json:"embedding"`
+}
+
+// EmbeddingHandler handles HTTP requests related to embeddings
+type EmbeddingHandler struct {
+	usecase domain.EmbeddingUseCase
+}
+
+// NewEmbeddingHandler creates a new instance of the embeddings controller
+func NewEmbeddingHandler(embeddingUseCase domain.EmbeddingUseCase) *EmbeddingHandler {
+	return &EmbeddingHandler{
+		usecase: embeddingUseCase,
+	}
+}
+
+// HandleEmbeddings processes the POST embeddings request
+func (h *EmbeddingHandler) HandleEmbeddings(c *gin.Context) {
+	var request domain.EmbeddingRequest
+
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+		respondJSONError(c, http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+	response, err := h.usecase.CreateEmbeddings(ctx, request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if request.EncodingFormat == domain.EncodingFormatBase64 {
+		c.JSON(http.StatusOK, encodeEmbeddings(response))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// encodeEmbeddings encodes the vectors of the response as base64, as the OpenAI embeddings API does
+func encodeEmbeddings(response *domain.EmbeddingResponse) base64EmbeddingResponse {
+	encoded := base64EmbeddingResponse{
+		Object: response.Object,
+		Data:   make([]base64Embedding, 0, len(response.Data)),
+		Model:  response.Model,
+		Usage:  response.Usage,
+	}
+	for _, embedding := range response.Data {
+		raw := make([]byte, 4*len(embedding.Embedding))
+		for i, value := range embedding.Embedding {
+			binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(value))
+		}
+		encoded.Data = append(encoded.Data, base64Embedding{
+			Object:    embedding.Object,
+			Index:     embedding.Index,
+			Embedding: base64.StdEncoding.EncodeToString(raw),
+		})
+	}
+	return encoded
+}
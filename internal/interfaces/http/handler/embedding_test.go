@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmbeddingUseCase is a mock implementation of EmbeddingUseCase
+type MockEmbeddingUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingUseCase) CreateEmbeddings(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmbeddingResponse), args.Error(1)
+}
+
+func setupEmbeddingRouter(useCase domain.EmbeddingUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/embeddings", NewEmbeddingHandler(useCase).HandleEmbeddings)
+	return router
+}
+
+func embeddingResponse() *domain.EmbeddingResponse {
+	return &domain.EmbeddingResponse{
+		Object: "list",
+		Data:   []domain.Embedding{{Object: "embedding", Index: 0, Embedding: []float32{1, -2}}},
+		Model:  "text-embedding-3-small",
+		Usage:  domain.EmbeddingUsage{PromptTokens: 2, TotalTokens: 2},
+	}
+}
+
+func TestEmbeddingHandler_HandleEmbeddings(t *testing.T) {
+	t.Run("array input", func(t *testing.T) {
+		mockUseCase := &MockEmbeddingUseCase{}
+		mockUseCase.On("CreateEmbeddings", domain.EmbeddingRequest{Input: domain.EmbeddingInput{"hello", "world"}, Dimensions: 2}).
+			Return(embeddingResponse(), nil)
+		router := setupEmbeddingRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/embeddings", bytes.NewBufferString(`{"input":["hello","world"],"dimensions":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"object":"list",
+			"data":[{"object":"embedding","index":0,"embedding":[1,-2]}],
+			"model":"text-embedding-3-small",
+			"usage":{"prompt_tokens":2,"total_tokens":2}
+		}`, w.Body.String())
+	})
+
+	t.Run("string input as base64", func(t *testing.T) {
+		mockUseCase := &MockEmbeddingUseCase{}
+		mockUseCase.On("CreateEmbeddings", domain.EmbeddingRequest{Input: domain.EmbeddingInput{"hello"}, EncodingFormat: "base64"}).
+			Return(embeddingResponse(), nil)
+		router := setupEmbeddingRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/embeddings", bytes.NewBufferString(`{"input":"hello","encoding_format":"base64"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// 1.0 and -2.0 as little endian float32
+		assert.JSONEq(t, `{
+			"object":"list",
+			"data":[{"object":"embedding","index":0,"embedding":"AACAPwAAAMA="}],
+			"model":"text-embedding-3-small",
+			"usage":{"prompt_tokens":2,"total_tokens":2}
+		}`, w.Body.String())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		for _, body := range []string{`{"input":42}`, `{"input":[]}`, `{"input":["a"],"encoding_format":"int8"}`} {
+			router := setupEmbeddingRouter(&MockEmbeddingUseCase{})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/embeddings", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("use case error", func(t *testing.T) {
+		mockUseCase := &MockEmbeddingUseCase{}
+		mockUseCase.On("CreateEmbeddings", mock.Anything).Return(nil, errors.New("provider down"))
+		router := setupEmbeddingRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/embeddings", bytes.NewBufferString(`{"input":"hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
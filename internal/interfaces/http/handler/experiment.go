@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// ExperimentHandler handles HTTP requests about the experiments
+type ExperimentHandler struct {
+	usecase domain.ExperimentUseCase
+}
+
+// NewExperimentHandler creates a new instance of the experiment controller
+func NewExperimentHandler(experimentUseCase domain.ExperimentUseCase) *ExperimentHandler {
+	return &ExperimentHandler{
+		usecase: experimentUseCase,
+	}
+}
+
+// HandleStats processes the GET request returning the statistics of the variants of the experiments
+func (h *ExperimentHandler) HandleStats(c *gin.Context) {
+	experiments, err := h.usecase.Stats(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"experiments": experiments})
+}
+
+// HandleFeedback processes the POST request rating an answer of a variant
+func (h *ExperimentHandler) HandleFeedback(c *gin.Context) {
+	var request domain.Feedback
+
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+		respondJSONError(c, http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+	name := c.Param("name")
+	err := h.usecase.Feedback(ctx, name, request)
+	if errors.Is(err, domain.ErrExperimentNotFound) {
+		log.Ctx(ctx).Error().Err(err).Msgf("variant %s of experiment %s not found", request.Variant, name)
+		respondJSONError(c, http.StatusNotFound, gin.H{
+			"error": "Experiment variant not found: " + name + "/" + request.Variant,
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
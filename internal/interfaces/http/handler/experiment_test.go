@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExperimentUseCase is a mock implementation of ExperimentUseCase
+type MockExperimentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockExperimentUseCase) ProcessChat(ctx context.Context, prompt domain.PromptRequest) (*domain.ChatResponse, error) {
+	args := m.Called(prompt)
+	return args.Get(0).(*domain.ChatResponse), args.Error(1)
+}
+
+func (m *MockExperimentUseCase) Stats(ctx context.Context) ([]domain.ExperimentStats, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.ExperimentStats), args.Error(1)
+}
+
+func (m *MockExperimentUseCase) Feedback(ctx context.Context, experiment string, feedback domain.Feedback) error {
+	return m.Called(experiment, feedback).Error(0)
+}
+
+func setupExperimentRouter(useCase domain.ExperimentUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	experimentHandler := NewExperimentHandler(useCase)
+	router.GET("/experiments", experimentHandler.HandleStats)
+	router.POST("/experiments/:name/feedback", experimentHandler.HandleFeedback)
+	return router
+}
+
+func TestExperimentHandler_HandleStats(t *testing.T) {
+	mockUseCase := &MockExperimentUseCase{}
+	mockUseCase.On("Stats").Return([]domain.ExperimentStats{{
+		Name:     "support-prompt",
+		Variants: []domain.VariantStats{{Name: "control", Weight: 80, Requests: 3, CostUSD: 0.01}},
+	}}, nil)
+	router := setupExperimentRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/experiments", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"experiments":[{"name":"support-prompt","variants":[{"name":"control","weight":80,"requests":3,
+		"errors":0,"avg_latency_ms":0,"p95_latency_ms":0,"input_tokens":0,"output_tokens":0,"cost_usd":0.01,
+		"feedback":0,"avg_score":0}]}]}`, w.Body.String())
+}
+
+func TestExperimentHandler_HandleFeedback(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		expected int
+	}{
+		{name: "recorded", body: `{"variant":"control","score":1}`, expected: http.StatusNoContent},
+		{name: "zero score", body: `{"variant":"control","score":0}`, expected: http.StatusNoContent},
+		{name: "unknown variant", body: `{"variant":"missing","score":1}`, err: domain.ErrExperimentNotFound, expected: http.StatusNotFound},
+		{name: "score out of range", body: `{"variant":"control","score":5}`, expected: http.StatusBadRequest},
+		{name: "missing score", body: `{"variant":"control"}`, expected: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := &MockExperimentUseCase{}
+			mockUseCase.On("Feedback", "support-prompt", mock.Anything).Return(tt.err)
+			router := setupExperimentRouter(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/experiments/support-prompt/feedback", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
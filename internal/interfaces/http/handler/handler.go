@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"net/http"
@@ -26,18 +27,49 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	ctx := c.Request.Context()
 	if err := c.ShouldBindJSON(&request); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
-		c.JSON(http.StatusBadRequest, gin.H{
+		respondJSONError(c, http.StatusBadRequest, gin.H{
 			"error": "Invalid request format: " + err.Error(),
 		})
 		return
 	}
 	response, err := h.usecase.ProcessChat(ctx, request)
 	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("error process chat")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Error processing chat: " + err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// respondError sends the error of a use case with the status matching its type
+func respondError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	var inputErr *domain.InputError
+	if errors.As(err, &inputErr) {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+		respondJSONError(c, http.StatusBadRequest, gin.H{
+			"error": "Invalid request: " + inputErr.Error(),
+		})
+		return
+	}
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid structured output")
+		respondJSONError(c, http.StatusUnprocessableEntity, gin.H{
+			"error":  validationErr.Error(),
+			"output": validationErr.Output,
+		})
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msg("error process chat")
+	respondJSONError(c, http.StatusInternalServerError, gin.H{
+		"error": "Error processing chat: " + err.Error(),
+	})
+}
+
+// respondJSONError writes an error response with the id of the request, so the clients can report it
+func respondJSONError(c *gin.Context, status int, body gin.H) {
+	if requestID := domain.RequestIDsFrom(c.Request.Context()).RequestID; requestID != "" {
+		body["request_id"] = requestID
+	}
+	c.JSON(status, body)
+}
@@ -108,6 +108,35 @@ func TestChatHandler_HandleChat_UseCaseError(t *testing.T) {
 	mockUseCase.AssertExpectations(t)
 }
 
+func TestChatHandler_HandleChat_ErrorWithRequestID(t *testing.T) {
+	mockUseCase := &MockChatUseCase{}
+	handler := NewChatHandler(mockUseCase)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ctx := domain.WithRequestIDs(c.Request.Context(), domain.RequestIDs{RequestID: "test-request-id"})
+		c.Request = c.Request.WithContext(ctx)
+	})
+	router.POST("/chat", handler.HandleChat)
+
+	mockUseCase.On("ProcessChat", mock.Anything, mock.Anything).Return((*domain.ChatResponse)(nil), errors.New("API connection failed"))
+
+	req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(`{"prompt":"Test prompt"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response map[string]string
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-request-id", response["request_id"])
+	assert.Contains(t, response["error"], "API connection failed")
+}
+
 func TestChatHandler_HandleChat_InvalidJSON(t *testing.T) {
 	mockUseCase := &MockChatUseCase{}
 	handler := NewChatHandler(mockUseCase)
@@ -239,3 +268,235 @@ func TestChatHandler_HandleChat_WithHeaders(t *testing.T) {
 
 	mockUseCase.AssertExpectations(t)
 }
+
+func TestChatHandler_HandleChat_InvalidReasoningEffort(t *testing.T) {
+	mockUseCase := &MockChatUseCase{}
+	handler := NewChatHandler(mockUseCase)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/chat", handler.HandleChat)
+
+	req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(`{"prompt":"Hello","reasoning_effort":"extreme"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUseCase.AssertNotCalled(t, "ProcessChat")
+}
+
+func TestChatHandler_HandleChat_Messages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("messages without prompt", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		request := domain.PromptRequest{
+			Messages: []domain.Message{
+				{Role: domain.RoleUser, Content: "What is the weather in Paris?"},
+				{Role: domain.RoleAssistant, ToolCalls: []domain.ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+				{Role: domain.RoleTool, ToolCallID: "call_1", Content: `{"temperature":18}`},
+			},
+			Tools: []domain.Tool{{Name: "get_weather"}},
+		}
+		mockUseCase.On("ProcessChat", context.Background(), request).Return(&domain.ChatResponse{Response: "It is 18 degrees in Paris."}, nil)
+
+		requestBody, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	invalid := map[string]string{
+		"neither prompt nor messages": `{"tools":[{"name":"get_weather"}]}`,
+		"invalid message role":        `{"messages":[{"role":"robot","content":"Hello"}]}`,
+		"tool without name":           `{"prompt":"Hello","tools":[{"description":"no name"}]}`,
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockUseCase := &MockChatUseCase{}
+			handler := NewChatHandler(mockUseCase)
+			router := gin.New()
+			router.POST("/chat", handler.HandleChat)
+
+			req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockUseCase.AssertNotCalled(t, "ProcessChat")
+		})
+	}
+}
+
+func TestChatHandler_HandleChat_StructuredOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("invalid structured output", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		validationErr := &domain.ValidationError{Message: "the answer is not valid JSON", Output: "Ada Lovelace"}
+		mockUseCase.On("ProcessChat", context.Background(), mock.Anything).Return((*domain.ChatResponse)(nil), validationErr)
+
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(`{"prompt":"Who?","response_format":{"type":"json_object"}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "structured output validation failed: the answer is not valid JSON", response["error"])
+		assert.Equal(t, "Ada Lovelace", response["output"])
+	})
+
+	invalid := map[string]string{
+		"unknown type":       `{"prompt":"Who?","response_format":{"type":"xml"}}`,
+		"schema is required": `{"prompt":"Who?","response_format":{"type":"json_schema"}}`,
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockUseCase := &MockChatUseCase{}
+			handler := NewChatHandler(mockUseCase)
+			router := gin.New()
+			router.POST("/chat", handler.HandleChat)
+
+			req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockUseCase.AssertNotCalled(t, "ProcessChat")
+		})
+	}
+}
+
+func TestChatHandler_HandleChat_Images(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("images without prompt", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		request := domain.PromptRequest{Images: []domain.Image{{Data: "iVBORw0KGgo=", MimeType: "image/png"}}}
+		mockUseCase.On("ProcessChat", context.Background(), request).Return(&domain.ChatResponse{Response: "A login form."}, nil)
+
+		requestBody, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("model without vision", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		inputErr := &domain.InputError{Message: "model llama-3.1-8b-instant does not accept images"}
+		mockUseCase.On("ProcessChat", context.Background(), mock.Anything).Return((*domain.ChatResponse)(nil), inputErr)
+
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(`{"prompt":"What is this?","images":[{"url":"https://example.com/screenshot.png"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid request: model llama-3.1-8b-instant does not accept images", response["error"])
+	})
+
+	invalid := map[string]string{
+		"unsupported mime type": `{"prompt":"What is this?","images":[{"data":"iVBORw0KGgo=","mime_type":"application/pdf"}]}`,
+		"missing mime type":     `{"prompt":"What is this?","images":[{"data":"iVBORw0KGgo="}]}`,
+		"invalid base64":        `{"prompt":"What is this?","images":[{"data":"not base64!","mime_type":"image/png"}]}`,
+		"non http url":          `{"prompt":"What is this?","images":[{"url":"file:///etc/passwd"}]}`,
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockUseCase := &MockChatUseCase{}
+			handler := NewChatHandler(mockUseCase)
+			router := gin.New()
+			router.POST("/chat", handler.HandleChat)
+
+			req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockUseCase.AssertNotCalled(t, "ProcessChat")
+		})
+	}
+}
+
+func TestChatHandler_HandleChat_Template(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("template without prompt", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		request := domain.PromptRequest{Template: "summary", TemplateVersion: 2, Variables: map[string]any{"text": "a long text"}}
+		mockUseCase.On("ProcessChat", context.Background(), request).Return(&domain.ChatResponse{Response: "A text."}, nil)
+
+		requestBody, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("missing variables", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		handler := NewChatHandler(mockUseCase)
+		router := gin.New()
+		router.POST("/chat", handler.HandleChat)
+
+		inputErr := &domain.InputError{Message: "missing variables for template summary: text"}
+		mockUseCase.On("ProcessChat", context.Background(), mock.Anything).Return((*domain.ChatResponse)(nil), inputErr)
+
+		req, _ := http.NewRequest("POST", "/chat", bytes.NewBufferString(`{"template":"summary"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing variables for template summary: text")
+	})
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"prompthor/internal/domain"
+)
+
+// HealthHandler handles the probes of the API
+type HealthHandler struct {
+	usecase domain.HealthUseCase
+}
+
+// NewHealthHandler creates a new instance of the health controller
+func NewHealthHandler(healthUseCase domain.HealthUseCase) *HealthHandler {
+	return &HealthHandler{
+		usecase: healthUseCase,
+	}
+}
+
+// HandleLiveness processes the GET request of the liveness probe, the API is alive as long as it answers
+func (h *HealthHandler) HandleLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "OK"})
+}
+
+// HandleReadiness processes the GET request of the readiness probe returning the status of every dependency,
+// with 503 when a required one is down
+func (h *HealthHandler) HandleReadiness(c *gin.Context) {
+	readiness := h.usecase.Readiness(c.Request.Context())
+	status := http.StatusOK
+	if readiness.Status != domain.StatusReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, readiness)
+}
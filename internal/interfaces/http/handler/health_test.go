@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHealthUseCase is a mock implementation of HealthUseCase
+type MockHealthUseCase struct {
+	mock.Mock
+}
+
+func (m *MockHealthUseCase) Readiness(ctx context.Context) domain.Readiness {
+	return m.Called().Get(0).(domain.Readiness)
+}
+
+func setupHealthRouter(useCase domain.HealthUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	healthHandler := NewHealthHandler(useCase)
+	router.GET("/livez", healthHandler.HandleLiveness)
+	router.GET("/readyz", healthHandler.HandleReadiness)
+	return router
+}
+
+func TestHealthHandler_HandleLiveness(t *testing.T) {
+	router := setupHealthRouter(&MockHealthUseCase{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/livez", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, w.Body.String())
+}
+
+func TestHealthHandler_HandleReadiness(t *testing.T) {
+	checkedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		readiness domain.Readiness
+		expected  int
+		body      string
+	}{
+		{
+			name: "ready",
+			readiness: domain.Readiness{Status: domain.StatusReady, Dependencies: []domain.DependencyStatus{
+				{Name: "openai", Status: domain.DependencyUp, Required: true, LatencyMs: 12, CheckedAt: checkedAt},
+			}},
+			expected: http.StatusOK,
+			body: `{"status":"ready","dependencies":[{"name":"openai","status":"up","required":true,"latency_ms":12,
+				"checked_at":"2025-01-02T03:04:05Z"}]}`,
+		},
+		{
+			name: "required dependency down",
+			readiness: domain.Readiness{Status: domain.StatusNotReady, Dependencies: []domain.DependencyStatus{
+				{Name: "openai", Status: domain.DependencyDown, Required: true, Error: "unexpected status 500", CheckedAt: checkedAt},
+			}},
+			expected: http.StatusServiceUnavailable,
+			body: `{"status":"not_ready","dependencies":[{"name":"openai","status":"down","required":true,
+				"error":"unexpected status 500","latency_ms":0,"checked_at":"2025-01-02T03:04:05Z"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := &MockHealthUseCase{}
+			mockUseCase.On("Readiness").Return(tt.readiness)
+			router := setupHealthRouter(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/readyz", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"prompthor/internal/domain"
+	"strconv"
+)
+
+// SetCurrentRequest represents the request moving the current alias of a template
+type SetCurrentRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// TemplateHandler handles HTTP requests managing the prompt templates
+type TemplateHandler struct {
+	usecase domain.TemplateUseCase
+}
+
+// NewTemplateHandler creates a new instance of the template controller
+func NewTemplateHandler(templateUseCase domain.TemplateUseCase) *TemplateHandler {
+	return &TemplateHandler{
+		usecase: templateUseCase,
+	}
+}
+
+// HandleCreate processes the POST request creating a new version of a template
+func (h *TemplateHandler) HandleCreate(c *gin.Context) {
+	var request domain.TemplateRequest
+
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+		respondJSONError(c, http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+	template, err := h.usecase.Create(ctx, request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// HandleList processes the GET request listing the templates
+func (h *TemplateHandler) HandleList(c *gin.Context) {
+	templates, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// HandleGet processes the GET request returning the current version of a template, or the one in the version query parameter
+func (h *TemplateHandler) HandleGet(c *gin.Context) {
+	version := 0
+	if value := c.Query("version"); value != "" {
+		var err error
+		if version, err = strconv.Atoi(value); err != nil || version < 1 {
+			respondJSONError(c, http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: version must be a positive number",
+			})
+			return
+		}
+	}
+	template, err := h.usecase.Get(c.Request.Context(), c.Param("name"), version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// HandleVersions processes the GET request listing the versions of a template
+func (h *TemplateHandler) HandleVersions(c *gin.Context) {
+	versions, err := h.usecase.Versions(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// HandleSetCurrent processes the PUT request pointing the current alias of a template to one of its versions
+func (h *TemplateHandler) HandleSetCurrent(c *gin.Context) {
+	var request SetCurrentRequest
+
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid request")
+		respondJSONError(c, http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+	template, err := h.usecase.SetCurrent(ctx, c.Param("name"), request.Version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// respondTemplateError writes a 404 response when the template or its version does not exist
+func respondTemplateError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrTemplateNotFound) {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msgf("template %s not found", c.Param("name"))
+		respondJSONError(c, http.StatusNotFound, gin.H{
+			"error": "Template not found: " + c.Param("name"),
+		})
+		return
+	}
+	respondError(c, err)
+}
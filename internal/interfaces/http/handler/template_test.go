@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"prompthor/internal/domain"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTemplateUseCase is a mock implementation of TemplateUseCase
+type MockTemplateUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTemplateUseCase) Render(ctx context.Context, name string, version int, variables map[string]any) (*domain.RenderedTemplate, error) {
+	args := m.Called(name, version, variables)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RenderedTemplate), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Create(ctx context.Context, request domain.TemplateRequest) (*domain.Template, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Get(ctx context.Context, name string, version int) (*domain.Template, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Versions(ctx context.Context, name string) ([]domain.Template, error) {
+	args := m.Called(name)
+	return args.Get(0).([]domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) List(ctx context.Context) ([]domain.TemplateInfo, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.TemplateInfo), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) SetCurrent(ctx context.Context, name string, version int) (*domain.Template, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func setupTemplateRouter(useCase domain.TemplateUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	templateHandler := NewTemplateHandler(useCase)
+	router.GET("/templates", templateHandler.HandleList)
+	router.POST("/templates", templateHandler.HandleCreate)
+	router.GET("/templates/:name", templateHandler.HandleGet)
+	router.GET("/templates/:name/versions", templateHandler.HandleVersions)
+	router.PUT("/templates/:name/current", templateHandler.HandleSetCurrent)
+	return router
+}
+
+func TestTemplateHandler_HandleCreate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("Create", domain.TemplateRequest{Name: "greeting", Body: "Hi {{.name}}", Variables: []string{"name"}}).
+			Return(&domain.Template{Name: "greeting", Version: 1, Role: "user", Body: "Hi {{.name}}", Variables: []string{"name"}}, nil)
+		router := setupTemplateRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/templates", bytes.NewBufferString(`{"name":"greeting","body":"Hi {{.name}}","variables":["name"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":1`)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		router := setupTemplateRouter(&MockTemplateUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/templates", bytes.NewBufferString(`{"name":"greeting","body":"Hi","role":"tool"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("Create", mock.Anything).Return(nil, &domain.InputError{Message: "invalid template body: unclosed action"})
+		router := setupTemplateRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/templates", bytes.NewBufferString(`{"name":"greeting","body":"Hi {{.name"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unclosed action")
+	})
+}
+
+func TestTemplateHandler_HandleList(t *testing.T) {
+	mockUseCase := &MockTemplateUseCase{}
+	mockUseCase.On("List").Return([]domain.TemplateInfo{{Name: "support", Current: 1, Latest: 2}}, nil)
+	router := setupTemplateRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/templates", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"templates":[{"name":"support","current":1,"latest":2}]}`, w.Body.String())
+}
+
+func TestTemplateHandler_HandleGet(t *testing.T) {
+	t.Run("current version", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("Get", "support", 0).Return(&domain.Template{Name: "support", Version: 2}, nil)
+		router := setupTemplateRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/templates/support", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":2`)
+	})
+
+	t.Run("requested version", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("Get", "support", 1).Return(&domain.Template{Name: "support", Version: 1}, nil)
+		router := setupTemplateRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/templates/support?version=1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":1`)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		router := setupTemplateRouter(&MockTemplateUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/templates/support?version=latest", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("Get", "missing", 0).Return(nil, domain.ErrTemplateNotFound)
+		router := setupTemplateRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/templates/missing", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"error":"Template not found: missing"}`, w.Body.String())
+	})
+}
+
+func TestTemplateHandler_HandleVersions(t *testing.T) {
+	mockUseCase := &MockTemplateUseCase{}
+	mockUseCase.On("Versions", "support").Return([]domain.Template{{Name: "support", Version: 1}, {Name: "support", Version: 2}}, nil)
+	router := setupTemplateRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/templates/support/versions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"version":2`)
+}
+
+func TestTemplateHandler_HandleSetCurrent(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		expected int
+	}{
+		{name: "rolled back", body: `{"version":1}`, expected: http.StatusOK},
+		{name: "unknown version", body: `{"version":9}`, err: domain.ErrTemplateNotFound, expected: http.StatusNotFound},
+		{name: "missing version", body: `{}`, expected: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := &MockTemplateUseCase{}
+			if tt.err != nil {
+				mockUseCase.On("SetCurrent", "support", mock.Anything).Return(nil, tt.err)
+			} else {
+				mockUseCase.On("SetCurrent", "support", mock.Anything).Return(&domain.Template{Name: "support", Version: 1}, nil)
+			}
+			router := setupTemplateRouter(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/templates/support/current", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
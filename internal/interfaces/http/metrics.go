@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"prompthor/internal/domain"
+	"time"
+)
+
+// unmatchedRoute labels the requests not matching any route, so unknown paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// metricsMiddleware records the count, latency and status of the requests per route
+func metricsMiddleware(metrics domain.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestStarted()
+		defer func() {
+			route := c.FullPath()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			metrics.HTTPRequestFinished(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"prompthor/internal/domain"
+	"regexp"
+)
+
+// validRequestID are the request ids accepted from the clients, other ones are replaced so they can't pollute the logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDMiddleware stores the ids of the request and its caller in its context, generating the request id when the
+// client didn't send a valid one, and echoes the request id in the response headers.
+// The request header is updated too, so the logger and the gateway sender use the same id.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(domain.RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+			c.Request.Header.Set(domain.RequestIDHeader, requestID)
+		}
+		c.Header(domain.RequestIDHeader, requestID)
+		ctx := domain.WithRequestIDs(c.Request.Context(), domain.RequestIDs{
+			RequestID:     requestID,
+			CorrelationID: c.GetHeader(domain.CorrelationIDHeader),
+			RoutingKey:    c.GetHeader(domain.RoutingKeyHeader),
+			Caller:        caller(c.Request),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// caller returns the subject of the verified client certificate of the request, empty without mutual TLS
+func caller(request *http.Request) string {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 {
+		return ""
+	}
+	return request.TLS.VerifiedChains[0][0].Subject.String()
+}
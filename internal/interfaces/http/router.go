@@ -4,20 +4,130 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/narumayase/anysher/middleware"
 	"github.com/narumayase/anysher/middleware/gateway"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"net/http"
 	"prompthor/internal/domain"
 	"prompthor/internal/interfaces/http/handler"
 )
 
+// serviceName is the name of the server in its spans
+const serviceName = "prompthor"
+
+// Option configures optional routes of the API
+type Option func(*routes)
+
+// routes holds the use cases of the optional routes
+type routes struct {
+	documentUseCase       domain.DocumentUseCase
+	maxUploadBytes        int64
+	collectionUseCase     domain.CollectionUseCase
+	collectionUploadBytes int64
+	embeddingUseCase      domain.EmbeddingUseCase
+	templateUseCase       domain.TemplateUseCase
+	experimentUseCase     domain.ExperimentUseCase
+	metrics               domain.Metrics
+	metricsHandler        http.Handler
+	healthUseCase         domain.HealthUseCase
+	configUseCase         domain.ConfigUseCase
+}
+
+// WithDocumentUseCase adds the route answering prompts about uploaded files, maxUploadBytes limits the request size
+func WithDocumentUseCase(documentUseCase domain.DocumentUseCase, maxUploadBytes int64) Option {
+	return func(r *routes) {
+		r.documentUseCase = documentUseCase
+		r.maxUploadBytes = maxUploadBytes
+	}
+}
+
+// WithCollectionUseCase adds the routes managing the document collections, maxUploadBytes limits the ingestion requests size
+func WithCollectionUseCase(collectionUseCase domain.CollectionUseCase, maxUploadBytes int64) Option {
+	return func(r *routes) {
+		r.collectionUseCase = collectionUseCase
+		r.collectionUploadBytes = maxUploadBytes
+	}
+}
+
+// WithEmbeddingUseCase adds the OpenAI shaped embeddings route
+func WithEmbeddingUseCase(embeddingUseCase domain.EmbeddingUseCase) Option {
+	return func(r *routes) {
+		r.embeddingUseCase = embeddingUseCase
+	}
+}
+
+// WithTemplateUseCase adds the routes managing the prompt templates
+func WithTemplateUseCase(templateUseCase domain.TemplateUseCase) Option {
+	return func(r *routes) {
+		r.templateUseCase = templateUseCase
+	}
+}
+
+// WithExperimentUseCase adds the routes reporting the experiments and collecting the feedback of their variants
+func WithExperimentUseCase(experimentUseCase domain.ExperimentUseCase) Option {
+	return func(r *routes) {
+		r.experimentUseCase = experimentUseCase
+	}
+}
+
+// WithMetrics records the metrics of the requests and adds the route exposing them, served by handler
+func WithMetrics(metrics domain.Metrics, handler http.Handler) Option {
+	return func(r *routes) {
+		r.metrics = metrics
+		r.metricsHandler = handler
+	}
+}
+
+// WithHealthUseCase adds the readiness probe checking the dependencies of the API
+func WithHealthUseCase(healthUseCase domain.HealthUseCase) Option {
+	return func(r *routes) {
+		r.healthUseCase = healthUseCase
+	}
+}
+
+// WithConfigUseCase adds the administration routes reporting and reloading the configuration, only served to the
+// requests with the admin token of the active configuration as bearer token, and not served without token
+func WithConfigUseCase(configUseCase domain.ConfigUseCase) Option {
+	return func(r *routes) {
+		r.configUseCase = configUseCase
+	}
+}
+
 // SetupRouter configures the API routes
-func SetupRouter(chatUseCase domain.ChatUseCase) *gin.Engine {
+func SetupRouter(chatUseCase domain.ChatUseCase, options ...Option) *gin.Engine {
+	var optional routes
+	for _, option := range options {
+		option(&optional)
+	}
 	router := gin.Default()
 
-	// Add middlewares
+	// The probes and the metrics routes are added before the middlewares, so they are neither logged nor sent to the gateway
+	healthHandler := handler.NewHealthHandler(optional.healthUseCase)
+	router.GET("/livez", healthHandler.HandleLiveness)
+	if optional.healthUseCase != nil {
+		router.GET("/readyz", healthHandler.HandleReadiness)
+	}
+	if optional.metrics != nil {
+		router.GET("/metrics", gin.WrapH(optional.metricsHandler))
+		router.Use(metricsMiddleware(optional.metrics))
+	}
+
+	// The administration routes are added before the middlewares too, so the admin token is neither logged with the
+	// headers nor sent to the gateway
+	if optional.configUseCase != nil {
+		configHandler := handler.NewConfigHandler(optional.configUseCase)
+		admin := router.Group("/admin", requestIDMiddleware(), middleware.Logger(), middleware.RequestIDToLogger(),
+			adminMiddleware(optional.configUseCase))
+		admin.GET("/config", configHandler.HandleVersion)
+		admin.POST("/config/reload", configHandler.HandleReload)
+	}
+
+	// Add middlewares, the server span first so it covers every other middleware
+	router.Use(otelgin.Middleware(serviceName))
+	router.Use(requestIDMiddleware())
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORS())
 	router.Use(middleware.HeadersToContext())
 	router.Use(middleware.RequestIDToLogger())
-	router.Use(gateway.Sender())
+	router.Use(traceGateway(gateway.Sender())...)
 	router.Use(middleware.ErrorHandler())
 
 	// Create the controller
@@ -26,6 +136,35 @@ func SetupRouter(chatUseCase domain.ChatUseCase) *gin.Engine {
 	// API routes group
 	api := router.Group("/api/v1")
 	api.POST("/chat/ask", chatHandler.HandleChat)
+	if optional.documentUseCase != nil {
+		documentHandler := handler.NewDocumentHandler(optional.documentUseCase, optional.maxUploadBytes)
+		api.POST("/chat/ask-with-files", documentHandler.HandleAskWithFiles)
+	}
+	if optional.collectionUseCase != nil {
+		collectionHandler := handler.NewCollectionHandler(optional.collectionUseCase, optional.collectionUploadBytes)
+		api.GET("/collections", collectionHandler.HandleList)
+		api.POST("/collections/:name/documents", collectionHandler.HandleIngest)
+		api.DELETE("/collections/:name", collectionHandler.HandleDelete)
+	}
+	if optional.templateUseCase != nil {
+		templateHandler := handler.NewTemplateHandler(optional.templateUseCase)
+		api.GET("/templates", templateHandler.HandleList)
+		api.POST("/templates", templateHandler.HandleCreate)
+		api.GET("/templates/:name", templateHandler.HandleGet)
+		api.GET("/templates/:name/versions", templateHandler.HandleVersions)
+		api.PUT("/templates/:name/current", templateHandler.HandleSetCurrent)
+	}
+	if optional.experimentUseCase != nil {
+		experimentHandler := handler.NewExperimentHandler(optional.experimentUseCase)
+		api.GET("/experiments", experimentHandler.HandleStats)
+		api.POST("/experiments/:name/feedback", experimentHandler.HandleFeedback)
+	}
+
+	// OpenAI shaped routes, for the clients of the OpenAI SDKs
+	if optional.embeddingUseCase != nil {
+		embeddingHandler := handler.NewEmbeddingHandler(optional.embeddingUseCase)
+		router.POST("/v1/embeddings", embeddingHandler.HandleEmbeddings)
+	}
 
 	// Health check route
 	router.GET("/health", func(c *gin.Context) {
@@ -2,15 +2,26 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"prompthor/internal/domain"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MockChatUseCase is a mock implementation of ChatUseCase for router tests
@@ -23,6 +34,63 @@ func (m *MockChatUseCase) ProcessChat(ctx context.Context, prompt domain.PromptR
 	return args.Get(0).(*domain.ChatResponse), args.Error(1)
 }
 
+// MockMetrics is a mock implementation of Metrics for router tests
+type MockMetrics struct {
+	mock.Mock
+}
+
+func (m *MockMetrics) HTTPRequestStarted() {
+	m.Called()
+}
+
+func (m *MockMetrics) HTTPRequestFinished(method, route string, status int, duration time.Duration) {
+	m.Called(method, route, status, duration)
+}
+
+func (m *MockMetrics) LLMRequestStarted(provider string) {
+	m.Called(provider)
+}
+
+func (m *MockMetrics) LLMRequestFinished(provider, model string, duration time.Duration, usage domain.Usage, err error) {
+	m.Called(provider, model, duration, usage, err)
+}
+
+func (m *MockMetrics) Retry(reason string) {
+	m.Called(reason)
+}
+
+func (m *MockMetrics) CacheLookup(cache string, hit bool) {
+	m.Called(cache, hit)
+}
+
+// MockHealthUseCase is a mock implementation of HealthUseCase for router tests
+type MockHealthUseCase struct {
+	mock.Mock
+}
+
+func (m *MockHealthUseCase) Readiness(ctx context.Context) domain.Readiness {
+	return m.Called().Get(0).(domain.Readiness)
+}
+
+// MockConfigUseCase is a mock implementation of ConfigUseCase for router tests
+type MockConfigUseCase struct {
+	mock.Mock
+}
+
+func (m *MockConfigUseCase) Version(ctx context.Context) domain.ConfigVersion {
+	return m.Called().Get(0).(domain.ConfigVersion)
+}
+
+func (m *MockConfigUseCase) Reload(ctx context.Context) (domain.ConfigVersion, error) {
+	args := m.Called()
+	return args.Get(0).(domain.ConfigVersion), args.Error(1)
+}
+
+func (m *MockConfigUseCase) AdminToken(ctx context.Context) (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func TestSetupRouter(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUseCase := &MockChatUseCase{}
@@ -101,6 +169,520 @@ func TestRouter_ChatEndpoint(t *testing.T) {
 	})
 }
 
+// MockDocumentUseCase is a mock implementation of DocumentUseCase for router tests
+type MockDocumentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockDocumentUseCase) AskWithFiles(ctx context.Context, prompt domain.PromptRequest, files []domain.File) (*domain.ChatResponse, error) {
+	args := m.Called(ctx, prompt, files)
+	return args.Get(0).(*domain.ChatResponse), args.Error(1)
+}
+
+func TestRouter_AskWithFilesEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("endpoint exists with a document use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{}, WithDocumentUseCase(&MockDocumentUseCase{}, 1024))
+		req, _ := http.NewRequest("POST", "/api/v1/chat/ask-with-files", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("endpoint is not registered without a document use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+		req, _ := http.NewRequest("POST", "/api/v1/chat/ask-with-files", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// MockCollectionUseCase is a mock implementation of CollectionUseCase for router tests
+type MockCollectionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCollectionUseCase) Retrieve(ctx context.Context, collection, query string, k int) ([]domain.ScoredChunk, error) {
+	args := m.Called(ctx, collection, query, k)
+	return args.Get(0).([]domain.ScoredChunk), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Ingest(ctx context.Context, collection string, files []domain.File) (*domain.Collection, error) {
+	args := m.Called(ctx, collection, files)
+	return args.Get(0).(*domain.Collection), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Collections(ctx context.Context) ([]domain.Collection, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Collection), args.Error(1)
+}
+
+func (m *MockCollectionUseCase) Delete(ctx context.Context, collection string) error {
+	return m.Called(ctx, collection).Error(0)
+}
+
+func TestRouter_CollectionEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("endpoints exist with a collection use case", func(t *testing.T) {
+		mockUseCase := &MockCollectionUseCase{}
+		mockUseCase.On("Collections", mock.Anything).Return([]domain.Collection{}, nil)
+		router := SetupRouter(&MockChatUseCase{}, WithCollectionUseCase(mockUseCase, 1024))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/collections", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/collections/handbook/documents", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("endpoints are not registered without a collection use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/collections", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// MockEmbeddingUseCase is a mock implementation of EmbeddingUseCase for router tests
+type MockEmbeddingUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingUseCase) CreateEmbeddings(ctx context.Context, request domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*domain.EmbeddingResponse), args.Error(1)
+}
+
+func TestRouter_EmbeddingsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("endpoint exists with an embedding use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{}, WithEmbeddingUseCase(&MockEmbeddingUseCase{}))
+		req, _ := http.NewRequest("POST", "/v1/embeddings", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("endpoint is not registered without an embedding use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+		req, _ := http.NewRequest("POST", "/v1/embeddings", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// MockTemplateUseCase is a mock implementation of TemplateUseCase for router tests
+type MockTemplateUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTemplateUseCase) Render(ctx context.Context, name string, version int, variables map[string]any) (*domain.RenderedTemplate, error) {
+	args := m.Called(ctx, name, version, variables)
+	return args.Get(0).(*domain.RenderedTemplate), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Create(ctx context.Context, request domain.TemplateRequest) (*domain.Template, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Get(ctx context.Context, name string, version int) (*domain.Template, error) {
+	args := m.Called(ctx, name, version)
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) Versions(ctx context.Context, name string) ([]domain.Template, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).([]domain.Template), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) List(ctx context.Context) ([]domain.TemplateInfo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.TemplateInfo), args.Error(1)
+}
+
+func (m *MockTemplateUseCase) SetCurrent(ctx context.Context, name string, version int) (*domain.Template, error) {
+	args := m.Called(ctx, name, version)
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+func TestRouter_TemplateEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("endpoints exist with a template use case", func(t *testing.T) {
+		mockUseCase := &MockTemplateUseCase{}
+		mockUseCase.On("List", mock.Anything).Return([]domain.TemplateInfo{}, nil)
+		router := SetupRouter(&MockChatUseCase{}, WithTemplateUseCase(mockUseCase))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/templates", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", "/api/v1/templates/support/current", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("endpoints are not registered without a template use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/templates", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// MockExperimentUseCase is a mock implementation of ExperimentUseCase for router tests
+type MockExperimentUseCase struct {
+	MockChatUseCase
+}
+
+func (m *MockExperimentUseCase) Stats(ctx context.Context) ([]domain.ExperimentStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.ExperimentStats), args.Error(1)
+}
+
+func (m *MockExperimentUseCase) Feedback(ctx context.Context, experiment string, feedback domain.Feedback) error {
+	return m.Called(ctx, experiment, feedback).Error(0)
+}
+
+func TestRouter_ExperimentEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("endpoints exist with an experiment use case", func(t *testing.T) {
+		mockUseCase := &MockExperimentUseCase{}
+		mockUseCase.On("Stats", mock.Anything).Return([]domain.ExperimentStats{}, nil)
+		router := SetupRouter(mockUseCase, WithExperimentUseCase(mockUseCase))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/experiments", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/experiments/support-prompt/feedback", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("endpoints are not registered without an experiment use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/experiments", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRouter_MetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scrape := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("prompthor_http_requests_total 1\n"))
+	})
+
+	t.Run("records the requests by route", func(t *testing.T) {
+		metrics := &MockMetrics{}
+		metrics.On("HTTPRequestStarted").Return()
+		metrics.On("HTTPRequestFinished", "GET", "/health", http.StatusOK, mock.AnythingOfType("time.Duration")).Return()
+		metrics.On("HTTPRequestFinished", "GET", "unmatched", http.StatusNotFound, mock.AnythingOfType("time.Duration")).Return()
+		router := SetupRouter(&MockChatUseCase{}, WithMetrics(metrics, scrape))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/health", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/unknown/path", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		metrics.AssertExpectations(t)
+		metrics.AssertNumberOfCalls(t, "HTTPRequestStarted", 2)
+	})
+
+	t.Run("scrapes are served and not recorded", func(t *testing.T) {
+		metrics := &MockMetrics{}
+		router := SetupRouter(&MockChatUseCase{}, WithMetrics(metrics, scrape))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "prompthor_http_requests_total")
+		metrics.AssertNotCalled(t, "HTTPRequestStarted")
+	})
+
+	t.Run("endpoint is not registered without metrics", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRouter_ProbeEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("liveness is always served", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/livez", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"OK"}`, w.Body.String())
+	})
+
+	t.Run("readiness reports the dependencies and is not recorded", func(t *testing.T) {
+		healthUseCase := &MockHealthUseCase{}
+		healthUseCase.On("Readiness").Return(domain.Readiness{
+			Status:       domain.StatusNotReady,
+			Dependencies: []domain.DependencyStatus{{Name: "openai", Status: domain.DependencyDown, Required: true}},
+		})
+		metrics := &MockMetrics{}
+		router := SetupRouter(&MockChatUseCase{}, WithHealthUseCase(healthUseCase), WithMetrics(metrics, http.NotFoundHandler()))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"not_ready"`)
+		metrics.AssertNotCalled(t, "HTTPRequestStarted")
+	})
+
+	t.Run("readiness is not registered without health use case", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRouter_AdminEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{name: "admin token", authorization: "Bearer secret-token", expected: http.StatusOK},
+		{name: "wrong token", authorization: "Bearer other-token", expected: http.StatusUnauthorized},
+		{name: "missing token", expected: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "secret-token", expected: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configUseCase := &MockConfigUseCase{}
+			configUseCase.On("AdminToken").Return("secret-token", nil)
+			configUseCase.On("Reload").Return(domain.ConfigVersion{Version: 2, Checksum: "def"}, nil)
+			router := SetupRouter(&MockChatUseCase{}, WithConfigUseCase(configUseCase))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/admin/config/reload", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected != http.StatusOK {
+				configUseCase.AssertNotCalled(t, "Reload")
+			}
+		})
+	}
+
+	t.Run("admin routes are not served without admin token", func(t *testing.T) {
+		configUseCase := &MockConfigUseCase{}
+		configUseCase.On("AdminToken").Return("", nil)
+		router := SetupRouter(&MockChatUseCase{}, WithConfigUseCase(configUseCase))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin/config", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("the admin token of a reloaded configuration applies to the next requests", func(t *testing.T) {
+		configUseCase := &MockConfigUseCase{}
+		configUseCase.On("AdminToken").Return("first-token", nil).Once()
+		configUseCase.On("AdminToken").Return("rotated-token", nil)
+		configUseCase.On("Version").Return(domain.ConfigVersion{Version: 2})
+		router := SetupRouter(&MockChatUseCase{}, WithConfigUseCase(configUseCase))
+
+		var codes []int
+		for range 2 {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin/config", nil)
+			req.Header.Set("Authorization", "Bearer rotated-token")
+			router.ServeHTTP(w, req)
+			codes = append(codes, w.Code)
+		}
+
+		assert.Equal(t, []int{http.StatusUnauthorized, http.StatusOK}, codes)
+	})
+
+	t.Run("admin routes are rejected while the admin token can't be read", func(t *testing.T) {
+		configUseCase := &MockConfigUseCase{}
+		configUseCase.On("AdminToken").Return("", errors.New("secret file /run/secrets/admin_token is empty"))
+		router := SetupRouter(&MockChatUseCase{}, WithConfigUseCase(configUseCase))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/admin/config/reload", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"error":"Admin token unavailable"}`, w.Body.String())
+		configUseCase.AssertNotCalled(t, "Reload")
+	})
+}
+
+func TestRouter_Tracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	originalProvider, originalPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(originalProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	router := SetupRouter(&MockChatUseCase{})
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	gatewaySpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "gateway.Sender", gatewaySpan.Name())
+	assert.Equal(t, "GET /health", serverSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), gatewaySpan.Parent().SpanID())
+}
+
+func TestRouter_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("forwards the request ids to the use case and echoes the request id", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		mockUseCase.On("ProcessChat", mock.MatchedBy(func(ctx context.Context) bool {
+			return domain.RequestIDsFrom(ctx) == domain.RequestIDs{
+				RequestID:     "client-request-1",
+				CorrelationID: "f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+				RoutingKey:    "telegram:12345",
+			}
+		}), mock.Anything).Return(&domain.ChatResponse{Response: "Hi"}, nil)
+		router := SetupRouter(mockUseCase)
+
+		req, _ := http.NewRequest("POST", "/api/v1/chat/ask", strings.NewReader(`{"prompt":"Hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "client-request-1")
+		req.Header.Set("X-Correlation-ID", "f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+		req.Header.Set("X-Routing-Key", "telegram:12345")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "client-request-1", w.Header().Get("X-Request-Id"))
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("identifies the mutual TLS callers by their certificate subject", func(t *testing.T) {
+		mockUseCase := &MockChatUseCase{}
+		mockUseCase.On("ProcessChat", mock.MatchedBy(func(ctx context.Context) bool {
+			return domain.RequestIDsFrom(ctx).Caller == "CN=billing,O=Acme"
+		}), mock.Anything).Return(&domain.ChatResponse{Response: "Hi"}, nil)
+		router := SetupRouter(mockUseCase)
+
+		req, _ := http.NewRequest("POST", "/api/v1/chat/ask", strings.NewReader(`{"prompt":"Hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+			{Subject: pkix.Name{CommonName: "billing", Organization: []string{"Acme"}}},
+		}}}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("generates the request id when missing or invalid", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		for _, requestID := range []string{"", "bad id\twith spaces", strings.Repeat("a", 129)} {
+			req, _ := http.NewRequest("GET", "/health", nil)
+			if requestID != "" {
+				req.Header.Set("X-Request-Id", requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			generated := w.Header().Get("X-Request-Id")
+			assert.Len(t, generated, 36)
+			assert.NotEqual(t, requestID, generated)
+		}
+	})
+
+	t.Run("error bodies carry the request id", func(t *testing.T) {
+		router := SetupRouter(&MockChatUseCase{})
+
+		req, _ := http.NewRequest("POST", "/api/v1/chat/ask", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "client-request-2")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "client-request-2", response["request_id"])
+	})
+}
+
 func TestRouter_CORSMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUseCase := &MockChatUseCase{}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the middlewares
+const tracerName = "prompthor/internal/interfaces/http"
+
+// gatewaySpanKey is the key of the gin context holding the span of the gateway sender
+const gatewaySpanKey = "gatewaySpan"
+
+// traceGateway wraps the gateway sender in a span covering only what it does after the handler has run,
+// sending the response to the gateway, so it is not mistaken for the time spent in the handler
+func traceGateway(sender gin.HandlerFunc) []gin.HandlerFunc {
+	end := func(c *gin.Context) {
+		c.Next()
+		if span, ok := c.Get(gatewaySpanKey); ok {
+			span.(trace.Span).End()
+		}
+	}
+	start := func(c *gin.Context) {
+		c.Next()
+		_, span := otel.Tracer(tracerName).Start(c.Request.Context(), "gateway.Sender")
+		c.Set(gatewaySpanKey, span)
+	}
+	return []gin.HandlerFunc{end, sender, start}
+}
@@ -1,70 +1,680 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	anysherhttp "github.com/narumayase/anysher/http"
 	"github.com/rs/zerolog/log"
+	"io"
+	"maps"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"prompthor/cmd/server"
 	"prompthor/config"
 	"prompthor/internal/application"
 	"prompthor/internal/domain"
+	"prompthor/internal/infrastructure/audit"
 	"prompthor/internal/infrastructure/client"
+	"prompthor/internal/infrastructure/document"
+	"prompthor/internal/infrastructure/embedding"
+	"prompthor/internal/infrastructure/experiment"
+	"prompthor/internal/infrastructure/health"
+	"prompthor/internal/infrastructure/mcp"
+	"prompthor/internal/infrastructure/metrics"
 	"prompthor/internal/infrastructure/repository"
+	"prompthor/internal/infrastructure/templatestore"
+	"prompthor/internal/infrastructure/tool"
+	"prompthor/internal/infrastructure/tracing"
+	"prompthor/internal/infrastructure/vectorstore"
+	httphandler "prompthor/internal/interfaces/http"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run serves the API until it stops and returns the exit code, once the deferred flushes and closes have run
+func run() int {
+	// Mask the secrets in the logs, from the loading of the configuration on
+	log.Logger = log.Output(config.MaskSecrets(os.Stderr))
+	gin.DefaultWriter = config.MaskSecrets(os.Stdout)
+	gin.DefaultErrorWriter = config.MaskSecrets(os.Stderr)
+
+	// Validate the configuration instead of serving when asked, eg: prompthor config validate -config prompthor.yaml
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		return configCommand(os.Args[2:], os.Stdout)
+	}
+
 	// Load configuration
-	cfg := config.Load()
+	configFile := flag.String("config", "", "YAML configuration file, CONFIG_FILE by default")
+	flag.Parse()
+	cfg, err := config.LoadFile(*configFile)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid configuration")
+		return 1
+	}
+
+	// Trace the requests before anything sends one
+	shutdownTracing, err := initializeTracing(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to initialize tracing")
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
-	// Create repository based on configuration
-	chatRepository := initializeRepositories(cfg)
+	var routes []httphandler.Option
+	var dependencies snapshotDependencies
+	if cfg.MetricsEnabled {
+		prometheus := metrics.NewPrometheus()
+		dependencies.metrics = prometheus
+		routes = append(routes, httphandler.WithMetrics(prometheus, prometheus.Handler()))
+	}
 
-	// Create use case
-	chatUseCase := application.NewChatUseCase(chatRepository)
+	// Create use cases
+	embeddingRepository, err := initializeEmbeddingRepository(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create use cases")
+		return 1
+	}
+	embeddingUseCase := application.NewEmbeddingUseCase(embeddingRepository, cfg.EmbeddingsBatchSize)
+	embedder, err := initializeEmbedder(cfg, embeddingUseCase)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create use cases")
+		return 1
+	}
+	vectorStore, err := initializeVectorStore(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create use cases")
+		return 1
+	}
+	templateStore, err := initializeTemplateStore(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create use cases")
+		return 1
+	}
+	extractor := document.NewExtractor()
+	collectionUseCase := application.NewCollectionUseCase(extractor, embedder, vectorStore, cfg.DocumentChunkTokens)
+	templateUseCase := application.NewTemplateUseCase(templateStore)
+	dependencies.retriever = collectionUseCase
+	dependencies.templates = templateUseCase
+	if len(cfg.AgentTools) > 0 || len(cfg.MCPServers) > 0 {
+		mcpClient, err := initializeMCPClient(cfg)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create use cases")
+			return 1
+		}
+		defer mcpClient.Close()
 
-	server.Run(cfg, chatUseCase)
+		dependencies.mcpTools = mcpClient.Tools()
+	}
+	if cfg.AuditFile != "" {
+		auditSink, err := initializeAuditSink(cfg)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create use cases")
+			return 1
+		}
+		defer auditSink.Close()
+
+		dependencies.auditSink = auditSink
+	}
+	snapshot, err := newSnapshot(cfg, dependencies)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create use cases")
+		return 1
+	}
+	log.Info().Msgf("🚀 Starting with %s API", providerNames[chatProvider(cfg)])
+	reloadableUseCase := application.NewReloadableUseCase(snapshot, cfg.ConfigFile, snapshotBuilder(cfg, dependencies))
+	reloadConfig(context.Background(), cfg, reloadableUseCase)
+	documentUseCase := application.NewDocumentUseCase(reloadableUseCase, extractor,
+		application.WithChunkTokens(cfg.DocumentChunkTokens),
+		application.WithContextWindows(cfg.ContextWindowTokens, cfg.ModelContextWindows))
+	routes = append(routes,
+		httphandler.WithDocumentUseCase(documentUseCase, int64(cfg.DocumentMaxUploadBytes)),
+		httphandler.WithCollectionUseCase(collectionUseCase, int64(cfg.DocumentMaxUploadBytes)),
+		httphandler.WithEmbeddingUseCase(embeddingUseCase),
+		httphandler.WithTemplateUseCase(templateUseCase),
+		httphandler.WithExperimentUseCase(reloadableUseCase),
+		httphandler.WithHealthUseCase(reloadableUseCase),
+		httphandler.WithConfigUseCase(reloadableUseCase),
+	)
+
+	if err := server.Run(cfg, reloadableUseCase, routes...); err != nil {
+		log.Error().Err(err).Msg("server failed")
+		return 1
+	}
+	return 0
 }
 
-// initializeRepositories creates and returns the appropriate chat repository based on configuration
-func initializeRepositories(config config.Config) domain.LLMRepository {
-	var chatRepository domain.LLMRepository
+// configCommand runs the config command, which validates the configuration and reports every error found
+func configCommand(args []string, out io.Writer) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(out, "usage: prompthor config validate [-config file]")
+		return 2
+	}
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	configFile := flags.String("config", "", "YAML configuration file, CONFIG_FILE by default")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if _, err := config.LoadFile(*configFile); err != nil {
+		fmt.Fprintf(out, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintln(out, "configuration is valid")
+	return 0
+}
+
+// chatProvider returns the llm provider selected by the configuration, empty when none is configured
+func chatProvider(config config.Config) string {
 	switch {
+	case config.ChatProvider != "":
+		return config.ChatProvider
 	case config.ChatModel == "OpenAI" && config.OpenAIKey != "":
-		// initialize OpenAI repository
-		chatRepository = initializeOpenAIRepository(config)
+		return "openai"
+	case config.ChatModel == "Mistral" && config.MistralAPIKey != "":
+		return "mistral"
+	case config.ChatModel == "Cohere" && config.CohereAPIKey != "":
+		return "cohere"
 	case config.GroqAPIKey != "":
+		return "groq"
+	default:
+		return ""
+	}
+}
+
+// chatModel returns the default model of the chat provider of the configuration
+func chatModel(config config.Config) string {
+	switch chatProvider(config) {
+	case "openai":
+		return config.OpenAIModel
+	case "mistral":
+		return config.MistralModel
+	case "cohere":
+		return config.CohereModel
+	default:
+		return config.ChatModel
+	}
+}
+
+// chatModels returns the models configured for the chat provider: its default model, the vision models, the Groq
+// chat completions models and the models with a context window
+func chatModels(config config.Config) []string {
+	models := append([]string{chatModel(config)}, config.VisionModels...)
+	models = append(models, config.GroqChatCompletionsModels...)
+	return append(models, slices.Sorted(maps.Keys(config.ModelContextWindows))...)
+}
+
+// providerNames are the names of the chat providers in the logs
+var providerNames = map[string]string{
+	"openai":  "OpenAI",
+	"mistral": "Mistral",
+	"cohere":  "Cohere",
+	"groq":    "Groq",
+}
+
+// snapshotDependencies are the dependencies of the configuration snapshots kept across the reloads
+type snapshotDependencies struct {
+	metrics   domain.Metrics
+	retriever domain.Retriever
+	templates domain.TemplateRenderer
+	mcpTools  []domain.ToolExecutor
+	auditSink domain.AuditSink
+}
+
+// newChatUseCase creates the chat use case of the configuration: the repository of its provider with the chat options,
+// the tools and the audit trail
+func newChatUseCase(cfg config.Config, dependencies snapshotDependencies) (domain.ChatUseCase, error) {
+	chatRepository, err := initializeRepositories(cfg)
+	if err != nil {
+		return nil, err
+	}
+	chatRepository = repository.NewTracedRepository(chatRepository, chatProvider(cfg), chatModel(cfg))
+	var options []application.Option
+	if dependencies.metrics != nil {
+		chatRepository = repository.NewInstrumentedRepository(chatRepository, chatProvider(cfg), chatModels(cfg), dependencies.metrics)
+		options = append(options, application.WithMetrics(dependencies.metrics))
+	}
+	options = append(options,
+		application.WithStripReasoning(cfg.StripReasoning),
+		application.WithMaxRepairs(cfg.StructuredOutputRepairs),
+		application.WithMaxImageBytes(cfg.VisionMaxImageBytes),
+		application.WithRetriever(dependencies.retriever, cfg.RAGTopK),
+		application.WithTemplates(dependencies.templates),
+	)
+	if len(cfg.AgentTools) > 0 || len(cfg.MCPServers) > 0 {
+		registry, err := initializeToolRegistry(cfg, dependencies.mcpTools...)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options,
+			application.WithToolRegistry(registry),
+			application.WithAgentBudget(cfg.AgentMaxIterations, cfg.AgentTimeout))
+	}
+	var chatUseCase domain.ChatUseCase = application.NewChatUseCase(chatRepository, options...)
+	if dependencies.auditSink != nil {
+		redactor, err := initializeRedactor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		chatUseCase = application.NewAuditedChatUseCase(chatUseCase, dependencies.auditSink, redactor, chatProvider(cfg))
+	}
+	return chatUseCase, nil
+}
+
+// newSnapshot creates the snapshot of the configuration: the chat use case with its experiments, the readiness checks
+// and the admin token
+func newSnapshot(cfg config.Config, dependencies snapshotDependencies) (application.ConfigSnapshot, error) {
+	chatUseCase, err := newChatUseCase(cfg, dependencies)
+	if err != nil {
+		return application.ConfigSnapshot{}, err
+	}
+	snapshot := application.ConfigSnapshot{
+		ChatUseCase: chatUseCase,
+		HealthUseCase: application.NewHealthUseCase(cfg.ReadinessCacheTTL, cfg.ReadinessTimeout, dependencies.metrics,
+			initializeHealthChecks(cfg)...),
+		AdminToken: cfg.AdminToken.Value,
+	}
+	var experiments domain.Experiments
+	if cfg.ExperimentsFile != "" {
+		if experiments, err = experiment.LoadFile(cfg.ExperimentsFile); err != nil {
+			return application.ConfigSnapshot{}, err
+		}
+		if snapshot.ExperimentUseCase, err = initializeExperiments(experiments, chatUseCase); err != nil {
+			return application.ConfigSnapshot{}, err
+		}
+	}
+	snapshot.Checksum = configChecksum(cfg, experiments)
+	return snapshot, nil
+}
+
+// snapshotBuilder returns the builder of the snapshot of the configuration reloaded from the file of started, the
+// configuration the server started with. A configuration changing the settings only applied on start is rejected.
+func snapshotBuilder(started config.Config, dependencies snapshotDependencies) application.SnapshotBuilder {
+	return func() (application.ConfigSnapshot, error) {
+		cfg, err := config.LoadFile(started.ConfigFile)
+		if err != nil {
+			return application.ConfigSnapshot{}, err
+		}
+		if err := checkRestartSettings(started, cfg); err != nil {
+			return application.ConfigSnapshot{}, err
+		}
+		return newSnapshot(cfg, dependencies)
+	}
+}
+
+// restartSettings returns the settings only applied on start by their variable: the server, the documents, the
+// embeddings and the collections, the templates, the metrics, the tracing, the audit file, the MCP servers and the
+// watch of the configuration file
+func restartSettings(cfg config.Config) map[string]any {
+	return map[string]any{
+		"PORT":                      cfg.Port,
+		"HTTP_READ_TIMEOUT":         cfg.HTTPReadTimeout,
+		"HTTP_WRITE_TIMEOUT":        cfg.HTTPWriteTimeout,
+		"HTTP_IDLE_TIMEOUT":         cfg.HTTPIdleTimeout,
+		"SHUTDOWN_TIMEOUT":          cfg.ShutdownTimeout,
+		"TLS_CERT_FILE":             cfg.TLSCertFile,
+		"TLS_KEY_FILE":              cfg.TLSKeyFile,
+		"TLS_CLIENT_CA_FILE":        cfg.TLSClientCAFile,
+		"TLS_CLIENT_AUTH":           cfg.TLSClientAuth,
+		"DOCUMENT_MAX_UPLOAD_BYTES": cfg.DocumentMaxUploadBytes,
+		"DOCUMENT_CHUNK_TOKENS":     cfg.DocumentChunkTokens,
+		"CONTEXT_WINDOW_TOKENS":     cfg.ContextWindowTokens,
+		"MODEL_CONTEXT_WINDOWS":     cfg.ModelContextWindows,
+		"EMBEDDINGS_PROVIDER":       cfg.EmbeddingsProvider,
+		"EMBEDDINGS_MODEL":          cfg.EmbeddingsModel,
+		"EMBEDDINGS_URL":            cfg.EmbeddingsUrl,
+		"EMBEDDINGS_API_KEY":        cfg.EmbeddingsAPIKey,
+		"EMBEDDINGS_BATCH_SIZE":     cfg.EmbeddingsBatchSize,
+		"OLLAMA_URL":                cfg.OllamaUrl,
+		"RAG_EMBEDDER":              cfg.RAGEmbedder,
+		"RAG_STORE_PATH":            cfg.RAGStorePath,
+		"TEMPLATES_DIR":             cfg.TemplatesDir,
+		"METRICS_ENABLED":           cfg.MetricsEnabled,
+		"TRACING_EXPORTER":          cfg.TracingExporter,
+		"AUDIT_FILE":                cfg.AuditFile,
+		"AUDIT_MAX_BYTES":           cfg.AuditMaxBytes,
+		"AUDIT_RETENTION":           cfg.AuditRetention,
+		"MCP_SERVERS":               cfg.MCPServers,
+		"CONFIG_WATCH_INTERVAL":     cfg.ConfigWatchInterval,
+	}
+}
+
+// checkRestartSettings returns an error naming the settings only applied on start that cfg changes from started
+func checkRestartSettings(started, cfg config.Config) error {
+	startedSettings, settings := restartSettings(started), restartSettings(cfg)
+	var changed []string
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if !reflect.DeepEqual(startedSettings[key], settings[key]) {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%s: changed, needs a restart", strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// configChecksum returns the checksum identifying the values of the configuration and its experiments. The secrets are
+// masked in its dump, so they are added as they are set: a value, or the reference to the file read again on rotation.
+func configChecksum(cfg config.Config, experiments domain.Experiments) string {
+	content := fmt.Appendf(nil, "%#v", cfg)
+	// The experiments are added as JSON, their dump would have the addresses of the temperatures
+	if encoded, err := json.Marshal(experiments); err == nil {
+		content = append(content, encoded...)
+	}
+	secrets := cfg.Secrets()
+	for _, key := range slices.Sorted(maps.Keys(secrets)) {
+		content = append(content, secrets[key]...)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:6])
+}
+
+// reloadConfig reloads the configuration on SIGHUP and, when it is read from a file, on the changes of the file
+func reloadConfig(ctx context.Context, cfg config.Config, configUseCase domain.ConfigUseCase) {
+	ctx = log.Logger.WithContext(ctx)
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				log.Ctx(ctx).Info().Msg("reloading the configuration on SIGHUP")
+				configUseCase.Reload(ctx)
+			}
+		}
+	}()
+	if cfg.ConfigFile != "" && cfg.ConfigWatchInterval > 0 {
+		go config.Watch(ctx, cfg.ConfigFile, cfg.ConfigWatchInterval, func() {
+			log.Ctx(ctx).Info().Msgf("reloading the configuration on the change of %s", cfg.ConfigFile)
+			configUseCase.Reload(ctx)
+		})
+	}
+}
+
+// initializeRepositories creates and returns the appropriate chat repository based on configuration
+func initializeRepositories(config config.Config) (domain.LLMRepository, error) {
+	switch chatProvider(config) {
+	case "openai":
+		// initialize OpenAI repository
+		return initializeOpenAIRepository(config)
+	case "mistral":
+		// initialize Mistral repository
+		return initializeMistralRepository(config)
+	case "cohere":
+		// initialize Cohere repository
+		return initializeCohereRepository(config)
+	case "groq":
 		// initialize Groq repository
-		chatRepository = initializeGroqRepository(config)
+		return initializeGroqRepository(config)
 	default:
-		log.Panic().Err(fmt.Errorf("no valid LLM repository configuration found"))
+		return nil, fmt.Errorf("no valid LLM repository configuration found")
 	}
-	return chatRepository
 }
 
 // initializeGroqRepository creates and configures a Groq repository instance
-func initializeGroqRepository(config config.Config) domain.LLMRepository {
+func initializeGroqRepository(config config.Config) (domain.LLMRepository, error) {
 	// Create a new HTTP client
-	httpClient := anysherhttp.NewClient(&http.Client{})
+	httpClient := anysherhttp.NewClient(client.NewHTTPClient())
 
-	log.Info().Msg("🚀 Starting with Groq API")
 	chatRepo, err := repository.NewGroqRepository(config, httpClient)
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to create Groq repository: %v", err)
-		log.Fatal()
+		return nil, fmt.Errorf("failed to create Groq repository: %w", err)
 	}
-	return chatRepo
+	return chatRepo, nil
 }
 
 // initializeOpenAIRepository creates and configures an OpenAI repository instance
-func initializeOpenAIRepository(config config.Config) domain.LLMRepository {
-	openaiClient := client.NewOpenAIClient(config.OpenAIKey)
+func initializeOpenAIRepository(config config.Config) (domain.LLMRepository, error) {
+	openaiClient := client.NewOpenAIClient(config)
+
+	chatRepo, err := repository.NewOpenAIRepository(config, openaiClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAI repository: %w", err)
+	}
+	return chatRepo, nil
+}
+
+// initializeMistralRepository creates and configures a Mistral repository instance
+func initializeMistralRepository(config config.Config) (domain.LLMRepository, error) {
+	httpClient := anysherhttp.NewClient(client.NewHTTPClient())
+
+	chatRepo, err := repository.NewMistralRepository(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Mistral repository: %w", err)
+	}
+	return chatRepo, nil
+}
+
+// initializeCohereRepository creates and configures a Cohere repository instance
+func initializeCohereRepository(config config.Config) (domain.LLMRepository, error) {
+	httpClient := anysherhttp.NewClient(client.NewHTTPClient())
+
+	chatRepo, err := repository.NewCohereRepository(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cohere repository: %w", err)
+	}
+	return chatRepo, nil
+}
+
+// initializeTracing installs the tracer provider of the configured exporter and returns the function flushing its spans
+func initializeTracing(config config.Config) (func(context.Context) error, error) {
+	shutdown, err := tracing.Setup(context.Background(), config.TracingExporter)
+	if err != nil {
+		return nil, err
+	}
+	if config.TracingExporter != "none" {
+		log.Info().Msgf("🔭 Tracing exported to %s", config.TracingExporter)
+	}
+	return shutdown, nil
+}
+
+// initializeMCPClient connects to the configured MCP servers
+func initializeMCPClient(config config.Config) (*mcp.Client, error) {
+	servers, err := mcp.ParseServers(config.MCPServers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MCP servers configuration: %w", err)
+	}
+	return mcp.Connect(context.Background(), servers), nil
+}
+
+// initializeToolRegistry creates the registry with the built-in tools enabled in the configuration and the MCP tools
+func initializeToolRegistry(config config.Config, mcpTools ...domain.ToolExecutor) (domain.ToolRegistry, error) {
+	registry, err := tool.NewRegistry(mcpTools...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tool registry: %w", err)
+	}
+	for _, name := range config.AgentTools {
+		builtin, err := tool.Builtin(name, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tool: %w", err)
+		}
+		if err := registry.Register(builtin); err != nil {
+			return nil, fmt.Errorf("failed to register tool: %w", err)
+		}
+	}
+	log.Info().Msgf("🔧 Agent tools enabled: %v, %d MCP tools", config.AgentTools, len(mcpTools))
+	return registry, nil
+}
+
+// initializeEmbeddingRepository creates the embeddings repository of the configured provider
+func initializeEmbeddingRepository(config config.Config) (domain.EmbeddingRepository, error) {
+	var (
+		embeddingRepository domain.EmbeddingRepository
+		err                 error
+	)
+	switch config.EmbeddingsProvider {
+	case "openai":
+		embeddingRepository, err = repository.NewOpenAIEmbeddingRepository(config, client.NewOpenAIClient(config))
+	case "ollama":
+		embeddingRepository, err = repository.NewOllamaEmbeddingRepository(config, anysherhttp.NewClient(client.NewHTTPClient()))
+	case "openai_compatible":
+		embeddingRepository, err = repository.NewCompatibleEmbeddingRepository(config, anysherhttp.NewClient(client.NewHTTPClient()))
+	default:
+		err = fmt.Errorf("unknown embeddings provider %q, use openai, ollama or openai_compatible", config.EmbeddingsProvider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings repository: %w", err)
+	}
+	log.Info().Msgf("🧮 Embeddings with %s %s", config.EmbeddingsProvider, config.EmbeddingsModel)
+	return embeddingRepository, nil
+}
+
+// initializeEmbedder creates the embedder of the documents of the collections, the local hash embedder by default
+func initializeEmbedder(config config.Config, provider domain.Embedder) (domain.Embedder, error) {
+	switch config.RAGEmbedder {
+	case "provider":
+		return provider, nil
+	case "local":
+		return embedding.NewHashEmbedder(0), nil
+	default:
+		return nil, fmt.Errorf("unknown RAG embedder %q, use local or provider", config.RAGEmbedder)
+	}
+}
+
+// initializeVectorStore creates the vector store of the collections, loading the saved ones
+func initializeVectorStore(config config.Config) (domain.VectorStore, error) {
+	store, err := vectorstore.NewMemoryStore(config.RAGStorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vector store: %w", err)
+	}
+	return store, nil
+}
+
+// initializeTemplateStore creates the store of the prompt templates, loading and validating the ones in the templates
+// directory
+func initializeTemplateStore(config config.Config) (domain.TemplateStore, error) {
+	store, err := templatestore.NewFileStore(config.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	if err := application.ValidateTemplates(context.Background(), store); err != nil {
+		return nil, fmt.Errorf("invalid templates in %s: %w", config.TemplatesDir, err)
+	}
+	return store, nil
+}
+
+// initializeExperiments splits the chat requests across the variants of the experiments
+func initializeExperiments(experiments domain.Experiments, chatUseCase domain.ChatUseCase) (domain.ExperimentUseCase, error) {
+	experimentUseCase, err := application.NewExperimentUseCase(chatUseCase, experiments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid experiments: %w", err)
+	}
+	log.Info().Msgf("🧪 Running %d experiments", len(experiments.Experiments))
+	return experimentUseCase, nil
+}
+
+// initializeAuditSink opens the audit file, rotated by size and day and cleaned up after the retention
+func initializeAuditSink(config config.Config) (domain.AuditSink, error) {
+	sink, err := audit.NewFileSink(config.AuditFile, int64(config.AuditMaxBytes), config.AuditRetention)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	log.Info().Msgf("📜 Auditing the chat requests to %s", config.AuditFile)
+	return sink, nil
+}
 
-	log.Info().Msg("🚀 Starting with OpenAI API")
-	chatRepo, err := repository.NewOpenAIRepository(openaiClient)
+// initializeRedactor creates the redactor of the personal data written to the audit trail
+func initializeRedactor(config config.Config) (*application.Redactor, error) {
+	redactor, err := application.NewRedactor(config.AuditRedact, config.AuditRedactPatterns)
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to create OpenAI repository: %v", err)
-		log.Fatal()
+		return nil, fmt.Errorf("invalid audit redaction rules: %w", err)
+	}
+	return redactor, nil
+}
+
+// initializeHealthChecks creates the checks of the readiness: the chat provider and the storage directories are
+// required, the embeddings provider and the gateway are only reported
+func initializeHealthChecks(config config.Config) []application.HealthCheck {
+	var checks []application.HealthCheck
+	if checker := providerChecker(config); checker != nil {
+		checks = append(checks, application.HealthCheck{HealthChecker: checker, Required: true})
+	}
+	if checker := embeddingsChecker(config); checker != nil {
+		checks = append(checks, application.HealthCheck{HealthChecker: checker})
+	}
+	if config.GatewayEnabled {
+		checks = append(checks, application.HealthCheck{
+			HealthChecker: health.NewReachabilityChecker("gateway", config.GatewayAPIUrl, http.DefaultClient),
+		})
+	}
+	if config.RAGStorePath != "" {
+		checks = append(checks, application.HealthCheck{
+			HealthChecker: health.NewDirChecker("rag_store", filepath.Dir(config.RAGStorePath)),
+			Required:      true,
+		})
+	}
+	if config.TemplatesDir != "" {
+		checks = append(checks, application.HealthCheck{
+			HealthChecker: health.NewDirChecker("templates", config.TemplatesDir),
+			Required:      true,
+		})
+	}
+	if config.AuditFile != "" {
+		checks = append(checks, application.HealthCheck{
+			HealthChecker: health.NewDirChecker("audit", filepath.Dir(config.AuditFile)),
+			Required:      true,
+		})
+	}
+	return checks
+}
+
+// providerChecker creates the check of the chat provider, listing its models with the API key
+func providerChecker(config config.Config) domain.HealthChecker {
+	endpoint, apiKey := config.GroqUrl, config.GroqAPIKey
+	switch chatProvider(config) {
+	case "openai":
+		return health.NewAuthChecker("openai", strings.TrimSuffix(config.OpenAIBaseUrl, "/")+"/models", config.OpenAIKey,
+			http.DefaultClient)
+	case "mistral":
+		endpoint, apiKey = config.MistralUrl, config.MistralAPIKey
+	case "cohere":
+		endpoint, apiKey = config.CohereUrl, config.CohereAPIKey
+	case "groq":
+	default:
+		return nil
+	}
+	modelsURL, err := health.ModelsURL(endpoint)
+	if err != nil {
+		log.Warn().Err(err).Msgf("%s is checked by reachability only", chatProvider(config))
+		return health.NewReachabilityChecker(chatProvider(config), endpoint, http.DefaultClient)
+	}
+	if chatProvider(config) == "cohere" {
+		// Cohere only lists the models in the v1 API
+		modelsURL = strings.Replace(modelsURL, "/v2/models", "/v1/models", 1)
+	}
+	return health.NewAuthChecker(chatProvider(config), modelsURL, apiKey, http.DefaultClient)
+}
+
+// embeddingsChecker creates the check of the embeddings provider
+func embeddingsChecker(config config.Config) domain.HealthChecker {
+	switch config.EmbeddingsProvider {
+	case "openai":
+		return health.NewAuthChecker("embeddings", strings.TrimSuffix(config.OpenAIBaseUrl, "/")+"/models", config.OpenAIKey,
+			http.DefaultClient)
+	case "ollama":
+		return health.NewAuthChecker("embeddings", strings.TrimSuffix(config.OllamaUrl, "/")+"/api/tags", "", http.DefaultClient)
+	case "openai_compatible":
+		if modelsURL, err := health.ModelsURL(config.EmbeddingsUrl); err == nil {
+			return health.NewAuthChecker("embeddings", modelsURL, config.EmbeddingsAPIKey, http.DefaultClient)
+		}
+		return health.NewReachabilityChecker("embeddings", config.EmbeddingsUrl, http.DefaultClient)
+	default:
+		return nil
 	}
-	return chatRepo
 }
@@ -1,9 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"prompthor/config"
+	"prompthor/internal/application"
+	"prompthor/internal/domain"
+	"prompthor/internal/infrastructure/audit"
+	"prompthor/internal/infrastructure/embedding"
+	"prompthor/internal/infrastructure/experiment"
 	"prompthor/internal/infrastructure/repository"
+	"prompthor/internal/infrastructure/templatestore"
+	"prompthor/internal/infrastructure/vectorstore"
 	"testing"
 )
 
@@ -13,19 +27,227 @@ func TestInitializeRepositories(t *testing.T) {
 			OpenAIKey: "test-key",
 			ChatModel: "OpenAI",
 		}
-		llmRepo := initializeRepositories(cfg)
+		llmRepo, err := initializeRepositories(cfg)
+		require.NoError(t, err)
 		assert.NotNil(t, llmRepo)
 		assert.IsType(t, &repository.OpenAIRepository{}, llmRepo)
 	})
 
+	t.Run("should return Mistral repository when configured", func(t *testing.T) {
+		cfg := config.Config{
+			MistralAPIKey: "test-key",
+			ChatModel:     "Mistral",
+		}
+		llmRepo, err := initializeRepositories(cfg)
+		require.NoError(t, err)
+		assert.NotNil(t, llmRepo)
+		assert.IsType(t, &repository.MistralRepository{}, llmRepo)
+	})
+
+	t.Run("should return Cohere repository when configured", func(t *testing.T) {
+		cfg := config.Config{
+			CohereAPIKey: "test-key",
+			ChatModel:    "Cohere",
+		}
+		llmRepo, err := initializeRepositories(cfg)
+		require.NoError(t, err)
+		assert.NotNil(t, llmRepo)
+		assert.IsType(t, &repository.CohereRepository{}, llmRepo)
+	})
+
 	t.Run("should return Groq repository when configured", func(t *testing.T) {
 		cfg := config.Config{
 			GroqAPIKey: "test-key",
 		}
-		llmRepo := initializeRepositories(cfg)
+		llmRepo, err := initializeRepositories(cfg)
+		require.NoError(t, err)
 		assert.NotNil(t, llmRepo)
 		assert.IsType(t, &repository.GroqRepository{}, llmRepo)
 	})
+
+	t.Run("should fail without provider", func(t *testing.T) {
+		_, err := initializeRepositories(config.Config{ChatModel: "OpenAI"})
+		assert.EqualError(t, err, "no valid LLM repository configuration found")
+	})
+}
+
+func TestNewChatUseCase(t *testing.T) {
+	t.Run("should audit the requests with an audit sink", func(t *testing.T) {
+		sink, err := initializeAuditSink(config.Config{AuditFile: filepath.Join(t.TempDir(), "audit.jsonl"), AuditMaxBytes: 1024})
+		require.NoError(t, err)
+		defer sink.Close()
+		chatUseCase, err := newChatUseCase(config.Config{GroqAPIKey: "test-key", AuditRedact: []string{"email"}},
+			snapshotDependencies{auditSink: sink})
+		require.NoError(t, err)
+		assert.IsType(t, &application.AuditedChatUseCase{}, chatUseCase)
+	})
+
+	t.Run("should fail with an unknown tool", func(t *testing.T) {
+		_, err := newChatUseCase(config.Config{GroqAPIKey: "test-key", AgentTools: []string{"teleport"}}, snapshotDependencies{})
+		assert.ErrorContains(t, err, "failed to create tool")
+	})
+}
+
+func TestNewSnapshot(t *testing.T) {
+	t.Run("should build the chat use case, the experiments, the readiness and the admin token", func(t *testing.T) {
+		cfg := config.Config{
+			GroqAPIKey:      "test-key",
+			AdminToken:      "admin-token",
+			ExperimentsFile: "internal/infrastructure/experiment/testdata/experiments.json",
+		}
+
+		snapshot, err := newSnapshot(cfg, snapshotDependencies{})
+
+		require.NoError(t, err)
+		assert.IsType(t, &application.ChatUseCaseImpl{}, snapshot.ChatUseCase)
+		assert.IsType(t, &application.ExperimentUseCaseImpl{}, snapshot.ExperimentUseCase)
+		assert.IsType(t, &application.HealthUseCaseImpl{}, snapshot.HealthUseCase)
+		adminToken, err := snapshot.AdminToken()
+		require.NoError(t, err)
+		assert.Equal(t, "admin-token", adminToken)
+		assert.Len(t, snapshot.Checksum, 12)
+	})
+
+	t.Run("should fail with invalid experiments", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "experiments.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"experiments": [{"name": "tone"}]}`), 0600))
+
+		_, err := newSnapshot(config.Config{GroqAPIKey: "test-key", ExperimentsFile: path}, snapshotDependencies{})
+
+		assert.EqualError(t, err, "invalid experiments: experiment tone has no variants")
+	})
+}
+
+func TestSnapshotBuilder(t *testing.T) {
+	for _, env := range []string{"CONFIG_FILE", "CHAT_PROVIDER", "CHAT_MODEL", "GROQ_API_KEY", "MISTRAL_API_KEY", "ADMIN_TOKEN", "PORT", "TEMPLATES_DIR"} {
+		t.Setenv(env, "")
+	}
+	path := filepath.Join(t.TempDir(), "prompthor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("groq:\n  api_key: test-key\n"), 0600))
+	started, err := config.LoadFile(path)
+	require.NoError(t, err)
+	build := snapshotBuilder(started, snapshotDependencies{})
+
+	groq, err := build()
+	require.NoError(t, err)
+	assert.IsType(t, &application.ChatUseCaseImpl{}, groq.ChatUseCase)
+	adminToken, err := groq.AdminToken()
+	require.NoError(t, err)
+	assert.Empty(t, adminToken)
+	again, err := build()
+	require.NoError(t, err)
+	assert.Equal(t, groq.Checksum, again.Checksum)
+
+	require.NoError(t, os.WriteFile(path, []byte("chat:\n  provider: mistral\nmistral:\n  api_key: test-key\nadmin:\n  token: admin-token\n"), 0600))
+	mistral, err := build()
+	require.NoError(t, err)
+	assert.NotEqual(t, groq.Checksum, mistral.Checksum)
+	adminToken, err = mistral.AdminToken()
+	require.NoError(t, err)
+	assert.Equal(t, "admin-token", adminToken)
+
+	require.NoError(t, os.WriteFile(path, []byte("chat:\n  provider: mistral\n"), 0600))
+	_, err = build()
+	assert.ErrorContains(t, err, "MISTRAL_API_KEY: required by the mistral chat provider")
+
+	require.NoError(t, os.WriteFile(path, []byte("groq:\n  api_key: test-key\nserver:\n  port: 9090\ntemplates:\n  dir: templates\n"), 0600))
+	_, err = build()
+	assert.EqualError(t, err, "PORT, TEMPLATES_DIR: changed, needs a restart")
+}
+
+func TestCheckRestartSettings(t *testing.T) {
+	started := config.Config{Port: "8080", AuditFile: "audit.jsonl", MCPServers: []string{"docs=http://localhost:3000"},
+		ModelContextWindows: map[string]int{"gpt-4o": 128000}}
+
+	assert.NoError(t, checkRestartSettings(started, started))
+	reloaded := started
+	reloaded.GroqAPIKey = "other-key"
+	reloaded.RAGTopK = 8
+	assert.NoError(t, checkRestartSettings(started, reloaded))
+	reloaded.MetricsEnabled = true
+	reloaded.MCPServers = []string{"docs=http://localhost:3001"}
+	reloaded.ModelContextWindows = map[string]int{"gpt-4o": 64000}
+	assert.EqualError(t, checkRestartSettings(started, reloaded),
+		"MCP_SERVERS, METRICS_ENABLED, MODEL_CONTEXT_WINDOWS: changed, needs a restart")
+}
+
+func TestConfigChecksum(t *testing.T) {
+	cfg := config.Config{GroqAPIKey: "test-key", ModelContextWindows: map[string]int{"gpt-4o": 128000, "llama3": 8192}}
+	temperature := 0.2
+	experiments := domain.Experiments{Experiments: []domain.Experiment{
+		{Name: "tone", Variants: []domain.Variant{{Name: "formal", Weight: 1, Temperature: &temperature}}},
+	}}
+	sameTemperature := 0.2
+	same := domain.Experiments{Experiments: []domain.Experiment{
+		{Name: "tone", Variants: []domain.Variant{{Name: "formal", Weight: 1, Temperature: &sameTemperature}}},
+	}}
+	assert.Equal(t, configChecksum(cfg, experiments), configChecksum(cfg, same))
+	rotated := cfg
+	rotated.GroqAPIKey = "other-key"
+	assert.NotEqual(t, configChecksum(cfg, experiments), configChecksum(rotated, experiments))
+	assert.NotEqual(t, configChecksum(cfg, experiments), configChecksum(cfg, domain.Experiments{}))
+}
+
+func TestChatProvider(t *testing.T) {
+	assert.Equal(t, "openai", chatProvider(config.Config{ChatModel: "OpenAI", OpenAIKey: "test-key"}))
+	assert.Equal(t, "mistral", chatProvider(config.Config{ChatModel: "Mistral", MistralAPIKey: "test-key"}))
+	assert.Equal(t, "cohere", chatProvider(config.Config{ChatModel: "Cohere", CohereAPIKey: "test-key"}))
+	assert.Equal(t, "groq", chatProvider(config.Config{ChatModel: "OpenAI", GroqAPIKey: "test-key"}))
+	assert.Empty(t, chatProvider(config.Config{ChatModel: "OpenAI"}))
+	assert.Equal(t, "mistral", chatProvider(config.Config{ChatProvider: "mistral", GroqAPIKey: "test-key"}))
+}
+
+func TestChatModels(t *testing.T) {
+	cfg := config.Config{
+		ChatProvider:        "mistral",
+		ChatModel:           "openai/gpt-oss-20b",
+		MistralModel:        "mistral-small-latest",
+		VisionModels:        []string{"pixtral-12b-latest"},
+		ModelContextWindows: map[string]int{"mistral-large-latest": 131072, "codestral-latest": 32768},
+	}
+
+	assert.Equal(t, "mistral-small-latest", chatModel(cfg))
+	assert.Equal(t, []string{"mistral-small-latest", "pixtral-12b-latest", "codestral-latest", "mistral-large-latest"}, chatModels(cfg))
+	assert.Equal(t, "openai/gpt-oss-20b", chatModel(config.Config{ChatModel: "openai/gpt-oss-20b", GroqAPIKey: "test-key"}))
+}
+
+func TestConfigCommand(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "")
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	assert.NoError(t, os.WriteFile(valid, []byte("groq:\n  api_key: test-key\n"), 0600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	assert.NoError(t, os.WriteFile(invalid, []byte("chat:\n  model: openai\n"), 0600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		expected string
+	}{
+		{name: "valid", args: []string{"validate", "-config", valid}, code: 0, expected: "configuration is valid"},
+		{name: "invalid", args: []string{"validate", "-config", invalid}, code: 1,
+			expected: "invalid configuration:\nCHAT_MODEL: \"openai\" is not a provider, did you mean OpenAI?"},
+		{name: "unknown subcommand", args: []string{"print"}, code: 2, expected: "usage: prompthor config validate"},
+		{name: "unknown flag", args: []string{"validate", "-strict"}, code: 2, expected: "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			code := configCommand(tt.args, &out)
+
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, out.String(), tt.expected)
+		})
+	}
+}
+
+func TestInitializeTracing(t *testing.T) {
+	shutdown, err := initializeTracing(config.Config{TracingExporter: "none"})
+	require.NoError(t, err)
+	assert.NotNil(t, shutdown)
+	assert.NoError(t, shutdown(context.Background()))
 }
 
 func TestInitializeGroqRepository(t *testing.T) {
@@ -33,7 +255,8 @@ func TestInitializeGroqRepository(t *testing.T) {
 		cfg := config.Config{
 			GroqAPIKey: "test-key",
 		}
-		repo := initializeGroqRepository(cfg)
+		repo, err := initializeGroqRepository(cfg)
+		require.NoError(t, err)
 		assert.NotNil(t, repo)
 		assert.IsType(t, &repository.GroqRepository{}, repo)
 	})
@@ -44,8 +267,202 @@ func TestInitializeOpenAIRepository(t *testing.T) {
 		cfg := config.Config{
 			OpenAIKey: "test-key",
 		}
-		repo := initializeOpenAIRepository(cfg)
+		repo, err := initializeOpenAIRepository(cfg)
+		require.NoError(t, err)
 		assert.NotNil(t, repo)
 		assert.IsType(t, &repository.OpenAIRepository{}, repo)
 	})
 }
+
+func TestInitializeToolRegistry(t *testing.T) {
+	t.Run("should register the configured built-in tools", func(t *testing.T) {
+		cfg := config.Config{
+			AgentTools: []string{"calculator", "current_time"},
+		}
+		registry, err := initializeToolRegistry(cfg)
+		require.NoError(t, err)
+
+		assert.Len(t, registry.Tools(), 2)
+		_, ok := registry.Get("calculator")
+		assert.True(t, ok)
+		_, ok = registry.Get("http_fetch")
+		assert.False(t, ok)
+	})
+}
+
+func TestInitializeMCPClient(t *testing.T) {
+	t.Run("should return an empty client without MCP servers", func(t *testing.T) {
+		mcpClient, err := initializeMCPClient(config.Config{})
+		require.NoError(t, err)
+		defer mcpClient.Close()
+
+		assert.Empty(t, mcpClient.Tools())
+	})
+
+	t.Run("should return an error for an invalid MCP servers configuration", func(t *testing.T) {
+		_, err := initializeMCPClient(config.Config{MCPServers: []string{"no-target"}})
+		assert.EqualError(t, err, `invalid MCP servers configuration: invalid MCP server "no-target", expected name=target`)
+	})
+}
+
+func TestInitializeEmbeddingRepository(t *testing.T) {
+	tests := []struct {
+		provider string
+		expected interface{}
+	}{
+		{provider: "openai", expected: &repository.OpenAIEmbeddingRepository{}},
+		{provider: "ollama", expected: &repository.OllamaEmbeddingRepository{}},
+		{provider: "openai_compatible", expected: &repository.CompatibleEmbeddingRepository{}},
+	}
+	for _, tt := range tests {
+		t.Run("should return the "+tt.provider+" repository", func(t *testing.T) {
+			cfg := config.Config{EmbeddingsProvider: tt.provider, EmbeddingsUrl: "http://localhost:8000/v1/embeddings"}
+			embeddingRepository, err := initializeEmbeddingRepository(cfg)
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, embeddingRepository)
+		})
+	}
+
+	t.Run("should return an error for an unknown provider", func(t *testing.T) {
+		_, err := initializeEmbeddingRepository(config.Config{EmbeddingsProvider: "unknown"})
+		assert.EqualError(t, err, `failed to create embeddings repository: unknown embeddings provider "unknown", use openai, ollama or openai_compatible`)
+	})
+}
+
+func TestInitializeEmbedder(t *testing.T) {
+	t.Run("should return the local embedder", func(t *testing.T) {
+		embedder, err := initializeEmbedder(config.Config{RAGEmbedder: "local"}, nil)
+		require.NoError(t, err)
+		assert.IsType(t, &embedding.HashEmbedder{}, embedder)
+	})
+
+	t.Run("should return the embeddings provider when configured", func(t *testing.T) {
+		provider := embedding.NewHashEmbedder(8)
+		embedder, err := initializeEmbedder(config.Config{RAGEmbedder: "provider"}, provider)
+		require.NoError(t, err)
+		assert.Same(t, provider, embedder)
+	})
+
+	t.Run("should return an error for an unknown embedder", func(t *testing.T) {
+		_, err := initializeEmbedder(config.Config{RAGEmbedder: "remote"}, nil)
+		assert.EqualError(t, err, `unknown RAG embedder "remote", use local or provider`)
+	})
+}
+
+func TestInitializeVectorStore(t *testing.T) {
+	t.Run("should return an in-memory store", func(t *testing.T) {
+		store, err := initializeVectorStore(config.Config{})
+		require.NoError(t, err)
+		assert.IsType(t, &vectorstore.MemoryStore{}, store)
+	})
+}
+
+func TestInitializeTemplateStore(t *testing.T) {
+	t.Run("should return a file store", func(t *testing.T) {
+		store, err := initializeTemplateStore(config.Config{TemplatesDir: t.TempDir()})
+		require.NoError(t, err)
+		assert.IsType(t, &templatestore.FileStore{}, store)
+	})
+
+	t.Run("should reject an invalid template file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "greeting"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "greeting", "v1.json"), []byte(`{"body": "Hi {{.name"}`), 0o644))
+
+		_, err := initializeTemplateStore(config.Config{TemplatesDir: dir})
+
+		assert.ErrorContains(t, err, "invalid templates in "+dir+": template greeting version 1: invalid template body:")
+	})
+}
+
+func TestInitializeExperiments(t *testing.T) {
+	t.Run("should wrap the chat use case with the experiments", func(t *testing.T) {
+		experiments, err := experiment.LoadFile("internal/infrastructure/experiment/testdata/experiments.json")
+		require.NoError(t, err)
+
+		experimentUseCase, err := initializeExperiments(experiments, &application.ChatUseCaseImpl{})
+
+		require.NoError(t, err)
+		assert.IsType(t, &application.ExperimentUseCaseImpl{}, experimentUseCase)
+	})
+}
+
+func TestInitializeAuditSink(t *testing.T) {
+	sink, err := initializeAuditSink(config.Config{AuditFile: filepath.Join(t.TempDir(), "audit.jsonl"), AuditMaxBytes: 1024})
+	require.NoError(t, err)
+	assert.IsType(t, &audit.FileSink{}, sink)
+	assert.NoError(t, sink.Close())
+}
+
+func TestInitializeRedactor(t *testing.T) {
+	redactor, err := initializeRedactor(config.Config{AuditRedact: []string{"email"}, AuditRedactPatterns: []string{`employee=EMP-\d{6}`}})
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED:email] is [REDACTED:employee]", redactor.Redact("ada@example.com is EMP-123456"))
+}
+
+func TestInitializeHealthChecks(t *testing.T) {
+	t.Run("should check the provider and the storage as required", func(t *testing.T) {
+		dir := t.TempDir()
+		checks := initializeHealthChecks(config.Config{
+			ChatModel:          "Cohere",
+			CohereAPIKey:       "test-key",
+			CohereUrl:          "https://api.cohere.com/v2/chat",
+			EmbeddingsProvider: "ollama",
+			OllamaUrl:          "http://localhost:11434",
+			GatewayEnabled:     true,
+			GatewayAPIUrl:      "http://anyway:9889",
+			RAGStorePath:       filepath.Join(dir, "collections.json"),
+			TemplatesDir:       dir,
+			AuditFile:          filepath.Join(dir, "audit.jsonl"),
+		})
+
+		var names []string
+		required := make(map[string]bool)
+		for _, check := range checks {
+			names = append(names, check.Name())
+			required[check.Name()] = check.Required
+		}
+		assert.Equal(t, []string{"cohere", "embeddings", "gateway", "rag_store", "templates", "audit"}, names)
+		assert.Equal(t, map[string]bool{"cohere": true, "embeddings": false, "gateway": false, "rag_store": true,
+			"templates": true, "audit": true}, required)
+	})
+
+	t.Run("should skip the gateway and the storage when disabled", func(t *testing.T) {
+		checks := initializeHealthChecks(config.Config{GroqAPIKey: "test-key", GroqUrl: "https://api.groq.com/openai/v1/responses"})
+
+		assert.Len(t, checks, 1)
+		assert.Equal(t, "groq", checks[0].Name())
+	})
+}
+
+func TestProviderChecker(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   config.Config
+		expected string
+	}{
+		{name: "openai", config: config.Config{ChatModel: "OpenAI", OpenAIKey: "test-key", OpenAIBaseUrl: server.URL + "/v1/"},
+			expected: "/v1/models"},
+		{name: "mistral", config: config.Config{ChatModel: "Mistral", MistralAPIKey: "test-key", MistralUrl: server.URL + "/v1/chat/completions"},
+			expected: "/v1/models"},
+		{name: "cohere", config: config.Config{ChatModel: "Cohere", CohereAPIKey: "test-key", CohereUrl: server.URL + "/v2/chat"},
+			expected: "/v1/models"},
+		{name: "groq", config: config.Config{GroqAPIKey: "test-key", GroqUrl: server.URL + "/openai/v1/responses"},
+			expected: "/openai/v1/models"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := providerChecker(tt.config)
+
+			assert.NoError(t, checker.Check(context.Background()))
+			assert.Equal(t, tt.name, checker.Name())
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}